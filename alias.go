@@ -0,0 +1,114 @@
+package failure
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	aliasesMu  sync.RWMutex
+	aliases    = make(map[Code]Code)
+	hasAliases int32
+)
+
+// AliasCode makes the deprecated code old an alias of new, to rename
+// codes without breaking code comparing them during migrations.
+// CodeOf returns new for errors with old, and Is and errors.Is treat
+// old and new as the same code. Codes given to the registries like
+// RegisterCode, RegisterHTTPStatus and RegisterLocalizedMessage, and to
+// CaptureOnlyCodes or CaptureExceptCodes are resolved as well,
+// regardless of whether they are registered before AliasCode.
+// Errors created with old are reported to the hook set by
+// SetDeprecatedCodeHook.
+//
+//	failure.AliasCode(UserNotFound, NotFound)
+func AliasCode(old, new Code) {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	aliases[old] = new
+	atomic.StoreInt32(&hasAliases, 1)
+}
+
+// ResolveCode returns the code c is aliased to by AliasCode, or c
+// itself if it is not an alias.
+// Registries keyed by codes outside this package should resolve codes
+// by it on both registration and lookup.
+func ResolveCode(c Code) Code {
+	return resolveCode(c)
+}
+
+func resolveCode(c Code) Code {
+	if c == nil || atomic.LoadInt32(&hasAliases) == 0 {
+		return c
+	}
+
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	// Aliases may be chained, and the limit prevents cycles.
+	for n := 0; n <= len(aliases); n++ {
+		next, ok := aliases[c]
+		if !ok {
+			break
+		}
+		c = next
+	}
+	return c
+}
+
+// lookupAliased returns the value registered in m for code.
+// Codes are resolved by resolveCode on registration, and codes
+// registered before they became aliases are found by resolving them
+// again.
+// The caller must hold the lock of m.
+func lookupAliased[V any](m map[Code]V, code Code) (V, bool) {
+	code = resolveCode(code)
+	if v, ok := m[code]; ok {
+		return v, true
+	}
+	if atomic.LoadInt32(&hasAliases) != 0 {
+		for k, v := range m {
+			if resolveCode(k) == code {
+				return v, true
+			}
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// DeprecatedCodeHook is called with an error created by New or
+// Translate with a code aliased by AliasCode.
+type DeprecatedCodeHook func(err error, old, new Code)
+
+type deprecatedCodeHookHolder struct {
+	hook DeprecatedCodeHook
+}
+
+var deprecatedCodeHook atomic.Value // deprecatedCodeHookHolder
+
+// SetDeprecatedCodeHook sets the hook called when errors are created
+// with deprecated codes, to find code still using them.
+// Passing nil removes the hook, which is the default.
+// It is safe to call SetDeprecatedCodeHook concurrently.
+//
+//	failure.SetDeprecatedCodeHook(func(err error, old, new failure.Code) {
+//		log.Printf("deprecated code %s is used at %s", old.ErrorCode(), failure.CallStackOf(err).HeadFrame())
+//	})
+func SetDeprecatedCodeHook(h DeprecatedCodeHook) {
+	deprecatedCodeHook.Store(deprecatedCodeHookHolder{h})
+}
+
+func runDeprecatedCodeHook(err error, code Code) {
+	if atomic.LoadInt32(&hasAliases) == 0 {
+		return
+	}
+	h, _ := deprecatedCodeHook.Load().(deprecatedCodeHookHolder)
+	if h.hook == nil {
+		return
+	}
+	if c := resolveCode(code); c != code {
+		h.hook(err, code, c)
+	}
+}
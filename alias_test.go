@@ -0,0 +1,80 @@
+package failure_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasCode(t *testing.T) {
+	defer failure.SetDeprecatedCodeHook(nil)
+
+	oldCode := failure.StringCode("alias_test.user_not_found")
+	olderCode := failure.StringCode("alias_test.no_user")
+	newCode := failure.StringCode("alias_test.not_found")
+	failure.AliasCode(oldCode, newCode)
+	failure.AliasCode(olderCode, oldCode)
+
+	type report struct {
+		old, new failure.Code
+	}
+	var reports []report
+	failure.SetDeprecatedCodeHook(func(err error, old, new failure.Code) {
+		assert.Equal(t, "TestAliasCode", failure.CallStackOf(err).HeadFrame().Func())
+		reports = append(reports, report{old, new})
+	})
+
+	err := failure.New(olderCode)
+	assert.Equal(t, newCode, failure.CodeOf(err))
+	assert.True(t, failure.Is(err, newCode))
+	assert.True(t, failure.Is(err, oldCode))
+	assert.True(t, failure.Is(failure.New(newCode), olderCode))
+	assert.True(t, errors.Is(err, failure.New(newCode)))
+	assert.True(t, errors.Is(failure.New(newCode), err))
+	assert.Equal(t, []failure.Code{olderCode}, failure.CodesOf(err))
+
+	failure.New(newCode)
+	assert.Equal(t, []report{{olderCode, newCode}}, reports)
+}
+
+func TestAliasCode_Registries(t *testing.T) {
+	oldCode := failure.StringCode("alias_test.registries_old")
+	newCode := failure.StringCode("alias_test.registries_new")
+
+	// Registered before the alias by the old code.
+	failure.RegisterRetryability(oldCode, failure.Retryable)
+	failure.RegisterLocalizedMessage(oldCode, "en", "old")
+	failure.AliasCode(oldCode, newCode)
+	// Registered after the alias by the old code.
+	failure.RegisterHTTPStatus(oldCode, http.StatusConflict)
+	failure.RegisterCode(oldCode, "The old code.", 0, true)
+	// Registered by the new code.
+	failure.RegisterExitCode(newCode, 3)
+	failure.RegisterLocalizedMessage(newCode, "ja", "new")
+
+	for _, c := range []failure.Code{oldCode, newCode} {
+		err := failure.New(c)
+		assert.True(t, failure.IsRetryable(err))
+		assert.Equal(t, http.StatusConflict, failure.HTTPStatusOf(err))
+		assert.Equal(t, 3, failure.ExitCodeOf(err))
+
+		msg, ok := failure.LocalizedMessage(err, "en")
+		assert.True(t, ok)
+		assert.Equal(t, "old", msg)
+		msg, ok = failure.LocalizedMessage(err, "ja")
+		assert.True(t, ok)
+		assert.Equal(t, "new", msg)
+
+		info, ok := failure.LookupCode(c)
+		assert.True(t, ok)
+		assert.Equal(t, "The old code.", info.Description)
+	}
+	assert.Equal(t, newCode, failure.ResolveCode(oldCode))
+
+	assert.True(t, failure.CaptureOnlyCodes(newCode)(oldCode))
+	assert.True(t, failure.CaptureOnlyCodes(oldCode)(newCode))
+	assert.False(t, failure.CaptureExceptCodes(newCode)(oldCode))
+}
@@ -0,0 +1,182 @@
+// Package analyzer provides an analyzer which checks that errors
+// returned from other packages are wrapped by the failure package.
+//
+// Returning errors of other packages as they are loses the call stack
+// and the error code at the boundary, so the analyzer reports such
+// returns like below.
+//
+//	func Load(name string) ([]byte, error) {
+//		b, err := os.ReadFile(name)
+//		if err != nil {
+//			return nil, err // error returned from os.ReadFile is not wrapped by failure
+//		}
+//		return b, nil
+//	}
+//
+// Errors from the same package, from the failure package and its sub
+// packages, and from packages specified by the -ignore flag are not
+// reported.
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const failurePath = "github.com/morikuni/failure"
+
+// Analyzer reports errors returned from other packages without being
+// wrapped by failure.Wrap, failure.Translate or other constructors.
+var Analyzer = &analysis.Analyzer{
+	Name:     "failurecheck",
+	Doc:      "check that errors returned from other packages are wrapped by failure",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var ignore string
+
+func init() {
+	Analyzer.Flags.StringVar(&ignore, "ignore", "errors", "comma separated import paths of packages whose errors are not reported")
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	filter := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+	ins.Preorder(filter, func(n ast.Node) {
+		var body *ast.BlockStmt
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			body = n.Body
+		case *ast.FuncLit:
+			body = n.Body
+		}
+		if body != nil {
+			checkFunc(pass, body)
+		}
+	})
+	return nil, nil
+}
+
+// assignment is an assignment of a call result to a variable.
+type assignment struct {
+	pos  token.Pos
+	call *ast.CallExpr
+}
+
+func checkFunc(pass *analysis.Pass, body *ast.BlockStmt) {
+	assigns := make(map[types.Object][]assignment)
+	var returns []*ast.ReturnStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Checked separately.
+			return false
+		case *ast.AssignStmt:
+			recordAssign(pass, assigns, n.Pos(), n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			recordAssign(pass, assigns, n.Pos(), lhs, n.Values)
+		case *ast.ReturnStmt:
+			returns = append(returns, n)
+		}
+		return true
+	})
+
+	for _, ret := range returns {
+		for _, r := range ret.Results {
+			r = ast.Unparen(r)
+			switch r := r.(type) {
+			case *ast.CallExpr:
+				if returnsError(pass.TypesInfo.TypeOf(r)) {
+					checkCall(pass, r.Pos(), r)
+				}
+			case *ast.Ident:
+				if !types.Identical(pass.TypesInfo.TypeOf(r), errorType) {
+					continue
+				}
+				if call := lastCall(assigns[pass.TypesInfo.ObjectOf(r)], r.Pos()); call != nil {
+					checkCall(pass, r.Pos(), call)
+				}
+			}
+		}
+	}
+}
+
+func recordAssign(pass *analysis.Pass, assigns map[types.Object][]assignment, pos token.Pos, lhs, rhs []ast.Expr) {
+	for i, l := range lhs {
+		id, ok := l.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj := pass.TypesInfo.ObjectOf(id)
+		if obj == nil {
+			continue
+		}
+
+		var call *ast.CallExpr
+		switch {
+		case len(lhs) == len(rhs):
+			call, _ = ast.Unparen(rhs[i]).(*ast.CallExpr)
+		case len(rhs) == 1:
+			call, _ = ast.Unparen(rhs[0]).(*ast.CallExpr)
+		}
+		assigns[obj] = append(assigns[obj], assignment{pos, call})
+	}
+}
+
+// lastCall returns the call assigned last before pos.
+// It returns nil if the last assignment is not a call.
+func lastCall(as []assignment, pos token.Pos) *ast.CallExpr {
+	var call *ast.CallExpr
+	for _, a := range as {
+		if a.pos < pos {
+			call = a.call
+		}
+	}
+	return call
+}
+
+func returnsError(t types.Type) bool {
+	if tup, ok := t.(*types.Tuple); ok {
+		for i := 0; i < tup.Len(); i++ {
+			if types.Identical(tup.At(i).Type(), errorType) {
+				return true
+			}
+		}
+		return false
+	}
+	return t != nil && types.Identical(t, errorType)
+}
+
+func checkCall(pass *analysis.Pass, pos token.Pos, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg() == pass.Pkg {
+		return
+	}
+
+	path := fn.Pkg().Path()
+	if path == failurePath || strings.HasPrefix(path, failurePath+"/") {
+		return
+	}
+	for _, p := range strings.Split(ignore, ",") {
+		if strings.TrimSpace(p) == path {
+			return
+		}
+	}
+
+	pass.Reportf(pos, "error returned from %s is not wrapped by failure", fn.FullName())
+}
@@ -0,0 +1,14 @@
+// Command failurecheck reports errors returned from other packages
+// without being wrapped by the failure package.
+//
+//	go vet -vettool=$(which failurecheck) ./...
+package main
+
+import (
+	"github.com/morikuni/failure/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
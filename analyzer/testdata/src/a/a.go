@@ -0,0 +1,66 @@
+package a
+
+import (
+	"errors"
+	"ext"
+
+	"github.com/morikuni/failure"
+)
+
+func returnCall() error {
+	return ext.Do() // want `error returned from ext.Do is not wrapped by failure`
+}
+
+func returnTuple() (string, error) {
+	return ext.Get() // want `error returned from ext.Get is not wrapped by failure`
+}
+
+func returnVar() (string, error) {
+	s, err := ext.Get()
+	if err != nil {
+		return "", err // want `error returned from ext.Get is not wrapped by failure`
+	}
+	return s, nil
+}
+
+func returnVarDecl() error {
+	var err = ext.Do()
+	return err // want `error returned from ext.Do is not wrapped by failure`
+}
+
+func returnMethod(r ext.Reader) error {
+	_, err := r.Read()
+	return err // want `error returned from \(ext.Reader\).Read is not wrapped by failure`
+}
+
+func returnFuncLit() {
+	_ = func() error {
+		return ext.Do() // want `error returned from ext.Do is not wrapped by failure`
+	}
+}
+
+func reassigned() error {
+	err := ext.Do()
+	err = failure.Wrap(err)
+	return err
+}
+
+func wrapped() error {
+	if err := ext.Do(); err != nil {
+		return failure.Wrap(err)
+	}
+	return failure.Translate(ext.Do(), nil)
+}
+
+func samePackage() error {
+	return returnCall()
+}
+
+func ignored() error {
+	return errors.New("error")
+}
+
+func notError() (string, error) {
+	s, _ := ext.Get()
+	return s, nil
+}
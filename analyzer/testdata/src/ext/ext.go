@@ -0,0 +1,13 @@
+package ext
+
+type Reader interface {
+	Read() (string, error)
+}
+
+func Do() error {
+	return nil
+}
+
+func Get() (string, error) {
+	return "", nil
+}
@@ -0,0 +1,17 @@
+package failure
+
+type Code interface {
+	ErrorCode() string
+}
+
+type Wrapper interface {
+	WrapError(err error) error
+}
+
+func Wrap(err error, wrappers ...Wrapper) error {
+	return err
+}
+
+func Translate(err error, code Code, wrappers ...Wrapper) error {
+	return err
+}
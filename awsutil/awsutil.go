@@ -0,0 +1,78 @@
+// Package awsutil translates errors of github.com/aws/aws-sdk-go-v2
+// into errors of the failure package.
+package awsutil
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/morikuni/failure"
+)
+
+// CodePrefix is the prefix of codes of API errors.
+// The code of an API error is CodePrefix followed by the AWS error code
+// like "aws.NoSuchKey", so that failure.CodeMatches(err, "aws.*")
+// matches all of them.
+const CodePrefix = "aws."
+
+// Unknown is the code of errors which are not API errors, like
+// network errors.
+const Unknown failure.StringCode = "aws.unknown"
+
+// Keys of the debug information added by Translate.
+const (
+	ErrorCodeKey  = "aws_error_code"
+	RequestIDKey  = "aws_request_id"
+	HTTPStatusKey = "http_status"
+)
+
+// Classify returns the code for err returned by AWS SDK.
+func Classify(err error) failure.Code {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() != "" {
+		return failure.StringCode(CodePrefix + apiErr.ErrorCode())
+	}
+	return Unknown
+}
+
+// Translate translates err returned by AWS SDK into a failure error
+// with the code decided by Classify.
+// The AWS error code, the request ID and the HTTP status are appended
+// as debug information if available.
+// The error is marked retryable or not retryable by the default
+// retryable classification of the SDK.
+// It returns nil if err is nil.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		apiErr    smithy.APIError
+		reqIDErr  interface{ ServiceRequestID() string }
+		statusErr interface{ HTTPStatusCode() int }
+	)
+	debug := failure.Debug{}
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() != "" {
+		debug[ErrorCodeKey] = apiErr.ErrorCode()
+	}
+	if errors.As(err, &reqIDErr) && reqIDErr.ServiceRequestID() != "" {
+		debug[RequestIDKey] = reqIDErr.ServiceRequestID()
+	}
+	if errors.As(err, &statusErr) && statusErr.HTTPStatusCode() != 0 {
+		debug[HTTPStatusKey] = statusErr.HTTPStatusCode()
+	}
+
+	retryability := failure.MarkNotRetryable()
+	if retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+		retryability = failure.MarkRetryable()
+	}
+
+	wrappers := []failure.Wrapper{failure.WithCallStackSkip(1), retryability}
+	if len(debug) != 0 {
+		wrappers = append(wrappers, debug)
+	}
+	return failure.Translate(err, Classify(err), wrappers...)
+}
@@ -0,0 +1,51 @@
+package awsutil_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/awsutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func responseError(status int, err error) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      err,
+		},
+		RequestID: "req-1",
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	err := awsutil.Translate(responseError(http.StatusBadRequest, &smithy.GenericAPIError{
+		Code:    "ThrottlingException",
+		Message: "rate exceeded",
+		Fault:   smithy.FaultClient,
+	}))
+
+	assert.Equal(t, failure.StringCode("aws.ThrottlingException"), failure.CodeOf(err))
+	assert.Equal(t, []failure.Debug{{
+		"aws_error_code": "ThrottlingException",
+		"aws_request_id": "req-1",
+		"http_status":    http.StatusBadRequest,
+	}}, failure.DebugsOf(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.Equal(t, "TestTranslate", failure.CallStackOf(err).HeadFrame().Func())
+
+	err = awsutil.Translate(responseError(http.StatusNotFound, &smithy.GenericAPIError{Code: "NoSuchKey"}))
+	assert.Equal(t, failure.StringCode("aws.NoSuchKey"), failure.CodeOf(err))
+	assert.False(t, failure.IsRetryable(err))
+
+	err = awsutil.Translate(io.EOF)
+	assert.Equal(t, awsutil.Unknown, failure.CodeOf(err))
+	assert.Empty(t, failure.DebugsOf(err))
+
+	assert.Nil(t, awsutil.Translate(nil))
+}
@@ -0,0 +1,17 @@
+module github.com/morikuni/failure/awsutil
+
+go 1.21
+
+replace github.com/morikuni/failure => ../
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.30.3
+	github.com/aws/smithy-go v1.20.3
+	github.com/morikuni/failure v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
@@ -0,0 +1,81 @@
+package failure
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// WrapHere wraps err with the name of the calling function like
+// "service.GetUser", so that Breadcrumb shows the path of err without
+// writing messages.
+//
+//	func (s *Service) GetUser(id string) (*User, error) {
+//		u, err := s.repo.FindUser(id)
+//		if err != nil {
+//			return nil, failure.WrapHere(err)
+//		}
+//		...
+//	}
+func WrapHere(err error) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, []Wrapper{annotateCaller(2)}, 2)
+}
+
+func annotateCaller(skip int) Wrapper {
+	var name string
+	if pc, _, _, ok := runtime.Caller(skip); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			pkgPath, fn := splitFuncName(fn.Name())
+			name = fn
+			if pkgPath != "" {
+				name = path.Base(pkgPath) + "." + fn
+			}
+		}
+	}
+	return WrapperFunc(func(err error) error {
+		return withAnnotation{err, name}
+	})
+}
+
+type withAnnotation struct {
+	error
+	annotation string
+}
+
+func (w withAnnotation) UnwrapError() error {
+	return w.error
+}
+
+func (w withAnnotation) Unwrap() error {
+	return w.error
+}
+
+func (w withAnnotation) GetAnnotation() string {
+	return w.annotation
+}
+
+func (w withAnnotation) detail(p palette) string {
+	return fmt.Sprintf("at(%s)", w.annotation)
+}
+
+// Breadcrumb returns the names of functions recorded by WrapHere from
+// the outermost like "service.GetUser <- repo.FindUser".
+// It returns an empty string if err is not wrapped by WrapHere.
+func Breadcrumb(err error) string {
+	type annotationGetter interface {
+		GetAnnotation() string
+	}
+
+	var names []string
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(annotationGetter); ok {
+			names = append(names, g.GetAnnotation())
+		}
+	}
+	return strings.Join(names, " <- ")
+}
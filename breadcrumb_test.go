@@ -0,0 +1,29 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func findUser() error {
+	return failure.WrapHere(io.EOF)
+}
+
+func getUser() error {
+	return failure.WrapHere(findUser())
+}
+
+func TestWrapHere(t *testing.T) {
+	err := getUser()
+
+	assert.Equal(t, "failure_test.getUser <- failure_test.findUser", failure.Breadcrumb(err))
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "    at(failure_test.getUser)\n")
+	assert.Equal(t, "", failure.Breadcrumb(failure.Wrap(io.EOF)))
+	assert.Nil(t, failure.WrapHere(nil))
+}
@@ -0,0 +1,94 @@
+package failure
+
+// Builder builds an error step by step.
+//
+//	err := failure.Build(NotFound).
+//		Msg("user not found").
+//		Ctx("user_id", id).
+//		Retryable().
+//		Severity(failure.SeverityWarn).
+//		Wrap(err)
+//
+// The result is the same as passing the corresponding wrappers to New,
+// Translate or Wrap, except that values of Ctx are appended as one
+// Debug.
+type Builder struct {
+	code     Code
+	wrappers []Wrapper
+	debug    Debug
+}
+
+// Build starts building an error with code.
+// If code is nil, Wrap works like the Wrap function.
+func Build(code Code) *Builder {
+	return &Builder{code: code}
+}
+
+// Msg appends the message.
+func (b *Builder) Msg(msg string) *Builder {
+	return b.With(Message(msg))
+}
+
+// Ctx appends the key-value pair as debug information.
+func (b *Builder) Ctx(key string, value interface{}) *Builder {
+	if b.debug == nil {
+		b.debug = Debug{}
+	}
+	b.debug[key] = value
+	return b
+}
+
+// Retryable marks the error as retryable.
+func (b *Builder) Retryable() *Builder {
+	return b.With(MarkRetryable())
+}
+
+// NotRetryable marks the error as not retryable.
+func (b *Builder) NotRetryable() *Builder {
+	return b.With(MarkNotRetryable())
+}
+
+// Severity appends the severity.
+func (b *Builder) Severity(s Severity) *Builder {
+	return b.With(WithSeverity(s))
+}
+
+// With appends arbitrary wrappers.
+func (b *Builder) With(wrappers ...Wrapper) *Builder {
+	b.wrappers = append(b.wrappers, wrappers...)
+	return b
+}
+
+// buildWrappers copies the debug information, so that errors built by
+// the same Builder do not share it.
+func (b *Builder) buildWrappers() []Wrapper {
+	if b.debug == nil {
+		return b.wrappers
+	}
+	debug := make(Debug, len(b.debug))
+	for k, v := range b.debug {
+		debug[k] = v
+	}
+	return append(b.wrappers[:len(b.wrappers):len(b.wrappers)], debug)
+}
+
+// New creates the error like New.
+// It panics if the code given to Build is nil.
+func (b *Builder) New() error {
+	if b.code == nil {
+		panic("failure: Builder.New requires a code")
+	}
+	return newFailure(nil, b.code, b.buildWrappers(), 2)
+}
+
+// Wrap wraps err like Translate, or like Wrap if the code given to Build
+// is nil. It returns nil if err is nil.
+func (b *Builder) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	if b.code == nil {
+		return wrap(err, b.buildWrappers(), 2)
+	}
+	return newFailure(err, b.code, b.buildWrappers(), 2)
+}
@@ -0,0 +1,65 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	err := failure.Build(TestCodeA).
+		Msg("not found").
+		Ctx("user_id", 1).
+		Ctx("name", "foo").
+		Retryable().
+		Severity(failure.SeverityCritical).
+		Wrap(io.EOF)
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "not found", failure.MessageOf(err))
+	assert.Equal(t, []failure.Debug{{"user_id": 1, "name": "foo"}}, failure.DebugsOf(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.Equal(t, failure.SeverityCritical, failure.SeverityOf(err))
+	assert.Equal(t, io.EOF, failure.CauseOf(err))
+	assert.Equal(t, "TestBuilder", failure.CallStackOf(err).HeadFrame().Func())
+	assert.EqualError(t, err, "TestBuilder: code(code_a): EOF")
+}
+
+func TestBuilder_New(t *testing.T) {
+	err := failure.Build(TestCodeB).NotRetryable().New()
+
+	assert.Equal(t, TestCodeB, failure.CodeOf(err))
+	assert.False(t, failure.IsRetryable(err))
+	assert.Nil(t, failure.DebugsOf(err))
+	assert.Equal(t, "TestBuilder_New", failure.CallStackOf(err).HeadFrame().Func())
+
+	assert.Panics(t, func() {
+		failure.Build(nil).New()
+	})
+}
+
+func TestBuilder_Wrap(t *testing.T) {
+	base := failure.New(TestCodeA)
+	err := failure.Build(nil).Msg("wrapped").With(failure.Debug{"a": 1}).Wrap(base)
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "wrapped", failure.MessageOf(err))
+	assert.Equal(t, []failure.Debug{{"a": 1}}, failure.DebugsOf(err))
+	if css := failure.CallStacksOf(err); assert.Len(t, css, 2) {
+		assert.Equal(t, "TestBuilder_Wrap", css[0].HeadFrame().Func())
+	}
+
+	assert.Nil(t, failure.Build(TestCodeA).Wrap(nil))
+	assert.Nil(t, failure.Build(nil).Wrap(nil))
+}
+
+func TestBuilder_Reuse(t *testing.T) {
+	b := failure.Build(TestCodeA).Ctx("a", 1)
+	err1 := b.New()
+	err2 := b.Ctx("a", 2).Ctx("b", 3).New()
+
+	assert.Equal(t, []failure.Debug{{"a": 1}}, failure.DebugsOf(err1))
+	assert.Equal(t, []failure.Debug{{"a": 2, "b": 3}}, failure.DebugsOf(err2))
+}
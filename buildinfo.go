@@ -0,0 +1,63 @@
+package failure
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Keys of the debug information added by WithBuildInfo.
+const (
+	ModuleKey        = "module"
+	ModuleVersionKey = "module_version"
+	VCSRevisionKey   = "vcs_revision"
+	VCSModifiedKey   = "vcs_modified"
+	GoVersionKey     = "go_version"
+)
+
+var (
+	buildInfoOnce sync.Once
+	buildInfo     Debug
+)
+
+// WithBuildInfo appends the build information of the binary to an
+// error as debug information, so that errors in logs can be
+// correlated to the exact binary.
+// The path and the version of the main module, the VCS revision and
+// the Go version are added, and unavailable ones are omitted.
+// The build information is read only once per process, and a copy of
+// it is returned for each call.
+func WithBuildInfo() Wrapper {
+	buildInfoOnce.Do(func() {
+		buildInfo = readBuildInfo()
+	})
+	d := make(Debug, len(buildInfo))
+	for k, v := range buildInfo {
+		d[k] = v
+	}
+	return d
+}
+
+func readBuildInfo() Debug {
+	d := Debug{GoVersionKey: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return d
+	}
+
+	if bi.Main.Path != "" {
+		d[ModuleKey] = bi.Main.Path
+	}
+	if bi.Main.Version != "" {
+		d[ModuleVersionKey] = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			d[VCSRevisionKey] = s.Value
+		case "vcs.modified":
+			d[VCSModifiedKey] = s.Value == "true"
+		}
+	}
+	return d
+}
@@ -0,0 +1,35 @@
+package failure_test
+
+import (
+	"io"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBuildInfo(t *testing.T) {
+	err := failure.Wrap(io.EOF, failure.WithBuildInfo())
+
+	v, ok := failure.ValueOf(err, failure.GoVersionKey)
+	assert.True(t, ok)
+	assert.Equal(t, runtime.Version(), v)
+
+	bi, ok := debug.ReadBuildInfo()
+	if assert.True(t, ok) {
+		v, _ := failure.ValueOf(err, failure.ModuleKey)
+		assert.Equal(t, bi.Main.Path, v)
+	}
+
+	assert.Equal(t, failure.DebugsOf(err), failure.DebugsOf(failure.Wrap(io.EOF, failure.WithBuildInfo())))
+}
+
+func TestWithBuildInfo_Copy(t *testing.T) {
+	d := failure.WithBuildInfo().(failure.Debug)
+	d[failure.GoVersionKey] = "modified"
+
+	v, _ := failure.ValueOf(failure.Wrap(io.EOF, failure.WithBuildInfo()), failure.GoVersionKey)
+	assert.Equal(t, runtime.Version(), v)
+}
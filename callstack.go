@@ -1,13 +1,18 @@
 package failure
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
-
-	"github.com/pkg/errors"
+	"sync"
+	"sync/atomic"
 )
 
 // CallStack represents a call stack.
@@ -21,44 +26,211 @@ type CallStack interface {
 	Frames() []Frame
 }
 
+// CallStackKey is a hash of a call stack returned by CallStackKeyOf.
+type CallStackKey uint64
+
+// callStack holds raw program counters and resolves them into
+// frames only when they are requested.
+// Resolved frames are cached so that the symbolization runs at most once.
 type callStack struct {
 	pcs []uintptr
+
+	headOnce sync.Once
+	head     Frame
+
+	framesOnce sync.Once
+	frames     []Frame
+}
+
+func newCallStack(pcs []uintptr) *callStack {
+	return &callStack{pcs: pcs}
 }
 
-func (cs callStack) HeadFrame() Frame {
-	if len(cs.pcs) == 0 {
-		return emptyFrame
+// newCallStackFromFrames creates a call stack from already resolved frames.
+func newCallStackFromFrames(fs []Frame) *callStack {
+	cs := &callStack{}
+	cs.framesOnce.Do(func() {
+		cs.frames = fs
+	})
+	return cs
+}
+
+func (cs *callStack) HeadFrame() Frame {
+	cs.headOnce.Do(func() {
+		if len(cs.pcs) != 0 {
+			// Resolve only the first frame unless it is elided.
+			if f := resolveFrames(cs.pcs[:1])[0]; !isInternalFrame(f) {
+				cs.head = f
+				return
+			}
+		}
+		if fs := cs.Frames(); len(fs) != 0 {
+			cs.head = fs[0]
+		} else {
+			cs.head = emptyFrame
+		}
+	})
+	return cs.head
+}
+
+func (cs *callStack) Frames() []Frame {
+	cs.framesOnce.Do(func() {
+		if len(cs.pcs) == 0 {
+			return
+		}
+
+		cs.frames = elideInternalFrames(resolveFrames(cs.pcs))
+	})
+	return cs.frames
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The call stack is encoded as an array of frames.
+func (cs *callStack) MarshalJSON() ([]byte, error) {
+	fs := cs.Frames()
+	jfs := make([]jsonFrame, len(fs))
+	for i, f := range fs {
+		jfs[i] = newJSONFrame(f)
 	}
+	return json.Marshal(jfs)
+}
 
-	rfs := runtime.CallersFrames(cs.pcs[:1])
-	f, _ := rfs.Next()
-	return frame{f.File, f.Line, f.Function}
+// LogValue implements the slog.LogValuer interface.
+// The call stack is logged as a group of frames keyed by their index.
+func (cs *callStack) LogValue() slog.Value {
+	fs := cs.Frames()
+	attrs := make([]slog.Attr, len(fs))
+	for i, f := range fs {
+		attrs[i] = slog.Any(strconv.Itoa(i), f)
+	}
+	return slog.GroupValue(attrs...)
 }
 
-func (cs callStack) Frames() []Frame {
-	if len(cs.pcs) == 0 {
-		return nil
+// FilterCallStack returns a new call stack which only has frames of cs
+// satisfying f.
+//
+//	cs = failure.FilterCallStack(cs, failure.ExcludePackages("runtime", "net/http"))
+func FilterCallStack(cs CallStack, f func(Frame) bool) CallStack {
+	var fs []Frame
+	for _, fr := range cs.Frames() {
+		if f(fr) {
+			fs = append(fs, fr)
+		}
 	}
+	return newCallStackFromFrames(fs)
+}
 
-	rfs := runtime.CallersFrames(cs.pcs)
+// ShortCallStack returns the top n frames of cs in one line like
+// "pkg.Fn (file.go:12) <- pkg.Caller (main.go:40)".
+// All frames are returned if n is less than 1.
+func ShortCallStack(cs CallStack, n int) string {
+	fs := cs.Frames()
+	if n > 0 && n < len(fs) {
+		fs = fs[:n]
+	}
 
-	fs := make([]Frame, 0, len(cs.pcs))
-	for {
-		f, more := rfs.Next()
+	var sb strings.Builder
+	for i, f := range fs {
+		if i > 0 {
+			sb.WriteString(" <- ")
+		}
+		fmt.Fprintf(&sb, "%s.%s (%s:%d)", f.Pkg(), f.Func(), f.File(), f.Line())
+	}
+	return sb.String()
+}
 
-		fs = append(fs, frame{f.File, f.Line, f.Function})
+// FNV-1a parameters for CallStackKeyOf.
+const (
+	keyOffset = 14695981039346656037
+	keyPrime  = 1099511628211
+)
 
-		if !more {
-			break
+// CallStackKeyOf returns a hash of the frames of cs, which can be used
+// as a map key to group errors by the place they occurred.
+// Call stacks captured at the same place have the same key.
+// The key is stable only within a process.
+func CallStackKeyOf(cs CallStack) CallStackKey {
+	h := uint64(keyOffset)
+	if c, ok := cs.(*callStack); ok && len(c.pcs) != 0 {
+		for _, pc := range c.pcs {
+			h = hashUint64(h, uint64(pc))
 		}
+		return CallStackKey(h)
 	}
-	return fs
+
+	for _, f := range cs.Frames() {
+		if pc := PCOf(f); pc != 0 {
+			h = hashUint64(h, uint64(pc))
+			continue
+		}
+		// Frames not resolved from program counters like the ones
+		// restored by UnmarshalError.
+		h = hashString(h, f.Path())
+		h = hashString(h, FuncFullOf(f))
+		h = hashUint64(h, uint64(f.Line()))
+	}
+	return CallStackKey(h)
+}
+
+func hashUint64(h, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= keyPrime
+		v >>= 8
+	}
+	return h
+}
+
+func hashString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= keyPrime
+	}
+	// separates adjacent strings.
+	h ^= 0xff
+	h *= keyPrime
+	return h
 }
 
-func (cs callStack) Format(s fmt.State, verb rune) {
+// ExcludePackages returns a predicate for FilterCallStack which
+// drops frames of the given packages.
+// The packages are specified by import path like "net/http".
+func ExcludePackages(pkgs ...string) func(Frame) bool {
+	return func(f Frame) bool {
+		p := PkgPathOf(f)
+		for _, pkg := range pkgs {
+			if p == pkg {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// OnlyPackagePrefix returns a predicate for FilterCallStack which
+// keeps only frames whose package import path starts with prefix.
+func OnlyPackagePrefix(prefix string) func(Frame) bool {
+	return func(f Frame) bool {
+		return strings.HasPrefix(PkgPathOf(f), prefix)
+	}
+}
+
+// Format implements the fmt.Formatter interface.
+//
+//	%v:   Print function names in one line. The first frame of the
+//	      main module is enclosed in brackets unless it is the head.
+//	%+v:  Print a frame per line.
+//	%#v:  Print frames as a slice.
+//	%#+v: Print a frame per line with source code around it.
+func (cs *callStack) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		switch {
+		case s.Flag('+') && s.Flag('#'):
+			for _, f := range cs.Frames() {
+				fmt.Fprintf(s, "%+v\n", f)
+				writeSource(s, f, sourceContextLines)
+			}
 		case s.Flag('+'):
 			for _, f := range cs.Frames() {
 				fmt.Fprintf(s, "%+v\n", f)
@@ -67,92 +239,477 @@ func (cs callStack) Format(s fmt.State, verb rune) {
 			fmt.Fprintf(s, "%#v", cs.Frames())
 		default:
 			fs := cs.Frames()
-			l := len(fs)
-			if l == 0 {
-				return
+			app := firstAppFrame(fs)
+			for i, f := range fs {
+				if i > 0 {
+					io.WriteString(s, ": ")
+				}
+				if i == app && i > 0 {
+					fmt.Fprintf(s, "[%s]", f.Func())
+				} else {
+					io.WriteString(s, f.Func())
+				}
 			}
-			for _, f := range fs[:l-1] {
-				fmt.Fprintf(s, "%s: ", f.Func())
-			}
-			fmt.Fprintf(s, "%v", fs[l-1].Func())
 		}
 	case 's':
 		fmt.Fprintf(s, "%v", cs)
 	}
 }
 
+// firstAppFrame returns the index of the first frame of OriginApp,
+// or -1 if there is none.
+func firstAppFrame(fs []Frame) int {
+	for i, f := range fs {
+		if OriginOf(f) == OriginApp {
+			return i
+		}
+	}
+	return -1
+}
+
+// DefaultMaxStackDepth is the default number of frames captured by Callers.
+const DefaultMaxStackDepth = 32
+
+var maxStackDepth int32 = DefaultMaxStackDepth
+
+// SetMaxStackDepth sets the maximum number of frames captured by Callers.
+// If depth is less than 1, DefaultMaxStackDepth is used.
+// It is safe to call SetMaxStackDepth concurrently.
+func SetMaxStackDepth(depth int) {
+	if depth < 1 {
+		depth = DefaultMaxStackDepth
+	}
+	atomic.StoreInt32(&maxStackDepth, int32(depth))
+}
+
+// MaxStackDepth returns the maximum number of frames captured by Callers.
+func MaxStackDepth() int {
+	return int(atomic.LoadInt32(&maxStackDepth))
+}
+
 // Callers returns a call stack for the current state.
+// At most MaxStackDepth frames are captured.
 func Callers(skip int) CallStack {
-	var pcs [32]uintptr
-	n := runtime.Callers(skip+2, pcs[:])
+	return callers(skip+1, MaxStackDepth())
+}
+
+// CallersN returns a call stack for the current state
+// capturing at most depth frames.
+func CallersN(skip, depth int) CallStack {
+	return callers(skip+1, depth)
+}
+
+func callers(skip, depth int) CallStack {
+	if depth < 1 {
+		return nil
+	}
+
+	bp := pcsPool.Get().(*[]uintptr)
+	defer pcsPool.Put(bp)
+	if cap(*bp) < depth {
+		*bp = make([]uintptr, depth)
+	}
+	buf := (*bp)[:depth]
+
+	n := runtime.Callers(skip+2, buf)
 	if n == 0 {
 		return nil
 	}
 
-	return callStack{pcs[:n]}
+	// Copy into a slice with exact length so that the buffer can be
+	// reused.
+	pcs := make([]uintptr, n)
+	copy(pcs, buf)
+	return newCallStack(pcs)
+}
+
+// pcsPool holds buffers for runtime.Callers.
+var pcsPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]uintptr, DefaultMaxStackDepth)
+		return &b
+	},
+}
+
+// NewCallStack creates a call stack from program counters returned by
+// runtime.Callers.
+func NewCallStack(pcs []uintptr) CallStack {
+	return newCallStack(pcs)
+}
+
+// NewCallStackFromFrames creates a call stack from frames.
+// Unlike call stacks captured by Callers, it is reproducible, so it is
+// useful to test formatters and exporters.
+func NewCallStackFromFrames(frames ...Frame) CallStack {
+	return newCallStackFromFrames(frames)
 }
 
-func callStackFromPkgErrors(st errors.StackTrace) CallStack {
-	pcs := make([]uintptr, len(st))
-	for i, v := range st {
-		pcs[i] = uintptr(v)
+// NewFrame creates a frame of the function at file:line.
+// The function is a fully qualified function name like
+// "github.com/foo/bar.(*T).Method".
+func NewFrame(function, file string, line int) Frame {
+	return frame{runtime.Frame{Function: function, File: file, Line: line}}
+}
+
+// ParseFrame parses a frame in the form of "function file:line" like
+// "github.com/foo/bar.f /src/bar/a.go:12".
+func ParseFrame(s string) (Frame, error) {
+	fs := strings.Fields(s)
+	if len(fs) != 2 {
+		return nil, fmt.Errorf("failure: invalid frame %q", s)
+	}
+	i := strings.LastIndexByte(fs[1], ':')
+	if i < 0 {
+		return nil, fmt.Errorf("failure: invalid frame %q", s)
 	}
+	line, err := strconv.Atoi(fs[1][i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failure: invalid line of frame %q", s)
+	}
+	return NewFrame(fs[0], fs[1][:i], line), nil
+}
 
-	return callStack{[]uintptr(pcs)}
+// MustParseFrames parses frames by ParseFrame.
+// It panics if any of them is invalid, so it is meant to be used in
+// tests.
+//
+//	failure.New(NotFound, failure.WithStaticCallStack(failure.MustParseFrames(
+//		"main.f /src/main.go:12",
+//		"main.main /src/main.go:5",
+//	)...))
+func MustParseFrames(ss ...string) []Frame {
+	fs := make([]Frame, len(ss))
+	for i, s := range ss {
+		f, err := ParseFrame(s)
+		if err != nil {
+			panic(err)
+		}
+		fs[i] = f
+	}
+	return fs
+}
+
+// ParseStack parses a call stack written in text, so that tools
+// processing logs can fingerprint or filter call stacks of errors.
+// It accepts the following forms, and returns nil if text has no frame.
+//
+//   - The output of panics and runtime/debug.Stack. Only the first
+//     goroutine is parsed.
+//   - The output of CallStack formatted by %+v.
+//   - The output of errors formatted by %+v. Only the first [CallStack]
+//     is parsed.
+//
+// Frames parsed from the output of this package have function names
+// without package paths because they are not written.
+func ParseStack(text string) CallStack {
+	text = ansiEscape.ReplaceAllString(text, "")
+	var fs []Frame
+	if i := strings.Index(text, "goroutine "); i >= 0 && strings.Contains(text, " +0x") {
+		fs = parseDebugStack([]byte(firstGoroutine(text[i:])))
+	} else {
+		fs = parseFormattedStack(text)
+	}
+	if len(fs) == 0 {
+		return nil
+	}
+	return newCallStackFromFrames(fs)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// firstGoroutine returns the first goroutine of a goroutine dump
+// starting with "goroutine ".
+func firstGoroutine(s string) string {
+	if i := strings.Index(s, "\n\ngoroutine "); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// parseFormattedStack parses frames written by this package in any
+// FrameStyle.
+func parseFormattedStack(text string) []Frame {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "[CallStack]" {
+			lines = lines[i+1:]
+			break
+		}
+	}
+
+	var fs []Frame
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		f, ok := parseFormattedFrame(line)
+		if !ok {
+			if len(fs) != 0 && !strings.HasPrefix(line, "... ") {
+				break
+			}
+			continue
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+func parseFormattedFrame(line string) (Frame, bool) {
+	var function, loc string
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "] ")
+		if end < 0 {
+			return nil, false
+		}
+		function, loc = line[1:end], line[end+2:]
+	} else {
+		// FrameStyleIDE
+		end := strings.Index(line, ":1: ")
+		if end < 0 {
+			return nil, false
+		}
+		function, loc = line[end+4:], line[:end]
+	}
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return nil, false
+	}
+	n, err := strconv.Atoi(loc[i+1:])
+	if err != nil {
+		return nil, false
+	}
+	return NewFrame(function, loc[:i], n), true
+}
+
+// parseDebugStack parses the output of runtime/debug.Stack into frames.
+//
+//	goroutine 1 [running]:
+//	main.f(0x1)
+//	        /path/to/main.go:10 +0x1d
+//	created by main.main in goroutine 1
+//	        /path/to/main.go:20 +0x2f
+func parseDebugStack(b []byte) []Frame {
+	var (
+		fs       []Frame
+		function string
+	)
+	for _, line := range strings.Split(string(b), "\n") {
+		switch {
+		case line == "" || strings.HasPrefix(line, "goroutine "):
+			continue
+		case line[0] == '\t' || line[0] == ' ':
+			if function == "" {
+				continue
+			}
+			line = strings.TrimSpace(line)
+			if i := strings.LastIndex(line, " +0x"); i >= 0 {
+				line = line[:i]
+			}
+			i := strings.LastIndex(line, ":")
+			if i < 0 {
+				continue
+			}
+			n, err := strconv.Atoi(line[i+1:])
+			if err != nil {
+				continue
+			}
+			fs = append(fs, frame{runtime.Frame{
+				File:     line[:i],
+				Line:     n,
+				Function: function,
+			}})
+			function = ""
+		default:
+			function = parseDebugStackFunc(line)
+		}
+	}
+	return fs
+}
+
+func parseDebugStackFunc(line string) string {
+	if strings.HasPrefix(line, "created by ") {
+		line = strings.TrimPrefix(line, "created by ")
+		if i := strings.Index(line, " in goroutine "); i >= 0 {
+			line = line[:i]
+		}
+		return line
+	}
+	if strings.HasSuffix(line, ")") {
+		if i := strings.LastIndex(line, "("); i > 0 {
+			line = line[:i]
+		}
+	}
+	return line
 }
 
 // Frame represents a stack frame.
 type Frame interface {
 	// Path returns a full path to the file.
+	// The path is rewritten by the PathTrimmer set by SetPathTrimmer.
 	Path() string
 	// File returns a file name.
 	File() string
 	// Line returns a line number in the file.
 	Line() int
-	// Func returns a function name.
+	// Func returns a function name without the package like
+	// "(*Type).Method.func1".
 	Func() string
 	// Pkg returns a package name of the function.
 	Pkg() string
 }
 
-var emptyFrame = frame{"???", 0, "???"}
+var emptyFrame = frame{runtime.Frame{File: "???", Function: "???"}}
 
 type frame struct {
-	file     string
-	line     int
-	function string
+	raw runtime.Frame
 }
 
 func (f frame) Path() string {
-	return f.file
+	return trimPath(f.raw.File)
 }
 
 func (f frame) File() string {
-	return filepath.Base(f.file)
+	return filepath.Base(f.raw.File)
 }
 
 func (f frame) Line() int {
-	return f.line
+	return f.raw.Line
 }
 
 func (f frame) Func() string {
-	fs := strings.Split(path.Base(f.function), ".")
-	if len(fs) >= 1 {
-		return strings.Join(fs[1:], ".")
-	}
-	return fs[0]
+	_, name := splitFuncName(f.raw.Function)
+	return name
 }
 
 func (f frame) Pkg() string {
-	fs := strings.Split(path.Base(f.function), ".")
-	return fs[0]
+	pkgPath, _ := splitFuncName(f.raw.Function)
+	if pkgPath == "" {
+		return ""
+	}
+	return path.Base(pkgPath)
+}
+
+func (f frame) PkgPath() string {
+	pkgPath, _ := splitFuncName(f.raw.Function)
+	return pkgPath
+}
+
+// PkgPathOf returns a full import path of the package of the function
+// of f.
+// Frames implementing PkgPath() string, like the ones captured by this
+// package, return it, and the other frames return Pkg instead.
+func PkgPathOf(f Frame) string {
+	type pkgPather interface {
+		PkgPath() string
+	}
+
+	if p, ok := f.(pkgPather); ok {
+		return p.PkgPath()
+	}
+	return f.Pkg()
+}
+
+// splitFuncName splits a symbol name of a function into the import
+// path of the package and the function name.
+//
+//	github.com/foo/bar.(*Type).Method.func1 -> github.com/foo/bar, (*Type).Method.func1
+//	github.com/foo/bar.Fn[...]              -> github.com/foo/bar, Fn[...]
+//	gopkg.in/yaml%2ev3.Marshal              -> gopkg.in/yaml.v3, Marshal
+//
+// Type arguments may contain slashes and dots, so they are ignored to
+// find the package. Dots in the last element of the import path are
+// escaped as "%2e" by the linker.
+func splitFuncName(fn string) (pkgPath, name string) {
+	end := strings.IndexByte(fn, '[')
+	if end < 0 {
+		end = len(fn)
+	}
+	slash := strings.LastIndexByte(fn[:end], '/')
+	dot := strings.IndexByte(fn[slash+1:end], '.')
+	if dot < 0 {
+		return "", fn
+	}
+	dot += slash + 1
+	return strings.ReplaceAll(fn[:dot], "%2e", "."), fn[dot+1:]
+}
+
+func (f frame) PC() uintptr {
+	return f.raw.PC
+}
+
+func (f frame) RuntimeFrame() runtime.Frame {
+	return f.raw
+}
+
+// FuncFullOf returns the full symbol name of the function of f
+// including the import path of the package like
+// "github.com/foo/bar.(*Type).Method".
+func FuncFullOf(f Frame) string {
+	return RuntimeFrameOf(f).Function
+}
+
+// PCOf returns the program counter of f.
+// It returns 0 if f was not captured from the runtime.
+func PCOf(f Frame) uintptr {
+	return RuntimeFrameOf(f).PC
+}
+
+// RuntimeFrameOf returns the runtime.Frame of f, so that frames can be
+// passed to the runtime and other tools.
+// Frames implementing RuntimeFrame() runtime.Frame, like the ones
+// captured by this package, return it, and the other frames are
+// converted from their paths, lines and functions.
+func RuntimeFrameOf(f Frame) runtime.Frame {
+	type runtimeFramer interface {
+		RuntimeFrame() runtime.Frame
+	}
+
+	if r, ok := f.(runtimeFramer); ok {
+		return r.RuntimeFrame()
+	}
+	fn := f.Func()
+	if p := PkgPathOf(f); p != "" {
+		fn = p + "." + fn
+	}
+	return runtime.Frame{Function: fn, File: f.Path(), Line: f.Line()}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f frame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONFrame(f))
+}
+
+// LogValue implements the slog.LogValuer interface.
+func (f frame) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("func", f.Func()),
+		slog.String("file", f.Path()),
+		slog.Int("line", f.Line()),
+	)
+}
+
+type jsonFrame struct {
+	Path string `json:"path"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Func string `json:"func"`
+	Pkg  string `json:"pkg"`
+}
+
+func newJSONFrame(f Frame) jsonFrame {
+	return jsonFrame{
+		Path: f.Path(),
+		File: f.File(),
+		Line: f.Line(),
+		Func: f.Func(),
+		Pkg:  f.Pkg(),
+	}
 }
 
 func (f frame) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			fmt.Fprintf(s, "[%s] ", f.Func())
+			formatFrame(s, f)
+			return
 		}
 		fallthrough
 	case 's':
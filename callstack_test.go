@@ -1,11 +1,12 @@
 package failure_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"runtime"
 	"testing"
 
 	"github.com/morikuni/failure"
-	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,35 +20,13 @@ func TestCallers(t *testing.T) {
 	assert.Contains(t, fs[0].Path(), "github.com/morikuni/failure/callstack_test.go")
 	assert.Contains(t, fs[0].File(), "callstack_test.go")
 	assert.Equal(t, fs[0].Func(), "X")
-	assert.Equal(t, fs[0].Line(), 13)
+	assert.Equal(t, fs[0].Line(), 14)
 	assert.Equal(t, fs[0].Pkg(), "failure_test")
 
 	assert.Contains(t, fs[1].Path(), "github.com/morikuni/failure/callstack_test.go")
 	assert.Contains(t, fs[1].File(), "callstack_test.go")
 	assert.Equal(t, fs[1].Func(), "TestCallers")
-	assert.Equal(t, fs[1].Line(), 17)
-	assert.Equal(t, fs[1].Pkg(), "failure_test")
-}
-
-func Y() error {
-	return errors.New("aaa")
-}
-
-func TestCallStackFromPkgErrors(t *testing.T) {
-	err := Y()
-
-	fs := failure.CallStackOf(err).Frames()
-
-	assert.Contains(t, fs[0].Path(), "github.com/morikuni/failure/callstack_test.go")
-	assert.Contains(t, fs[0].File(), "callstack_test.go")
-	assert.Equal(t, fs[0].Func(), "Y")
-	assert.Equal(t, fs[0].Line(), 33)
-	assert.Equal(t, fs[0].Pkg(), "failure_test")
-
-	assert.Contains(t, fs[1].Path(), "github.com/morikuni/failure/callstack_test.go")
-	assert.Contains(t, fs[1].File(), "callstack_test.go")
-	assert.Equal(t, fs[1].Func(), "TestCallStackFromPkgErrors")
-	assert.Equal(t, fs[1].Line(), 37)
+	assert.Equal(t, fs[1].Line(), 18)
 	assert.Equal(t, fs[1].Pkg(), "failure_test")
 }
 
@@ -63,12 +42,12 @@ func TestCallStack_Format(t *testing.T) {
 		fmt.Sprintf("%s", cs),
 	)
 	assert.Regexp(t,
-		`\[\]failure.Frame{/.+/github.com/morikuni/failure/callstack_test.go:13, /.+/github.com/morikuni/failure/callstack_test.go:55, .*}`,
+		`\[\]failure.Frame{/.+/github.com/morikuni/failure/callstack_test.go:14, /.+/github.com/morikuni/failure/callstack_test.go:34, .*}`,
 		fmt.Sprintf("%#v", cs),
 	)
 	assert.Regexp(t,
-		`\[X\] /.+/github.com/morikuni/failure/callstack_test.go:13
-\[TestCallStack_Format\] /.+/github.com/morikuni/failure/callstack_test.go:55
+		`\[X\] /.+/github.com/morikuni/failure/callstack_test.go:14
+\[TestCallStack_Format\] /.+/github.com/morikuni/failure/callstack_test.go:34
 \[.*`,
 		fmt.Sprintf("%+v", cs),
 	)
@@ -78,19 +57,19 @@ func TestFrame_Format(t *testing.T) {
 	f := X().HeadFrame()
 
 	assert.Regexp(t,
-		`/.+/github.com/morikuni/failure/callstack_test.go:13`,
+		`/.+/github.com/morikuni/failure/callstack_test.go:14`,
 		fmt.Sprintf("%v", f),
 	)
 	assert.Regexp(t,
-		`/.+/github.com/morikuni/failure/callstack_test.go:13`,
+		`/.+/github.com/morikuni/failure/callstack_test.go:14`,
 		fmt.Sprintf("%s", f),
 	)
 	assert.Regexp(t,
-		`/.+/github.com/morikuni/failure/callstack_test.go:13`,
+		`/.+/github.com/morikuni/failure/callstack_test.go:14`,
 		fmt.Sprintf("%#v", f),
 	)
 	assert.Regexp(t,
-		`\[X\] /.+/github.com/morikuni/failure/callstack_test.go:13`,
+		`\[X\] /.+/github.com/morikuni/failure/callstack_test.go:14`,
 		fmt.Sprintf("%+v", f),
 	)
 }
@@ -101,10 +80,10 @@ func TestCallStack_Frames(t *testing.T) {
 
 	assert.Equal(t, cs.Frames(), fs)
 
-	assert.Equal(t, 13, fs[0].Line())
+	assert.Equal(t, 14, fs[0].Line())
 	assert.Equal(t, "X", fs[0].Func())
 
-	assert.Equal(t, 99, fs[1].Line())
+	assert.Equal(t, 78, fs[1].Line())
 	assert.Equal(t, "TestCallStack_Frames", fs[1].Func())
 }
 
@@ -118,8 +97,351 @@ func TestFrame(t *testing.T) {
 	f := X().HeadFrame()
 
 	assert.Equal(t, "X", f.Func())
-	assert.Equal(t, 13, f.Line())
+	assert.Equal(t, 14, f.Line())
 	assert.Equal(t, "callstack_test.go", f.File())
 	assert.Contains(t, f.Path(), "github.com/morikuni/failure/callstack_test.go")
 	assert.Equal(t, "failure_test", f.Pkg())
 }
+
+func TestCallStack_Frames_Cache(t *testing.T) {
+	cs := X()
+
+	fs1 := cs.Frames()
+	fs2 := cs.Frames()
+	assert.True(t, &fs1[0] == &fs2[0], "frames should be resolved only once")
+}
+
+func BenchmarkCallers(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		failure.Callers(0)
+	}
+}
+
+func TestCallersN(t *testing.T) {
+	cs := failure.CallersN(0, 1)
+	fs := cs.Frames()
+	assert.Len(t, fs, 1)
+	assert.Equal(t, "TestCallersN", fs[0].Func())
+
+	assert.Nil(t, failure.CallersN(0, 0))
+}
+
+func TestSetMaxStackDepth(t *testing.T) {
+	defer failure.SetMaxStackDepth(failure.DefaultMaxStackDepth)
+
+	failure.SetMaxStackDepth(2)
+	assert.Equal(t, 2, failure.MaxStackDepth())
+	fs := X().Frames()
+	assert.Len(t, fs, 2)
+	assert.Equal(t, "X", fs[0].Func())
+	assert.Equal(t, "TestSetMaxStackDepth", fs[1].Func())
+
+	failure.SetMaxStackDepth(0)
+	assert.Equal(t, failure.DefaultMaxStackDepth, failure.MaxStackDepth())
+}
+
+func TestFilterCallStack(t *testing.T) {
+	cs := X()
+
+	fs := failure.FilterCallStack(cs, failure.ExcludePackages("testing", "runtime")).Frames()
+	if assert.Len(t, fs, 2) {
+		assert.Equal(t, "X", fs[0].Func())
+		assert.Equal(t, "TestFilterCallStack", fs[1].Func())
+	}
+
+	fs = failure.FilterCallStack(cs, failure.OnlyPackagePrefix("github.com/morikuni/")).Frames()
+	if assert.Len(t, fs, 2) {
+		assert.Equal(t, "X", fs[0].Func())
+	}
+
+	filtered := failure.FilterCallStack(cs, func(f failure.Frame) bool {
+		return f.Func() == "TestFilterCallStack"
+	})
+	assert.Equal(t, "TestFilterCallStack", filtered.HeadFrame().Func())
+
+	empty := failure.FilterCallStack(cs, func(failure.Frame) bool { return false })
+	assert.Empty(t, empty.Frames())
+	assert.Equal(t, 0, empty.HeadFrame().Line())
+}
+
+func TestPkgPathOf(t *testing.T) {
+	f := X().HeadFrame()
+
+	assert.Equal(t, "github.com/morikuni/failure_test", failure.PkgPathOf(f))
+}
+
+func TestCallStack_MarshalJSON(t *testing.T) {
+	cs := X()
+
+	b, err := json.Marshal(cs)
+	assert.NoError(t, err)
+
+	var fs []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &fs))
+	if assert.True(t, len(fs) >= 2) {
+		assert.Contains(t, fs[0]["path"], "github.com/morikuni/failure/callstack_test.go")
+		assert.Equal(t, "callstack_test.go", fs[0]["file"])
+		assert.Equal(t, float64(14), fs[0]["line"])
+		assert.Equal(t, "X", fs[0]["func"])
+		assert.Equal(t, "failure_test", fs[0]["pkg"])
+		assert.Equal(t, "TestCallStack_MarshalJSON", fs[1]["func"])
+	}
+}
+
+func TestFrame_MarshalJSON(t *testing.T) {
+	f := X().HeadFrame()
+
+	b, err := json.Marshal(f)
+	assert.NoError(t, err)
+	assert.Regexp(t,
+		`{"path":"/.+/github.com/morikuni/failure/callstack_test.go","file":"callstack_test.go","line":14,"func":"X","pkg":"failure_test"}`,
+		string(b),
+	)
+}
+
+func TestPCOf(t *testing.T) {
+	f := X().HeadFrame()
+
+	assert.NotZero(t, failure.PCOf(f))
+
+	rf := failure.RuntimeFrameOf(f)
+	assert.Equal(t, failure.PCOf(f), rf.PC)
+	assert.Equal(t, f.Path(), rf.File)
+	assert.Equal(t, f.Line(), rf.Line)
+	assert.Equal(t, "github.com/morikuni/failure_test.X", rf.Function)
+
+	fn := runtime.FuncForPC(failure.PCOf(f))
+	if assert.NotNil(t, fn) {
+		assert.Equal(t, "github.com/morikuni/failure_test.X", fn.Name())
+	}
+}
+
+func BenchmarkCallStack_Frames(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		failure.Callers(0).Frames()
+	}
+}
+
+type funcNameTest struct{}
+
+func (*funcNameTest) Method() failure.CallStack {
+	return failure.Callers(0)
+}
+
+func (funcNameTest) Closure() failure.CallStack {
+	return func() failure.CallStack {
+		return failure.Callers(0)
+	}()
+}
+
+func genericFunc[T any](T) failure.CallStack {
+	return failure.Callers(0)
+}
+
+func TestFrame_Func(t *testing.T) {
+	tests := map[string]struct {
+		cs failure.CallStack
+
+		wantFunc string
+	}{
+		"pointer receiver": {(&funcNameTest{}).Method(), "(*funcNameTest).Method"},
+		"closure":          {funcNameTest{}.Closure(), "funcNameTest.Closure.func1"},
+		"generic":          {genericFunc(1), "genericFunc[...]"},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			f := test.cs.HeadFrame()
+			assert.Equal(t, test.wantFunc, f.Func())
+			assert.Equal(t, "failure_test", f.Pkg())
+			assert.Equal(t, "github.com/morikuni/failure_test", failure.PkgPathOf(f))
+			assert.Equal(t, "github.com/morikuni/failure_test."+test.wantFunc, failure.FuncFullOf(f))
+		})
+	}
+}
+
+func TestFrame_Func_Symbols(t *testing.T) {
+	tests := map[string]struct {
+		function string
+
+		wantFunc    string
+		wantPkg     string
+		wantPkgPath string
+	}{
+		"function":        {"main.main", "main", "main", "main"},
+		"method":          {"github.com/foo/bar.(*Type).Method", "(*Type).Method", "bar", "github.com/foo/bar"},
+		"closure":         {"github.com/foo/bar.Fn.func1.2", "Fn.func1.2", "bar", "github.com/foo/bar"},
+		"type arguments":  {"github.com/foo/bar.Fn[github.com/x/y.T]", "Fn[github.com/x/y.T]", "bar", "github.com/foo/bar"},
+		"dotted package":  {"gopkg.in/yaml%2ev3.Marshal", "Marshal", "yaml.v3", "gopkg.in/yaml.v3"},
+		"unknown package": {"???", "???", "", ""},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			stack := "goroutine 1 [running]:\n" + test.function + "()\n\t/src/a.go:1 +0x1\n"
+			f := failure.CallStackOf(stackError{[]byte(stack)}).HeadFrame()
+			assert.Equal(t, test.wantFunc, f.Func())
+			assert.Equal(t, test.wantPkg, f.Pkg())
+			assert.Equal(t, test.wantPkgPath, failure.PkgPathOf(f))
+			assert.Equal(t, test.function, failure.FuncFullOf(f))
+		})
+	}
+}
+
+func TestShortCallStack(t *testing.T) {
+	cs := newTestCallStack("github.com/foo/bar.f /src/a.go:12", "github.com/foo/bar.(*T).g /src/b.go:20", "main.main /src/main.go:40")
+
+	assert.Equal(t, "bar.f (a.go:12) <- bar.(*T).g (b.go:20) <- main.main (main.go:40)", failure.ShortCallStack(cs, 0))
+	assert.Equal(t, "bar.f (a.go:12) <- bar.(*T).g (b.go:20)", failure.ShortCallStack(cs, 2))
+	assert.Equal(t, "bar.f (a.go:12) <- bar.(*T).g (b.go:20) <- main.main (main.go:40)", failure.ShortCallStack(cs, 5))
+	assert.Equal(t, "", failure.ShortCallStack(failure.NewCallStack(nil), 1))
+}
+
+func TestCallStackKeyOf(t *testing.T) {
+	var keys []failure.CallStackKey
+	for i := 0; i < 2; i++ {
+		keys = append(keys, failure.CallStackKeyOf(failure.Callers(0)))
+	}
+	assert.Equal(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[0], failure.CallStackKeyOf(failure.Callers(0)))
+	assert.NotEqual(t, keys[0], failure.CallStackKeyOf(X()))
+
+	a := newTestCallStack("main.f /a.go:1", "main.main /main.go:5")
+	assert.Equal(t, failure.CallStackKeyOf(a), failure.CallStackKeyOf(newTestCallStack("main.f /a.go:1", "main.main /main.go:5")))
+	assert.NotEqual(t, failure.CallStackKeyOf(a), failure.CallStackKeyOf(newTestCallStack("main.f /a.go:2", "main.main /main.go:5")))
+	assert.NotEqual(t, failure.CallStackKeyOf(a), failure.CallStackKeyOf(newTestCallStack("main.f /a.go:1")))
+}
+
+func TestParseFrame(t *testing.T) {
+	f, err := failure.ParseFrame("github.com/foo/bar.(*T).Method /src/bar/a.go:12")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "(*T).Method", f.Func())
+		assert.Equal(t, "bar", f.Pkg())
+		assert.Equal(t, "github.com/foo/bar", failure.PkgPathOf(f))
+		assert.Equal(t, "/src/bar/a.go", f.Path())
+		assert.Equal(t, "a.go", f.File())
+		assert.Equal(t, 12, f.Line())
+	}
+
+	for _, s := range []string{"", "main.f", "main.f /a.go", "main.f /a.go:x", "main.f /a.go:1 extra"} {
+		_, err := failure.ParseFrame(s)
+		assert.Error(t, err, s)
+	}
+
+	assert.Panics(t, func() { failure.MustParseFrames("main.f") })
+}
+
+func TestNewCallStackFromFrames(t *testing.T) {
+	cs := failure.NewCallStackFromFrames(
+		failure.NewFrame("main.f", "/src/main.go", 12),
+		failure.NewFrame("main.main", "/src/main.go", 5),
+	)
+
+	assert.Equal(t, "[f] /src/main.go:12\n[main] /src/main.go:5\n", fmt.Sprintf("%+v", cs))
+	assert.Equal(t, "f: main", fmt.Sprintf("%v", cs))
+}
+
+func TestParseStack(t *testing.T) {
+	summary := func(cs failure.CallStack) []string {
+		var ss []string
+		for _, f := range cs.Frames() {
+			ss = append(ss, fmt.Sprintf("%s %s:%d", failure.FuncFullOf(f), f.Path(), f.Line()))
+		}
+		return ss
+	}
+
+	panicText := `panic: boom
+
+goroutine 1 [running]:
+main.f(0x1)
+	/src/main.go:10 +0x1d
+main.main()
+	/src/main.go:5 +0x2f
+
+goroutine 2 [select]:
+main.g()
+	/src/g.go:3 +0x1d
+`
+	assert.Equal(t, []string{
+		"main.f /src/main.go:10",
+		"main.main /src/main.go:5",
+	}, summary(failure.ParseStack(panicText)))
+
+	cs := newTestCallStack("main.f /src/main.go:10", "main.main /src/main.go:5")
+	assert.Equal(t, []string{
+		"f /src/main.go:10",
+		"main /src/main.go:5",
+	}, summary(failure.ParseStack(fmt.Sprintf("%+v", cs))))
+
+	err := failure.New(failure.StringCode("code"), failure.WithStaticCallStack(cs.Frames()...))
+	err = failure.Wrap(err, failure.Message("wrapped"))
+	assert.Equal(t, []string{
+		"f /src/main.go:10",
+		"main /src/main.go:5",
+	}, summary(failure.ParseStack(fmt.Sprintf("%+v", err))))
+
+	assert.Nil(t, failure.ParseStack(""))
+	assert.Nil(t, failure.ParseStack("no stack"))
+}
+
+// stackTracer has the StackTrace method of the same shape as
+// github.com/pkg/errors.
+type stackTracer struct {
+	pcs []uintptr
+}
+
+type testFrame uintptr
+
+type testStackTrace []testFrame
+
+func (e stackTracer) Error() string {
+	return "stack tracer"
+}
+
+func (e stackTracer) StackTrace() testStackTrace {
+	st := make(testStackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = testFrame(pc)
+	}
+	return st
+}
+
+func TestCallStackOf_StackTracer(t *testing.T) {
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	cs := failure.CallStackOf(failure.Wrap(stackTracer{pcs}))
+	if assert.NotNil(t, cs) {
+		assert.Equal(t, "TestCallStackOf_StackTracer", cs.HeadFrame().Func())
+		assert.Equal(t, failure.NewCallStack(pcs).HeadFrame().Line(), cs.HeadFrame().Line())
+	}
+	assert.Len(t, failure.CallStacksOf(failure.Wrap(stackTracer{pcs})), 2)
+}
+
+// customFrame is a Frame implemented outside this package, which has
+// only the methods of the Frame interface.
+type customFrame struct{}
+
+func (customFrame) Path() string { return "/src/main.go" }
+func (customFrame) File() string { return "main.go" }
+func (customFrame) Line() int    { return 10 }
+func (customFrame) Func() string { return "f" }
+func (customFrame) Pkg() string  { return "main" }
+
+func TestFrame_Custom(t *testing.T) {
+	var f failure.Frame = customFrame{}
+
+	assert.Equal(t, "main", failure.PkgPathOf(f))
+	assert.Equal(t, "main.f", failure.FuncFullOf(f))
+	assert.Equal(t, uintptr(0), failure.PCOf(f))
+	assert.Equal(t, runtime.Frame{Function: "main.f", File: "/src/main.go", Line: 10}, failure.RuntimeFrameOf(f))
+	assert.Equal(t, failure.OriginApp, failure.OriginOf(f))
+	_, err := failure.SourceOf(f, 1)
+	assert.Error(t, err)
+
+	cs := failure.NewCallStackFromFrames(f)
+	assert.Equal(t, "main.f (main.go:10)", failure.ShortCallStack(cs, 0))
+	assert.True(t, failure.EqualCallStacks(cs, newTestCallStack("main.f /src/main.go:10")))
+}
@@ -0,0 +1,84 @@
+package failure
+
+import "sync/atomic"
+
+// CapturePolicy decides whether New, Translate and Wrap capture a call
+// stack for an error with the code.
+// The code is nil if the error has no code.
+type CapturePolicy func(code Code) bool
+
+type capturePolicyHolder struct {
+	policy CapturePolicy
+}
+
+var capturePolicy atomic.Value // capturePolicyHolder
+
+// SetCapturePolicy sets the CapturePolicy used by constructors.
+// Passing nil captures call stacks for all errors, which is the default.
+// It is safe to call SetCapturePolicy concurrently.
+//
+// Errors without call stack are cheaper to create, but CallStackOf
+// returns nil for them and their messages have no function name.
+// Call stacks passed by WithCallStack or WithCallStackSkip are always
+// used regardless of the policy.
+func SetCapturePolicy(p CapturePolicy) {
+	capturePolicy.Store(capturePolicyHolder{p})
+}
+
+// shouldCapture reports whether a call stack should be captured for
+// err or code. CodeOf(err) is used if code is nil.
+func shouldCapture(err error, code Code) bool {
+	h, _ := capturePolicy.Load().(capturePolicyHolder)
+	if h.policy == nil {
+		return true
+	}
+	if code == nil {
+		code = CodeOf(err)
+	}
+	return h.policy(code)
+}
+
+// CaptureSampled returns a CapturePolicy which captures a call stack
+// once every n errors.
+// If n is less than 1, it never captures.
+func CaptureSampled(n int) CapturePolicy {
+	var count uint64
+	return func(Code) bool {
+		if n < 1 {
+			return false
+		}
+		return (atomic.AddUint64(&count, 1)-1)%uint64(n) == 0
+	}
+}
+
+// CaptureOnlyCodes returns a CapturePolicy which captures a call stack
+// only for errors with one of the codes.
+func CaptureOnlyCodes(codes ...Code) CapturePolicy {
+	return func(code Code) bool {
+		return containsCode(codes, code)
+	}
+}
+
+// CaptureExceptCodes returns a CapturePolicy which captures a call
+// stack except for errors with one of the codes, like expected errors
+// in high traffic paths.
+//
+//	failure.SetCapturePolicy(failure.CaptureExceptCodes(NotFound))
+func CaptureExceptCodes(codes ...Code) CapturePolicy {
+	return func(code Code) bool {
+		return !containsCode(codes, code)
+	}
+}
+
+func containsCode(codes []Code, code Code) bool {
+	if code == nil {
+		return false
+	}
+	code = resolveCode(code)
+	for _, c := range codes {
+		if resolveCode(c) == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCapturePolicy(t *testing.T) {
+	defer failure.SetCapturePolicy(nil)
+
+	failure.SetCapturePolicy(failure.CaptureExceptCodes(TestCodeA))
+
+	assert.Nil(t, failure.CallStackOf(failure.New(TestCodeA)))
+	assert.Nil(t, failure.CallStackOf(failure.Wrap(failure.New(TestCodeA))))
+	assert.NotNil(t, failure.CallStackOf(failure.New(TestCodeB)))
+	assert.NotNil(t, failure.CallStackOf(failure.Wrap(io.EOF)))
+	assert.EqualError(t, failure.New(TestCodeA), "code(code_a)")
+
+	err := failure.New(TestCodeA, failure.WithCallStackSkip(0))
+	if assert.NotNil(t, failure.CallStackOf(err)) {
+		assert.Equal(t, "TestSetCapturePolicy", failure.CallStackOf(err).HeadFrame().Func())
+	}
+
+	failure.SetCapturePolicy(nil)
+	assert.NotNil(t, failure.CallStackOf(failure.New(TestCodeA)))
+}
+
+func TestCapturePolicies(t *testing.T) {
+	tests := map[string]struct {
+		policy failure.CapturePolicy
+		code   failure.Code
+
+		want bool
+	}{
+		"only codes match":     {failure.CaptureOnlyCodes(TestCodeA), TestCodeA, true},
+		"only codes unmatch":   {failure.CaptureOnlyCodes(TestCodeA), TestCodeB, false},
+		"only codes nil":       {failure.CaptureOnlyCodes(TestCodeA), nil, false},
+		"except codes match":   {failure.CaptureExceptCodes(TestCodeA), TestCodeA, false},
+		"except codes unmatch": {failure.CaptureExceptCodes(TestCodeA), TestCodeB, true},
+		"except codes nil":     {failure.CaptureExceptCodes(TestCodeA), nil, true},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, test.policy(test.code))
+		})
+	}
+}
+
+func TestCaptureSampled(t *testing.T) {
+	p := failure.CaptureSampled(3)
+	var got []bool
+	for i := 0; i < 6; i++ {
+		got = append(got, p(TestCodeA))
+	}
+	assert.Equal(t, []bool{true, false, false, true, false, false}, got)
+
+	assert.False(t, failure.CaptureSampled(0)(TestCodeA))
+}
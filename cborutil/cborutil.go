@@ -0,0 +1,88 @@
+// Package cborutil encodes errors of the failure package in
+// CBOR (RFC 8949), which is much smaller than JSON for shipping a
+// large number of errors.
+//
+// The encoded value has the same structure as the JSON encoded by
+// failure.MarshalError, so it can be decoded by any CBOR
+// library following schema/error.v1.json.
+package cborutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/morikuni/failure"
+)
+
+// decMode decodes maps into map[string]interface{} to be encoded by
+// encoding/json.
+var decMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+// Marshal encodes err into CBOR.
+func Marshal(err error) ([]byte, error) {
+	b, err := failure.MarshalError(err)
+	if err != nil {
+		return nil, err
+	}
+	return fromJSON(b)
+}
+
+// MarshalCallStack encodes cs into CBOR in the same structure as
+// the JSON encoded by json.Marshal.
+func MarshalCallStack(cs failure.CallStack) ([]byte, error) {
+	b, err := json.Marshal(cs)
+	if err != nil {
+		return nil, err
+	}
+	return fromJSON(b)
+}
+
+// Unmarshal decodes CBOR encoded by Marshal into an error in the
+// same way as failure.UnmarshalError.
+func Unmarshal(b []byte) (error, error) {
+	var v interface{}
+	if err := decMode.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	jb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return failure.UnmarshalError(jb)
+}
+
+func fromJSON(b []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(compactNumbers(v))
+}
+
+// compactNumbers converts json.Number in v into int64 if possible,
+// which is encoded smaller than float64.
+func compactNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = compactNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = compactNumbers(e)
+		}
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package cborutil_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/cborutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func TestMarshal(t *testing.T) {
+	err := failure.Translate(io.EOF, NotFound,
+		failure.Message("cannot load"),
+		failure.Debug{"id": "a1", "n": 3, "ratio": 0.5},
+		failure.MarkRetryable(),
+	)
+
+	b, e := cborutil.Marshal(err)
+	assert.NoError(t, e)
+
+	jb, _ := failure.MarshalError(err)
+	assert.True(t, len(b) < len(jb), "%d < %d", len(b), len(jb))
+
+	got, e := cborutil.Unmarshal(b)
+	assert.NoError(t, e)
+	assert.Equal(t, err.Error(), got.Error())
+	assert.Equal(t, NotFound, failure.CodeOf(got))
+	assert.Equal(t, "cannot load", failure.MessageOf(got))
+	assert.Equal(t, []failure.Debug{{"id": "a1", "n": float64(3), "ratio": 0.5}}, failure.DebugsOf(got))
+	assert.True(t, failure.IsRetryable(got))
+
+	want, _ := failure.MarshalError(err)
+	gb, _ := failure.MarshalError(got)
+	assert.JSONEq(t, string(want), string(gb))
+
+	_, e = cborutil.Marshal(nil)
+	assert.Error(t, e)
+	_, e = cborutil.Unmarshal([]byte{0xff})
+	assert.Error(t, e)
+}
+
+func TestMarshalCallStack(t *testing.T) {
+	cs := failure.NewCallStackFromFrames(failure.NewFrame("main.f", "/src/main.go", 12))
+
+	b, err := cborutil.MarshalCallStack(cs)
+	assert.NoError(t, err)
+
+	var got []map[string]interface{}
+	assert.NoError(t, cbor.Unmarshal(b, &got))
+
+	jb, _ := json.Marshal(cs)
+	var want []map[string]interface{}
+	json.Unmarshal(jb, &want)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "/src/main.go", got[0]["path"])
+		assert.EqualValues(t, 12, got[0]["line"])
+		assert.Equal(t, want[0]["func"], got[0]["func"])
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/morikuni/failure/cborutil
+
+go 1.21
+
+replace github.com/morikuni/failure => ../
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/morikuni/failure v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
@@ -0,0 +1,114 @@
+package failure
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// ChainOption changes how Error() of errors created by this package
+// joins the layers.
+type ChainOption func(*chainConfig)
+
+type chainConfig struct {
+	separator      string
+	innermostFirst bool
+	maxLayers      int
+}
+
+// ChainSeparator sets the separator between layers.
+// The default is ": ".
+func ChainSeparator(sep string) ChainOption {
+	return func(c *chainConfig) {
+		c.separator = sep
+	}
+}
+
+// ChainInnermostFirst makes the layers joined from the innermost.
+// The default is from the outermost.
+func ChainInnermostFirst() ChainOption {
+	return func(c *chainConfig) {
+		c.innermostFirst = true
+	}
+}
+
+// ChainMaxLayers limits the number of layers joined to the outermost
+// n layers. The default is no limit.
+func ChainMaxLayers(n int) ChainOption {
+	return func(c *chainConfig) {
+		c.maxLayers = n
+	}
+}
+
+var chainSetting atomic.Value // *chainConfig
+
+// SetErrorChain changes how Error() of errors created by this package
+// joins the layers like "f: code(not_found): sql: no rows in result set",
+// where the function names of call stacks and the codes are the layers,
+// and an error not created by this package is the innermost layer.
+// Calling it without options restores the default.
+// It is safe to call SetErrorChain concurrently.
+//
+//	failure.SetErrorChain(failure.ChainSeparator(" <- "), failure.ChainMaxLayers(5))
+func SetErrorChain(opts ...ChainOption) {
+	if len(opts) == 0 {
+		chainSetting.Store((*chainConfig)(nil))
+		return
+	}
+	c := &chainConfig{separator: ": "}
+	for _, opt := range opts {
+		opt(c)
+	}
+	chainSetting.Store(c)
+}
+
+func chainConfigOf() *chainConfig {
+	c, _ := chainSetting.Load().(*chainConfig)
+	return c
+}
+
+// chainString joins the layers of err by c.
+func (c *chainConfig) chainString(err error) string {
+	var parts []string
+	i := NewIterator(err)
+	for i.Next() {
+		e := i.Error()
+		if cs := i.CallStack(); cs != nil {
+			if _, ok := e.(withCallStack); ok {
+				parts = append(parts, cs.HeadFrame().Func())
+				continue
+			}
+		}
+		if f, ok := e.(Failure); ok {
+			parts = append(parts, "code("+codeString(f.code)+")")
+			continue
+		}
+		if isPackageLayer(e) {
+			continue
+		}
+		// Errors not created by this package may have the messages of
+		// the underlying errors.
+		parts = append(parts, e.Error())
+		break
+	}
+
+	if c.maxLayers > 0 && len(parts) > c.maxLayers {
+		parts = parts[:c.maxLayers]
+	}
+	if c.innermostFirst {
+		for l, r := 0, len(parts)-1; l < r; l, r = l+1, r-1 {
+			parts[l], parts[r] = parts[r], parts[l]
+		}
+	}
+	return strings.Join(parts, c.separator)
+}
+
+// isPackageLayer reports whether err is a layer of this package which
+// does not change Error().
+func isPackageLayer(err error) bool {
+	switch err.(type) {
+	case Failure, withCallStack:
+		return false
+	}
+	_, ok := err.(detailer)
+	return ok
+}
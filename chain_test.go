@@ -0,0 +1,50 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetErrorChain(t *testing.T) {
+	defer failure.SetErrorChain()
+
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("not found"),
+		failure.WithStaticCallStack(failure.NewFrame("repo.Find", "/repo.go", 1)),
+	)
+	err = failure.Wrap(err,
+		failure.Debug{"id": 1},
+		failure.WithStaticCallStack(failure.NewFrame("service.Get", "/service.go", 1)),
+	)
+	assert.Equal(t, "Get: Find: code(not_found): EOF", err.Error())
+
+	tests := map[string]struct {
+		opts []failure.ChainOption
+		want string
+	}{
+		"separator": {
+			[]failure.ChainOption{failure.ChainSeparator(" | ")},
+			"Get | Find | code(not_found) | EOF",
+		},
+		"innermost first": {
+			[]failure.ChainOption{failure.ChainInnermostFirst(), failure.ChainSeparator(" > ")},
+			"EOF > code(not_found) > Find > Get",
+		},
+		"max layers": {
+			[]failure.ChainOption{failure.ChainMaxLayers(2)},
+			"Get: Find",
+		},
+	}
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			failure.SetErrorChain(test.opts...)
+			assert.Equal(t, test.want, err.Error())
+		})
+	}
+
+	failure.SetErrorChain()
+	assert.Equal(t, "Get: Find: code(not_found): EOF", err.Error())
+}
@@ -0,0 +1,81 @@
+// Package cobrautil renders errors of the failure package returned by
+// commands of github.com/spf13/cobra.
+//
+//	cmd := &cobra.Command{
+//		Use:  "app",
+//		RunE: cobrautil.RunE(run),
+//	}
+//	cobrautil.AddDebugFlag(cmd)
+//	if err := cmd.Execute(); err != nil {
+//		os.Exit(failure.ExitCodeOf(err))
+//	}
+package cobrautil
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/morikuni/failure"
+	"github.com/spf13/cobra"
+)
+
+// DebugFlag is the name of the flag added by AddDebugFlag.
+const DebugFlag = "debug"
+
+// AddDebugFlag adds the persistent --debug flag to cmd, which makes
+// RunE print the details of errors with call stacks.
+func AddDebugFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool(DebugFlag, false, "print details of errors")
+}
+
+// RunE wraps run to render the returned error to the error output of
+// the command like below.
+//
+//	app: Error: user not found (code: not_found)
+//
+// The prefix is the name of the root command, and the rest is printed
+// by failure.ExitErrorPrinter, which prints the details by %+v if the
+// --debug flag is set.
+// The output is colored if it is a terminal and the NO_COLOR
+// environment variable is not set.
+//
+// The error is returned as it is, but cobra is told not to print it
+// nor the usage again.
+func RunE(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := run(cmd, args)
+		if err == nil {
+			return nil
+		}
+
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
+		w := cmd.ErrOrStderr()
+		PrintError(w, cmd.Root().Name(), err, debugEnabled(cmd), colorEnabled(w))
+		return err
+	}
+}
+
+// PrintError prints err to w in the same way as RunE.
+// If color is true, the prefix and the code are painted, and the
+// details are printed by failure.ColorFormatter.
+func PrintError(w io.Writer, name string, err error, debug, color bool) {
+	prefix := name + ":"
+	if color {
+		prefix = failure.ColorCyan + prefix + "\x1b[0m"
+	}
+	fmt.Fprintf(w, "%s ", prefix)
+	failure.ExitErrorPrinter{Verbose: debug, Color: color}.Print(w, err)
+}
+
+func debugEnabled(cmd *cobra.Command) bool {
+	f := cmd.Flags().Lookup(DebugFlag)
+	return f != nil && f.Value.String() == "true"
+}
+
+func colorEnabled(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && failure.ColorEnabled(f)
+}
@@ -0,0 +1,96 @@
+package cobrautil_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/cobrautil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCommand(err error) (*cobra.Command, *bytes.Buffer) {
+	root := &cobra.Command{Use: "app"}
+	cobrautil.AddDebugFlag(root)
+	root.AddCommand(&cobra.Command{
+		Use: "sub",
+		RunE: cobrautil.RunE(func(cmd *cobra.Command, args []string) error {
+			return err
+		}),
+	})
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	return root, &buf
+}
+
+func TestRunE(t *testing.T) {
+	notFound := failure.New(failure.StringCode("not_found"), failure.Message("user not found"))
+
+	tests := map[string]struct {
+		err  error
+		args []string
+
+		want      string
+		wantStack bool
+	}{
+		"failure":  {notFound, []string{"sub"}, "app: Error: user not found (code: not_found)\n", false},
+		"debug":    {notFound, []string{"sub", "--debug"}, "app: Error: user not found (code: not_found)\n", true},
+		"standard": {errors.New("error"), []string{"sub"}, "app: Error: error\n", false},
+		"nil":      {nil, []string{"sub"}, "", false},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			cmd, buf := newCommand(test.err)
+			cmd.SetArgs(test.args)
+
+			err := cmd.Execute()
+			assert.Equal(t, test.err, err)
+			if test.wantStack {
+				assert.Contains(t, buf.String(), test.want)
+				assert.Contains(t, buf.String(), "[CallStack]\n")
+			} else {
+				assert.Equal(t, test.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	err := failure.New(failure.StringCode("not_found"), failure.Message("user not found"))
+
+	var buf bytes.Buffer
+	cobrautil.PrintError(&buf, "app", err, false, false)
+	assert.Equal(t, "app: Error: user not found (code: not_found)\n", buf.String())
+
+	buf.Reset()
+	cobrautil.PrintError(&buf, "app", err, true, true)
+	assert.True(t, strings.HasPrefix(buf.String(), "\x1b[36mapp:\x1b[0m \x1b[31mError:\x1b[0m user not found \x1b[2m(code: not_found)\x1b[0m\n"), buf.String())
+	assert.Contains(t, buf.String(), failure.ColorRed+"code(not_found)\x1b[0m\n")
+}
+
+func TestRunE_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmd, _ := newCommand(failure.New(failure.StringCode("not_found")))
+	cmd.SetErr(f)
+	cmd.SetArgs([]string{"sub", "--debug"})
+	cmd.Execute()
+
+	b, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "app: Error:")
+	assert.NotContains(t, string(b), "\x1b[")
+}
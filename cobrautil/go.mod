@@ -0,0 +1,18 @@
+module github.com/morikuni/failure/cobrautil
+
+go 1.21
+
+replace github.com/morikuni/failure => ../
+
+require (
+	github.com/morikuni/failure v0.0.0-00010101000000-000000000000
+	github.com/spf13/cobra v1.8.1
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+)
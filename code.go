@@ -1,8 +1,12 @@
 package failure
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // Is checks whether err represents any of given code.
+// Codes aliased by AliasCode match their new codes.
 func Is(err error, codes ...Code) bool {
 	if len(codes) == 0 {
 		return false
@@ -14,13 +18,68 @@ func Is(err error, codes ...Code) bool {
 	}
 
 	for i := range codes {
-		if c == codes[i] {
+		if c == resolveCode(codes[i]) {
 			return true
 		}
 	}
 	return false
 }
 
+// CodeMatches checks whether err represents a code matching
+// any of given patterns.
+// Codes can be organized hierarchically by separating namespaces
+// with "." like "storage.s3.not_found".
+// In the pattern, "*" matches any single namespace, and "*" at the
+// end of the pattern matches all the remaining namespaces.
+//
+//	"storage.s3.not_found" matches "storage.s3.not_found"
+//	"storage.*"            matches "storage.s3.not_found"
+//	"storage.*.not_found"  matches "storage.s3.not_found"
+//	"*.not_found"          does not match "storage.s3.not_found"
+func CodeMatches(err error, patterns ...string) bool {
+	c := CodeOf(err)
+	if c == nil {
+		return false
+	}
+
+	code := strings.Split(c.ErrorCode(), ".")
+	for _, p := range patterns {
+		if matchCode(strings.Split(p, "."), code) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeAs extracts the code of err as T.
+// It returns false if err has no code or the code is not T, so that
+// applications can use their own code types with compile-time safety.
+//
+//	type AppCode string
+//
+//	func (c AppCode) ErrorCode() string { return string(c) }
+//
+//	code, ok := failure.CodeAs[AppCode](err)
+func CodeAs[T Code](err error) (T, bool) {
+	c, ok := CodeOf(err).(T)
+	return c, ok
+}
+
+func matchCode(pattern, code []string) bool {
+	for i, p := range pattern {
+		if i >= len(code) {
+			return false
+		}
+		if p == "*" && i == len(pattern)-1 {
+			return true
+		}
+		if p != "*" && p != code[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(code)
+}
+
 // Code represents an error Code.
 // The code should not have internal state, so it should be
 // defined as a variable.
@@ -46,3 +105,11 @@ type IntCode int64
 func (c IntCode) ErrorCode() string {
 	return strconv.FormatInt(int64(c), 10)
 }
+
+// codeString returns c.ErrorCode(), or "<nil>" if c is nil.
+func codeString(c Code) string {
+	if c == nil {
+		return "<nil>"
+	}
+	return c.ErrorCode()
+}
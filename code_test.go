@@ -65,3 +65,59 @@ func TestIs(t *testing.T) {
 	assert.False(t, failure.Is(io.EOF, A, B))
 	assert.False(t, failure.Is(errA))
 }
+
+func TestCodeMatches(t *testing.T) {
+	const (
+		S3NotFound  failure.StringCode = "storage.s3.not_found"
+		GCSNotFound failure.StringCode = "storage.gcs.not_found"
+		Storage     failure.StringCode = "storage"
+	)
+
+	tests := map[string]struct {
+		err      error
+		patterns []string
+
+		want bool
+	}{
+		"exact":             {failure.New(S3NotFound), []string{"storage.s3.not_found"}, true},
+		"trailing wildcard": {failure.New(S3NotFound), []string{"storage.*"}, true},
+		"middle wildcard":   {failure.New(GCSNotFound), []string{"storage.*.not_found"}, true},
+		"any":               {failure.New(S3NotFound), []string{"*"}, true},
+		"parent":            {failure.New(Storage), []string{"storage.*"}, false},
+		"leading wildcard":  {failure.New(S3NotFound), []string{"*.not_found"}, false},
+		"different":         {failure.New(S3NotFound), []string{"storage.gcs.*"}, false},
+		"prefix":            {failure.New(S3NotFound), []string{"storage.s3"}, false},
+		"longer pattern":    {failure.New(Storage), []string{"storage.s3.not_found"}, false},
+		"multiple":          {failure.New(S3NotFound), []string{"storage.gcs.*", "storage.s3.*"}, true},
+		"no pattern":        {failure.New(S3NotFound), nil, false},
+		"no code":           {io.EOF, []string{"*"}, false},
+		"nil":               {nil, []string{"*"}, false},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, failure.CodeMatches(test.err, test.patterns...))
+		})
+	}
+}
+
+func TestCodeAs(t *testing.T) {
+	err := failure.New(CustomCode("custom"))
+
+	c, ok := failure.CodeAs[CustomCode](err)
+	assert.True(t, ok)
+	assert.Equal(t, CustomCode("custom"), c)
+
+	s, ok := failure.CodeAs[failure.StringCode](err)
+	assert.False(t, ok)
+	assert.Equal(t, failure.StringCode(""), s)
+
+	i, ok := failure.CodeAs[failure.IntCode](failure.Wrap(failure.New(TestCodeB)))
+	assert.True(t, ok)
+	assert.Equal(t, TestCodeB, i)
+
+	_, ok = failure.CodeAs[CustomCode](io.EOF)
+	assert.False(t, ok)
+	_, ok = failure.CodeAs[CustomCode](nil)
+	assert.False(t, ok)
+}
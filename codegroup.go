@@ -0,0 +1,58 @@
+package failure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CodeGroups is errors grouped by their codes.
+// Errors without code are grouped by the nil key.
+type CodeGroups map[Code][]error
+
+// GroupByCode groups errs by their codes in the original order.
+// nil errors are ignored.
+//
+//	groups := failure.GroupByCode(errs)
+//	log.Print(groups.Summary())
+func GroupByCode(errs []error) CodeGroups {
+	g := make(CodeGroups)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		c := CodeOf(err)
+		g[c] = append(g[c], err)
+	}
+	return g
+}
+
+// Summary returns a line per code with the number of errors and the
+// first error, ordered by the number of errors descending.
+//
+//	not_found: 120 errors (e.g. code(not_found): user 1)
+//	<nil>: 3 errors (e.g. EOF)
+func (g CodeGroups) Summary() string {
+	codes := make([]Code, 0, len(g))
+	for c := range g {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		ni, nj := len(g[codes[i]]), len(g[codes[j]])
+		if ni != nj {
+			return ni > nj
+		}
+		return codeString(codes[i]) < codeString(codes[j])
+	})
+
+	var sb strings.Builder
+	for _, c := range codes {
+		errs := g[c]
+		unit := "errors"
+		if len(errs) == 1 {
+			unit = "error"
+		}
+		fmt.Fprintf(&sb, "%s: %d %s (e.g. %v)\n", codeString(c), len(errs), unit, errs[0])
+	}
+	return sb.String()
+}
@@ -0,0 +1,33 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByCode(t *testing.T) {
+	notFound := failure.StringCode("not_found")
+	conflict := failure.StringCode("conflict")
+	here := failure.WithStaticCallStack(failure.NewFrame("main.f", "/main.go", 1))
+	errs := []error{
+		failure.New(notFound, failure.Message("user 1"), here),
+		io.EOF,
+		nil,
+		failure.New(conflict, here),
+		failure.New(notFound, failure.Message("user 2"), here),
+	}
+
+	g := failure.GroupByCode(errs)
+	assert.Equal(t, failure.CodeGroups{
+		notFound: {errs[0], errs[4]},
+		conflict: {errs[3]},
+		nil:      {errs[1]},
+	}, g)
+	assert.Equal(t, `not_found: 2 errors (e.g. f: code(not_found))
+<nil>: 1 error (e.g. EOF)
+conflict: 1 error (e.g. f: code(conflict))
+`, g.Summary())
+}
@@ -0,0 +1,122 @@
+package failure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Collector collects errors created during a request, including the
+// ones swallowed on the way, to report them at once at the end of the
+// request.
+//
+//	c := failure.NewCollector()
+//	ctx = failure.ContextWithCollector(ctx, c)
+//	...
+//	// anywhere in the request
+//	err = failure.Wrap(err, failure.CollectTo(ctx))
+//	...
+//	if len(c.Errors()) != 0 {
+//		c.Report(os.Stderr)
+//	}
+type Collector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewCollector creates a Collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Collect adds err to the collector. nil is ignored.
+func (c *Collector) Collect(err error) {
+	if err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errs = append(c.errs, err)
+}
+
+// Errors returns the collected errors in the collected order.
+func (c *Collector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]error(nil), c.errs...)
+}
+
+// Report writes all the collected errors with their call stacks by %+v.
+func (c *Collector) Report(w io.Writer) {
+	errs := c.Errors()
+	for i, err := range errs {
+		fmt.Fprintf(w, "[Error %d/%d] %v\n%+v", i+1, len(errs), err, err)
+	}
+}
+
+type collectorKey struct{}
+
+// ContextWithCollector returns a copy of ctx having c.
+func ContextWithCollector(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, collectorKey{}, c)
+}
+
+// CollectorFrom returns the Collector of ctx, or nil if ctx has no
+// Collector.
+func CollectorFrom(ctx context.Context) *Collector {
+	c, _ := ctx.Value(collectorKey{}).(*Collector)
+	return c
+}
+
+// CollectTo makes New, Translate, Wrap and WrapMultiple add the created
+// error to the Collector of ctx.
+// It does nothing if ctx has no Collector.
+func CollectTo(ctx context.Context) Wrapper {
+	c := CollectorFrom(ctx)
+	return WrapperFunc(func(err error) error {
+		if c == nil {
+			return err
+		}
+		return withCollector{err, c}
+	})
+}
+
+type withCollector struct {
+	error
+	collector *Collector
+}
+
+func (w withCollector) UnwrapError() error {
+	return w.error
+}
+
+func (w withCollector) Unwrap() error {
+	return w.error
+}
+
+func (w withCollector) detail(p palette) string {
+	return ""
+}
+
+// collect adds err to the Collectors appended by CollectTo to the
+// outermost layers of err created by the same constructor, so that
+// wrapping an error does not collect it again.
+func collect(err error) {
+	i := NewIterator(err)
+	formatters := 0
+	for i.Next() {
+		switch t := i.Error().(type) {
+		case formatter:
+			formatters++
+			if formatters > 1 {
+				return
+			}
+		case withCollector:
+			t.collector.Collect(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package failure_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	c := failure.NewCollector()
+	ctx := failure.ContextWithCollector(context.Background(), c)
+	assert.True(t, c == failure.CollectorFrom(ctx))
+
+	swallowed := failure.New(failure.StringCode("swallowed"), failure.CollectTo(ctx))
+	err := failure.New(failure.StringCode("not_found"), failure.CollectTo(ctx))
+	err = failure.Wrap(err)
+	failure.Wrap(errors.New("not collected"))
+	failure.New(failure.StringCode("no collector"), failure.CollectTo(context.Background()))
+
+	errs := c.Errors()
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, swallowed, errs[0])
+		assert.Equal(t, failure.StringCode("not_found"), failure.CodeOf(errs[1]))
+	}
+
+	var buf bytes.Buffer
+	c.Report(&buf)
+	assert.Contains(t, buf.String(), "[Error 1/2] TestCollector: code(swallowed)\n")
+	assert.Contains(t, buf.String(), "[Error 2/2] TestCollector: code(not_found)\n")
+	assert.Contains(t, buf.String(), "[CallStack]\n")
+
+	assert.NotContains(t, fmt.Sprintf("%+v", err), "error(")
+	assert.NotContains(t, failure.Tree(err), "error(")
+
+	assert.Nil(t, failure.CollectorFrom(context.Background()))
+	c.Collect(nil)
+	assert.Len(t, c.Errors(), 2)
+}
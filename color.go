@@ -0,0 +1,116 @@
+package failure
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used by ColorFormatter by default.
+const (
+	ColorRed   = "\x1b[31m"
+	ColorCyan  = "\x1b[36m"
+	ColorFaint = "\x1b[2m"
+	colorReset = "\x1b[0m"
+)
+
+// ColorFormatter is a Formatter which colors %+v for terminals.
+// Frames of the application, frames of dependencies and messages of
+// errors are printed in distinct colors, and other verbs are printed
+// by DefaultFormat.
+// It is opt-in, and ColorEnabled tells whether it should be used.
+//
+//	if failure.ColorEnabled(os.Stderr) {
+//		failure.SetFormatter(failure.ColorFormatter{})
+//	}
+type ColorFormatter struct {
+	// AppPackagePrefixes are prefixes of package paths of the
+	// application. Frames of OriginApp are of the application if
+	// empty.
+	AppPackagePrefixes []string
+	// Message is the color of messages, codes and errors.
+	// ColorRed is used if empty.
+	Message string
+	// App is the color of frames of the application.
+	// ColorCyan is used if empty.
+	App string
+	// Dependency is the color of other frames.
+	// ColorFaint is used if empty.
+	Dependency string
+}
+
+// FormatError implements the Formatter interface.
+func (cf ColorFormatter) FormatError(s fmt.State, verb rune, err error) {
+	if verb != 'v' || !s.Flag('+') || s.Flag('#') {
+		DefaultFormat(s, verb, err)
+		return
+	}
+
+	p := palette{
+		message:    cf.Message,
+		app:        cf.App,
+		dependency: cf.Dependency,
+		prefixes:   cf.AppPackagePrefixes,
+	}
+	if p.message == "" {
+		p.message = ColorRed
+	}
+	if p.app == "" {
+		p.app = ColorCyan
+	}
+	if p.dependency == "" {
+		p.dependency = ColorFaint
+	}
+	writeDetail(s, err, p)
+}
+
+// ColorEnabled reports whether f is a terminal and colors are not
+// disabled by the NO_COLOR environment variable, which disables
+// colors when it is set to a non-empty value.
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// palette is the colors used by writeDetail.
+// The zero value prints without colors.
+type palette struct {
+	message    string
+	app        string
+	dependency string
+	prefixes   []string
+}
+
+func (p palette) paint(color, s string) string {
+	if color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (p palette) frame(f Frame) string {
+	s := fmt.Sprintf("%+v", f)
+	if p.isApp(f) {
+		return p.paint(p.app, s)
+	}
+	return p.paint(p.dependency, s)
+}
+
+func (p palette) isApp(f Frame) bool {
+	if len(p.prefixes) == 0 {
+		return OriginOf(f) == OriginApp
+	}
+	pkg := PkgPathOf(f)
+	for _, prefix := range p.prefixes {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
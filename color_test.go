@@ -0,0 +1,81 @@
+package failure_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorFormatter(t *testing.T) {
+	defer failure.SetFormatter(nil)
+
+	err := failure.New(failure.StringCode("not_found"),
+		failure.Message("user not found"),
+		failure.WithStaticCallStack(
+			failure.NewFrame("github.com/foo/app/user.Find", "/src/app/user/find.go", 12),
+			failure.NewFrame("github.com/lib/db.Query", "/src/db/query.go", 30),
+		),
+	)
+	plain := fmt.Sprintf("%+v", err)
+
+	failure.SetFormatter(failure.ColorFormatter{AppPackagePrefixes: []string{"github.com/foo/app"}})
+
+	got := fmt.Sprintf("%+v", err)
+	assert.Contains(t, got, failure.ColorRed+`message("user not found")`+"\x1b[0m\n")
+	assert.Contains(t, got, failure.ColorRed+"code(not_found)\x1b[0m\n")
+	assert.Contains(t, got, "    "+failure.ColorCyan+"[Find] /src/app/user/find.go:12\x1b[0m\n")
+	assert.Contains(t, got, "    "+failure.ColorFaint+"[Query] /src/db/query.go:30\x1b[0m\n")
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+
+	failure.SetFormatter(nil)
+	assert.Equal(t, plain, fmt.Sprintf("%+v", err))
+}
+
+func TestColorFormatter_Origin(t *testing.T) {
+	defer failure.SetFormatter(nil)
+
+	err := failure.New(failure.StringCode("not_found"),
+		failure.WithStaticCallStack(
+			failure.NewFrame("github.com/morikuni/failure.Find", "/src/failure/find.go", 12),
+			failure.NewFrame("github.com/lib/db.Query", "/src/db/query.go", 30),
+		),
+	)
+
+	failure.SetFormatter(failure.ColorFormatter{})
+
+	got := fmt.Sprintf("%+v", err)
+	assert.Contains(t, got, "    "+failure.ColorCyan+"[Find] /src/failure/find.go:12\x1b[0m\n")
+	assert.Contains(t, got, "    "+failure.ColorFaint+"[Query] /src/db/query.go:30\x1b[0m\n")
+}
+
+func TestColorEnabled(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	assert.False(t, failure.ColorEnabled(f))
+	assert.False(t, failure.ColorEnabled(nil))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, failure.ColorEnabled(os.Stdout))
+}
+
+func TestColorEnabled_EmptyNoColor(t *testing.T) {
+	// /dev/null is a character device like terminals.
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, failure.ColorEnabled(f))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, failure.ColorEnabled(f))
+}
@@ -0,0 +1,118 @@
+package failure
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// compactVersion is the first byte of the encoding of EncodeCallStack.
+const compactVersion = 1
+
+// EncodeCallStack encodes the frames of cs compactly for storage.
+// Use DecodeCallStack to decode it.
+// The frames are encoded into the format below, where strings are
+// interned so that file paths and packages shared by frames are
+// stored only once.
+// Program counters are not stored because they are meaningless out of
+// the process.
+//
+//	version    byte
+//	nstrings   uvarint
+//	strings    (len uvarint, bytes)...
+//	nframes    uvarint
+//	frames     (function index uvarint, path index uvarint, line uvarint)...
+func EncodeCallStack(cs CallStack) []byte {
+	fs := cs.Frames()
+	index := make(map[string]uint64)
+	var strs []string
+	intern := func(s string) uint64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		i := uint64(len(strs))
+		index[s] = i
+		strs = append(strs, s)
+		return i
+	}
+
+	refs := make([]uint64, 0, len(fs)*3)
+	for _, f := range fs {
+		rf := RuntimeFrameOf(f)
+		refs = append(refs, intern(rf.Function), intern(rf.File), uint64(rf.Line))
+	}
+
+	b := []byte{compactVersion}
+	b = binary.AppendUvarint(b, uint64(len(strs)))
+	for _, s := range strs {
+		b = binary.AppendUvarint(b, uint64(len(s)))
+		b = append(b, s...)
+	}
+	b = binary.AppendUvarint(b, uint64(len(fs)))
+	for _, r := range refs {
+		b = binary.AppendUvarint(b, r)
+	}
+	return b
+}
+
+// DecodeCallStack decodes a call stack encoded by EncodeCallStack.
+func DecodeCallStack(b []byte) (CallStack, error) {
+	if len(b) == 0 || b[0] != compactVersion {
+		return nil, fmt.Errorf("failure: unsupported compact call stack")
+	}
+	b = b[1:]
+
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return 0, fmt.Errorf("failure: malformed compact call stack")
+		}
+		b = b[n:]
+		return v, nil
+	}
+
+	n, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(b)) {
+		return nil, fmt.Errorf("failure: malformed compact call stack")
+	}
+	strs := make([]string, n)
+	for i := range strs {
+		l, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if l > uint64(len(b)) {
+			return nil, fmt.Errorf("failure: malformed compact call stack")
+		}
+		strs[i] = string(b[:l])
+		b = b[l:]
+	}
+
+	n, err = uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(b)) {
+		return nil, fmt.Errorf("failure: malformed compact call stack")
+	}
+	fs := make([]Frame, n)
+	for i := range fs {
+		var refs [3]uint64
+		for j := range refs {
+			if refs[j], err = uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		if refs[0] >= uint64(len(strs)) || refs[1] >= uint64(len(strs)) {
+			return nil, fmt.Errorf("failure: malformed compact call stack")
+		}
+		fs[i] = NewFrame(strs[refs[0]], strs[refs[1]], int(refs[2]))
+	}
+	if len(b) != 0 {
+		return nil, fmt.Errorf("failure: malformed compact call stack")
+	}
+
+	return newCallStackFromFrames(fs), nil
+}
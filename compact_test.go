@@ -0,0 +1,54 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCallStack(t *testing.T) {
+	cs := X()
+
+	b := failure.EncodeCallStack(cs)
+	got, err := failure.DecodeCallStack(b)
+	if assert.NoError(t, err) {
+		assert.True(t, failure.EqualCallStacks(cs, got))
+		assert.Equal(t, "X", got.HeadFrame().Func())
+	}
+
+	jb, _ := json.Marshal(cs)
+	assert.True(t, len(b) < len(jb), "%d < %d", len(b), len(jb))
+
+	static := newTestCallStack("main.f /src/main.go:12", "main.g /src/main.go:20", "main.main /src/main.go:5")
+	got, err = failure.DecodeCallStack(failure.EncodeCallStack(static))
+	if assert.NoError(t, err) {
+		assert.Equal(t, failure.ShortCallStack(static, 0), failure.ShortCallStack(got, 0))
+	}
+
+	got, err = failure.DecodeCallStack(failure.EncodeCallStack(failure.NewCallStackFromFrames()))
+	if assert.NoError(t, err) {
+		assert.Empty(t, got.Frames())
+	}
+}
+
+func TestDecodeCallStack_Invalid(t *testing.T) {
+	valid := failure.EncodeCallStack(newTestCallStack("main.f /src/main.go:12"))
+
+	tests := map[string][]byte{
+		"empty":            nil,
+		"unknown version":  {2},
+		"truncated":        valid[:len(valid)-1],
+		"trailing bytes":   append(append([]byte{}, valid...), 0),
+		"too many strings": {1, 100},
+		"invalid index":    {1, 0, 1, 0, 0, 1},
+	}
+
+	for title, input := range tests {
+		t.Run(title, func(t *testing.T) {
+			_, err := failure.DecodeCallStack(input)
+			assert.Error(t, err)
+		})
+	}
+}
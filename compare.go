@@ -0,0 +1,259 @@
+package failure
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CompareOption changes how EqualCallStacks, DiffCallStacks and
+// EqualErrors compare errors and frames.
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	ignoreLines      bool
+	ignoreCallStacks bool
+	ignoreTimestamps bool
+	ignoreKeys       map[string]bool
+}
+
+// IgnoreLines makes comparison of call stacks ignore line numbers, so
+// that tests do not break when code around the frames moves.
+func IgnoreLines() CompareOption {
+	return func(o *compareOptions) {
+		o.ignoreLines = true
+	}
+}
+
+// IgnoreCallStacks makes EqualErrors ignore call stacks.
+func IgnoreCallStacks() CompareOption {
+	return func(o *compareOptions) {
+		o.ignoreCallStacks = true
+	}
+}
+
+// IgnoreTimestamps makes EqualErrors ignore when errors are created
+// and wrapped.
+func IgnoreTimestamps() CompareOption {
+	return func(o *compareOptions) {
+		o.ignoreTimestamps = true
+	}
+}
+
+// IgnoreDebugKeys makes EqualErrors ignore the keys of debug
+// information.
+func IgnoreDebugKeys(keys ...string) CompareOption {
+	return func(o *compareOptions) {
+		if o.ignoreKeys == nil {
+			o.ignoreKeys = make(map[string]bool)
+		}
+		for _, k := range keys {
+			o.ignoreKeys[k] = true
+		}
+	}
+}
+
+func newCompareOptions(opts []CompareOption) compareOptions {
+	var o compareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o compareOptions) equalFrame(a, b Frame) bool {
+	if o.ignoreLines {
+		return a.Path() == b.Path() && FuncFullOf(a) == FuncFullOf(b)
+	}
+	return a.Path() == b.Path() && a.Line() == b.Line() && FuncFullOf(a) == FuncFullOf(b)
+}
+
+// EqualCallStacks reports whether a and b have the same frames.
+func EqualCallStacks(a, b CallStack, opts ...CompareOption) bool {
+	return newCompareOptions(opts).equalCallStacks(a, b)
+}
+
+func (o compareOptions) equalCallStacks(a, b CallStack) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	fs, ofs := a.Frames(), b.Frames()
+	if len(fs) != len(ofs) {
+		return false
+	}
+	for i := range fs {
+		if !o.equalFrame(fs[i], ofs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffCallStacks returns the difference of frames from a to b.
+// It returns an empty string if they are equal.
+// Frames in the longest common subsequence are printed with "  ",
+// frames only in a with "- " and frames only in b with "+ ".
+//
+//	  [f] /path/to/a.go:10
+//	- [g] /path/to/a.go:20
+//	+ [h] /path/to/b.go:30
+//	  [main] /path/to/main.go:5
+func DiffCallStacks(a, b CallStack, opts ...CompareOption) string {
+	o := newCompareOptions(opts)
+	if o.equalCallStacks(a, b) {
+		return ""
+	}
+	var fs, ofs []Frame
+	if a != nil {
+		fs = a.Frames()
+	}
+	if b != nil {
+		ofs = b.Frames()
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// fs[i:] and ofs[j:].
+	lcs := make([][]int, len(fs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(ofs)+1)
+	}
+	for i := len(fs) - 1; i >= 0; i-- {
+		for j := len(ofs) - 1; j >= 0; j-- {
+			switch {
+			case o.equalFrame(fs[i], ofs[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(fs) || j < len(ofs) {
+		switch {
+		case i < len(fs) && j < len(ofs) && o.equalFrame(fs[i], ofs[j]):
+			fmt.Fprintf(&sb, "  %+v\n", fs[i])
+			i++
+			j++
+		case j == len(ofs) || (i < len(fs) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&sb, "- %+v\n", fs[i])
+			i++
+		default:
+			fmt.Fprintf(&sb, "+ %+v\n", ofs[j])
+			j++
+		}
+	}
+	return sb.String()
+}
+
+// EqualErrors reports whether a and b have the same layers from the
+// outermost, which have the same codes, messages, debug information,
+// call stacks and so on.
+// Errors not created by this package are compared by their types and
+// messages.
+//
+//	failure.EqualErrors(got, want, failure.IgnoreCallStacks(), failure.IgnoreTimestamps())
+func EqualErrors(a, b error, opts ...CompareOption) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	o := newCompareOptions(opts)
+	as, bs := o.layers(a), o.layers(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if !o.equalLayer(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o compareOptions) layers(err error) []error {
+	var es []error
+	i := NewIterator(err)
+	for i.Next() {
+		e := i.Error()
+		switch e.(type) {
+		case withCollector:
+			continue
+		case formatter:
+			if o.ignoreTimestamps {
+				continue
+			}
+		case withCallStack:
+			if o.ignoreCallStacks {
+				continue
+			}
+		}
+		es = append(es, e)
+	}
+	return es
+}
+
+func (o compareOptions) equalLayer(a, b error) bool {
+	type unredactedDebugGetter interface {
+		getUnredactedDebug() Debug
+	}
+	type messageGetter interface {
+		GetMessage() string
+	}
+	type retryabilityGetter interface {
+		GetRetryability() Retryability
+	}
+	type severityGetter interface {
+		GetSeverity() Severity
+	}
+	type payloadGetter interface {
+		GetPayload() interface{}
+	}
+	type annotationGetter interface {
+		GetAnnotation() string
+	}
+
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if cs := callStackOfLayer(a); cs != nil && !o.ignoreCallStacks {
+		return o.equalCallStacks(cs, callStackOfLayer(b))
+	}
+	ia, ib := &Iterator{err: a}, &Iterator{err: b}
+	if c := ia.Code(); c != nil {
+		return c == ib.Code()
+	}
+
+	switch t := a.(type) {
+	case formatter:
+		return t.timestamp.Equal(b.(formatter).timestamp)
+	case unredactedDebugGetter:
+		return reflect.DeepEqual(o.trimDebug(t.getUnredactedDebug()), o.trimDebug(b.(unredactedDebugGetter).getUnredactedDebug()))
+	case messageGetter:
+		return t.GetMessage() == b.(messageGetter).GetMessage()
+	case retryabilityGetter:
+		return t.GetRetryability() == b.(retryabilityGetter).GetRetryability()
+	case severityGetter:
+		return t.GetSeverity() == b.(severityGetter).GetSeverity()
+	case payloadGetter:
+		return reflect.DeepEqual(t.GetPayload(), b.(payloadGetter).GetPayload())
+	case annotationGetter:
+		return t.GetAnnotation() == b.(annotationGetter).GetAnnotation()
+	}
+	return a.Error() == b.Error()
+}
+
+func (o compareOptions) trimDebug(d Debug) Debug {
+	if len(o.ignoreKeys) == 0 {
+		return d
+	}
+	trimmed := make(Debug, len(d))
+	for k, v := range d {
+		if !o.ignoreKeys[k] {
+			trimmed[k] = v
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,101 @@
+package failure_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCallStack creates a call stack from frames like "main.f /a.go:1".
+func newTestCallStack(frames ...string) failure.CallStack {
+	return failure.NewCallStackFromFrames(failure.MustParseFrames(frames...)...)
+}
+
+func TestEqualCallStacks(t *testing.T) {
+	base := newTestCallStack("main.f /a.go:1", "main.main /main.go:5")
+
+	tests := map[string]struct {
+		other failure.CallStack
+		opts  []failure.CompareOption
+
+		want bool
+	}{
+		"equal":                {newTestCallStack("main.f /a.go:1", "main.main /main.go:5"), nil, true},
+		"different line":       {newTestCallStack("main.f /a.go:2", "main.main /main.go:5"), nil, false},
+		"ignore lines":         {newTestCallStack("main.f /a.go:2", "main.main /main.go:5"), []failure.CompareOption{failure.IgnoreLines()}, true},
+		"different func":       {newTestCallStack("main.g /a.go:1", "main.main /main.go:5"), []failure.CompareOption{failure.IgnoreLines()}, false},
+		"different length":     {newTestCallStack("main.main /main.go:5"), nil, false},
+		"nil":                  {nil, nil, false},
+		"real call stack":      {failure.Callers(0), nil, false},
+		"different file":       {newTestCallStack("main.f /b.go:1", "main.main /main.go:5"), []failure.CompareOption{failure.IgnoreLines()}, false},
+		"same frames filtered": {failure.FilterCallStack(newTestCallStack("main.f /a.go:1", "main.g /a.go:3", "main.main /main.go:5"), func(f failure.Frame) bool { return f.Func() != "g" }), nil, true},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, failure.EqualCallStacks(base, test.other, test.opts...))
+			assert.Equal(t, test.want, failure.DiffCallStacks(base, test.other, test.opts...) == "")
+		})
+	}
+}
+
+func TestDiffCallStacks(t *testing.T) {
+	a := newTestCallStack("main.f /a.go:1", "main.g /a.go:2", "main.main /main.go:5")
+	b := newTestCallStack("main.f /a.go:1", "main.h /b.go:3", "main.main /main.go:5")
+
+	want := `  [f] /a.go:1
+- [g] /a.go:2
++ [h] /b.go:3
+  [main] /main.go:5
+`
+	assert.Equal(t, want, failure.DiffCallStacks(a, b))
+
+	want = `- [f] /a.go:1
+- [g] /a.go:2
+- [main] /main.go:5
+`
+	assert.Equal(t, want, failure.DiffCallStacks(a, nil))
+}
+
+func TestEqualErrors(t *testing.T) {
+	newErr := func(id string, line int) error {
+		return failure.Translate(io.EOF, failure.StringCode("not_found"),
+			failure.Message("not found"),
+			failure.Debug{"id": id, "request_id": id},
+			failure.WithStaticCallStack(failure.NewFrame("main.f", "/a.go", line)),
+		)
+	}
+
+	a := newErr("1", 1)
+	assert.True(t, failure.EqualErrors(a, a))
+	time.Sleep(time.Millisecond)
+	assert.False(t, failure.EqualErrors(a, newErr("1", 1)))
+	assert.True(t, failure.EqualErrors(a, newErr("1", 1), failure.IgnoreTimestamps()))
+	assert.False(t, failure.EqualErrors(a, newErr("1", 2), failure.IgnoreTimestamps()))
+	assert.True(t, failure.EqualErrors(a, newErr("1", 2), failure.IgnoreTimestamps(), failure.IgnoreLines()))
+	assert.True(t, failure.EqualErrors(a, newErr("1", 2), failure.IgnoreTimestamps(), failure.IgnoreCallStacks()))
+	assert.False(t, failure.EqualErrors(a, newErr("2", 1), failure.IgnoreTimestamps()))
+	assert.True(t, failure.EqualErrors(a, newErr("2", 1), failure.IgnoreTimestamps(), failure.IgnoreDebugKeys("id", "request_id")))
+
+	opts := []failure.CompareOption{failure.IgnoreTimestamps(), failure.IgnoreCallStacks()}
+	assert.True(t, failure.EqualErrors(a, failure.Wrap(a), opts...))
+	assert.False(t, failure.EqualErrors(a, failure.Wrap(a, failure.Message("x")), opts...))
+	assert.False(t, failure.EqualErrors(
+		failure.New(failure.StringCode("a"), failure.Message("x")),
+		failure.New(failure.StringCode("b"), failure.Message("x")),
+		opts...,
+	))
+	assert.False(t, failure.EqualErrors(
+		failure.Wrap(io.EOF, failure.MarkRetryable()),
+		failure.Wrap(io.EOF, failure.MarkNotRetryable()),
+		opts...,
+	))
+	assert.True(t, failure.EqualErrors(failure.Wrap(io.EOF), failure.Wrap(errors.New("EOF")), opts...))
+	assert.False(t, failure.EqualErrors(failure.Wrap(io.EOF), failure.Wrap(io.ErrUnexpectedEOF), opts...))
+	assert.True(t, failure.EqualErrors(nil, nil))
+	assert.False(t, failure.EqualErrors(a, nil))
+}
@@ -0,0 +1,55 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Error codes used by FromContextError.
+const (
+	DeadlineExceededCode StringCode = "failure.deadline_exceeded"
+	CanceledCode         StringCode = "failure.canceled"
+)
+
+// FromContextError translates err caused by context.DeadlineExceeded
+// or context.Canceled into an error with DeadlineExceededCode or
+// CanceledCode. Other errors are returned as they are.
+//
+// The deadline of ctx and how long it has passed are appended as debug
+// information keyed by "deadline" and "overdue", and the cause of the
+// cancellation set by context.WithCancelCause is keyed by "cause".
+//
+//	if err := db.QueryContext(ctx, q); err != nil {
+//		return failure.FromContextError(ctx, err)
+//	}
+func FromContextError(ctx context.Context, err error) error {
+	var code Code
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = DeadlineExceededCode
+	case errors.Is(err, context.Canceled):
+		code = CanceledCode
+	default:
+		return err
+	}
+
+	debug := Debug{}
+	if ctx != nil {
+		if d, ok := ctx.Deadline(); ok {
+			debug["deadline"] = d
+			if overdue := time.Since(d); overdue > 0 {
+				debug["overdue"] = overdue
+			}
+		}
+		if cause := context.Cause(ctx); cause != nil && cause != ctx.Err() {
+			debug["cause"] = cause
+		}
+	}
+
+	wrappers := []Wrapper{WithCallStackSkip(1)}
+	if len(debug) != 0 {
+		wrappers = append(wrappers, debug)
+	}
+	return Translate(err, code, wrappers...)
+}
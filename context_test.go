@@ -0,0 +1,44 @@
+package failure_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContextError(t *testing.T) {
+	deadline := time.Now().Add(-time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	err := failure.FromContextError(ctx, fmt.Errorf("query: %w", ctx.Err()))
+	assert.Equal(t, failure.DeadlineExceededCode, failure.CodeOf(err))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	d, _ := failure.ValueAs[time.Time](err, "deadline")
+	assert.True(t, d.Equal(deadline))
+	overdue, _ := failure.ValueAs[time.Duration](err, "overdue")
+	assert.True(t, overdue >= time.Second)
+	assert.Equal(t, "TestFromContextError", failure.CallStackOf(err).HeadFrame().Func())
+
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	cancelCause(io.ErrClosedPipe)
+	err = failure.FromContextError(ctx, ctx.Err())
+	assert.Equal(t, failure.CanceledCode, failure.CodeOf(err))
+	cause, _ := failure.ValueOf(err, "cause")
+	assert.Equal(t, io.ErrClosedPipe, cause)
+	_, ok := failure.ValueOf(err, "deadline")
+	assert.False(t, ok)
+
+	err = failure.FromContextError(nil, context.Canceled)
+	assert.Equal(t, failure.CanceledCode, failure.CodeOf(err))
+	assert.Empty(t, failure.DebugsOf(err))
+
+	assert.Equal(t, io.EOF, failure.FromContextError(context.Background(), io.EOF))
+	assert.Nil(t, failure.FromContextError(context.Background(), nil))
+}
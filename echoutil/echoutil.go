@@ -0,0 +1,54 @@
+// Package echoutil provides a middleware of github.com/labstack/echo
+// handling errors of the failure package.
+//
+//	e := echo.New()
+//	e.Use(echoutil.Middleware(httpserver.Config{}))
+package echoutil
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/httpserver"
+)
+
+// Middleware returns a middleware which recovers panics and handles
+// errors returned by handlers with cfg.
+// *echo.HTTPError without an error code, like the one returned for
+// unknown routes, is returned as it is so that echo handles it.
+// The request ID is taken from the X-Request-Id header of the response
+// set by the RequestID middleware of echo if cfg.RequestID is nil.
+func Middleware(cfg httpserver.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := serve(next, c)
+			if err == nil {
+				return nil
+			}
+
+			var he *echo.HTTPError
+			if errors.As(err, &he) && failure.CodeOf(err) == nil {
+				return err
+			}
+
+			cfg := cfg
+			if cfg.RequestID == nil {
+				cfg.RequestID = func(r *http.Request) string {
+					if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+						return id
+					}
+					return r.Header.Get(echo.HeaderXRequestID)
+				}
+			}
+			cfg.HandleError(c.Response(), c.Request(), err)
+			return nil
+		}
+	}
+}
+
+func serve(next echo.HandlerFunc, c echo.Context) (err error) {
+	defer failure.Recover(&err, failure.PanicCode)
+	return next(c)
+}
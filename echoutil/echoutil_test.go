@@ -0,0 +1,72 @@
+package echoutil_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/echoutil"
+	"github.com/morikuni/failure/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "echoutil.not_found"
+
+func init() {
+	failure.RegisterHTTPStatus(NotFound, http.StatusNotFound)
+}
+
+func TestMiddleware(t *testing.T) {
+	var logged error
+	e := echo.New()
+	e.Use(echoutil.Middleware(httpserver.Config{
+		Log: func(r *http.Request, err error) {
+			logged = err
+		},
+	}))
+	e.GET("/error", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+		return failure.New(NotFound)
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	})
+
+	tests := map[string]struct {
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		"error":   {"/error", http.StatusNotFound, "echoutil.not_found"},
+		"panic":   {"/panic", http.StatusInternalServerError, "failure.panic"},
+		"unknown": {"/unknown", http.StatusNotFound, ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			logged = nil
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			if test.wantCode == "" {
+				assert.Nil(t, logged)
+				return
+			}
+			var res struct {
+				Code string `json:"code"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+			assert.Equal(t, test.wantCode, res.Code)
+			assert.NotNil(t, failure.CallStackOf(logged))
+		})
+	}
+
+	logged = nil
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+	v, _ := failure.ValueOf(logged, httpserver.RequestIDKey)
+	assert.Equal(t, "req-1", v)
+}
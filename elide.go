@@ -0,0 +1,63 @@
+package failure
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+var keepInternalFrames int32
+
+// SetKeepInternalFrames sets whether call stacks keep frames of this
+// module itself like middlewares and helpers spawning goroutines.
+// They are elided by default, as testing trims tRunner, so that call
+// stacks show only the code of the application. Frames of test
+// packages of this module are never elided.
+// It is safe to call SetKeepInternalFrames concurrently, and it
+// affects call stacks whose frames are not resolved yet.
+func SetKeepInternalFrames(keep bool) {
+	var v int32
+	if keep {
+		v = 1
+	}
+	atomic.StoreInt32(&keepInternalFrames, v)
+}
+
+// modulePath is the path of this module, which is taken from the
+// package path to support forks.
+var modulePath = reflect.TypeOf(Failure{}).PkgPath()
+
+func isInternalFrame(f Frame) bool {
+	pkg := PkgPathOf(f)
+	if pkg != modulePath && !strings.HasPrefix(pkg, modulePath+"/") {
+		return false
+	}
+	return !strings.HasSuffix(pkg, "_test") && !strings.Contains(pkg, "/testdata/")
+}
+
+// elideInternalFrames removes internal frames from fs unless they are
+// kept by SetKeepInternalFrames.
+// fs is returned as it is if all frames are internal.
+func elideInternalFrames(fs []Frame) []Frame {
+	if atomic.LoadInt32(&keepInternalFrames) != 0 {
+		return fs
+	}
+
+	n := 0
+	for _, f := range fs {
+		if !isInternalFrame(f) {
+			n++
+		}
+	}
+	if n == len(fs) || n == 0 {
+		return fs
+	}
+
+	elided := make([]Frame, 0, n)
+	for _, f := range fs {
+		if !isInternalFrame(f) {
+			elided = append(elided, f)
+		}
+	}
+	return elided
+}
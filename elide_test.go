@@ -0,0 +1,36 @@
+package failure_test
+
+import (
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func internalFuncs(err error) []string {
+	var fs []string
+	for _, f := range failure.CallStackOf(err).Frames() {
+		if failure.PkgPathOf(f) == "github.com/morikuni/failure" {
+			fs = append(fs, f.Func())
+		}
+	}
+	return fs
+}
+
+func TestSetKeepInternalFrames(t *testing.T) {
+	defer failure.SetKeepInternalFrames(false)
+
+	newErr := func() error {
+		return <-failure.Go(func() error {
+			return failure.New(failure.StringCode("code"))
+		})
+	}
+
+	err := newErr()
+	assert.Empty(t, internalFuncs(err))
+	assert.Equal(t, "TestSetKeepInternalFrames.func1.func1", failure.CallStackOf(err).HeadFrame().Func())
+
+	failure.SetKeepInternalFrames(true)
+	err = newErr()
+	assert.Equal(t, []string{"Go.func1"}, internalFuncs(err))
+}
@@ -0,0 +1,131 @@
+package failure
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	exitCodesMu sync.RWMutex
+	exitCodes   = make(map[Code]int)
+)
+
+// RegisterExitCode registers the exit code of processes for the error
+// code.
+func RegisterExitCode(code Code, exitCode int) {
+	exitCodesMu.Lock()
+	defer exitCodesMu.Unlock()
+
+	exitCodes[resolveCode(code)] = exitCode
+}
+
+// ExitCodeOf returns the exit code registered for the error code of
+// err.
+// It returns 0 if err is nil, and 1 if no exit code is registered.
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	c := CodeOf(err)
+	if c == nil {
+		return 1
+	}
+
+	exitCodesMu.RLock()
+	defer exitCodesMu.RUnlock()
+
+	if n, ok := lookupAliased(exitCodes, c); ok {
+		return n
+	}
+	return 1
+}
+
+// ExitOnError prints err to os.Stderr by PrintExitError, and exits the
+// process with ExitCodeOf(err).
+// It does nothing if err is nil.
+// The call stack is printed only if the command line has a -v or
+// --verbose flag.
+//
+//	func main() {
+//		failure.ExitOnError(run())
+//	}
+func ExitOnError(err error) {
+	if err == nil {
+		return
+	}
+	PrintExitError(os.Stderr, err, hasVerboseFlag(os.Args[1:]))
+	os.Exit(ExitCodeOf(err))
+}
+
+// PrintExitError prints err to w for users of command line tools.
+// The message from MessageOf is printed with the code if any, and
+// err.Error() is used if err has no message.
+// If verbose is true, the details printed by %+v follow.
+//
+//	Error: user not found (code: not_found)
+func PrintExitError(w io.Writer, err error, verbose bool) {
+	ExitErrorPrinter{Verbose: verbose}.Print(w, err)
+}
+
+// ExitErrorPrinter prints errors for users of command line tools in
+// the same format as PrintExitError.
+type ExitErrorPrinter struct {
+	// Verbose makes the details printed by %+v follow.
+	Verbose bool
+	// Color paints "Error:" and the code, and prints the details by
+	// ColorFormatter. ColorEnabled tells whether it should be enabled.
+	Color bool
+}
+
+// Print prints err to w.
+func (p ExitErrorPrinter) Print(w io.Writer, err error) {
+	paint := func(color, s string) string {
+		if !p.Color {
+			return s
+		}
+		return color + s + colorReset
+	}
+
+	msg := MessageOf(err)
+	if msg == "" {
+		msg = err.Error()
+	}
+	fmt.Fprintf(w, "%s %s", paint(ColorRed, "Error:"), msg)
+	if c := CodeOf(err); c != nil {
+		fmt.Fprintf(w, " %s", paint(ColorFaint, "(code: "+c.ErrorCode()+")"))
+	}
+	io.WriteString(w, "\n")
+	if !p.Verbose {
+		return
+	}
+	if p.Color {
+		fmt.Fprintf(w, "%+v", colorFormatted{err})
+	} else {
+		fmt.Fprintf(w, "%+v", err)
+	}
+}
+
+// colorFormatted formats the error by ColorFormatter regardless of the
+// formatter set by SetFormatter.
+type colorFormatted struct {
+	err error
+}
+
+func (c colorFormatted) Format(s fmt.State, verb rune) {
+	ColorFormatter{}.FormatError(s, verb, c.err)
+}
+
+func hasVerboseFlag(args []string) bool {
+	for _, a := range args {
+		switch a {
+		case "--":
+			return false
+		case "-v", "--v", "-v=true", "--v=true", "-verbose", "--verbose", "-verbose=true", "--verbose=true":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package failure_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeOf(t *testing.T) {
+	const (
+		Usage   failure.StringCode = "exit_usage"
+		Unknown failure.StringCode = "exit_unknown"
+	)
+	failure.RegisterExitCode(Usage, 2)
+
+	assert.Equal(t, 0, failure.ExitCodeOf(nil))
+	assert.Equal(t, 2, failure.ExitCodeOf(failure.New(Usage)))
+	assert.Equal(t, 2, failure.ExitCodeOf(failure.Wrap(failure.New(Usage))))
+	assert.Equal(t, 1, failure.ExitCodeOf(failure.New(Unknown)))
+	assert.Equal(t, 1, failure.ExitCodeOf(errors.New("error")))
+}
+
+func TestPrintExitError(t *testing.T) {
+	err := failure.New(failure.StringCode("not_found"), failure.Message("user not found"))
+
+	var buf bytes.Buffer
+	failure.PrintExitError(&buf, err, false)
+	assert.Equal(t, "Error: user not found (code: not_found)\n", buf.String())
+
+	buf.Reset()
+	failure.PrintExitError(&buf, errors.New("error"), false)
+	assert.Equal(t, "Error: error\n", buf.String())
+
+	buf.Reset()
+	failure.PrintExitError(&buf, err, true)
+	assert.Contains(t, buf.String(), "Error: user not found (code: not_found)\n")
+	assert.Contains(t, buf.String(), "[CallStack]\n")
+}
+
+func TestExitErrorPrinter(t *testing.T) {
+	err := failure.New(failure.StringCode("not_found"), failure.Message("user not found"))
+
+	var buf bytes.Buffer
+	failure.ExitErrorPrinter{Color: true}.Print(&buf, err)
+	assert.Equal(t, "\x1b[31mError:\x1b[0m user not found \x1b[2m(code: not_found)\x1b[0m\n", buf.String())
+
+	buf.Reset()
+	failure.ExitErrorPrinter{Verbose: true, Color: true}.Print(&buf, err)
+	assert.Contains(t, buf.String(), failure.ColorRed+"code(not_found)\x1b[0m\n")
+}
+
+func TestExitOnError(t *testing.T) {
+	if args, ok := os.LookupEnv("FAILURE_TEST_EXIT_ARGS"); ok {
+		os.Args = append(os.Args[:1], strings.Fields(args)...)
+		failure.RegisterExitCode(failure.StringCode("usage"), 3)
+		failure.ExitOnError(nil)
+		failure.ExitOnError(failure.New(failure.StringCode("usage"), failure.Message("bad flag")))
+		return
+	}
+
+	tests := map[string]struct {
+		args string
+
+		wantStack bool
+	}{
+		"quiet":   {"", false},
+		"verbose": {"-v", true},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestExitOnError$")
+			cmd.Env = append(os.Environ(), "FAILURE_TEST_EXIT_ARGS="+test.args)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+
+			err := cmd.Run()
+			var exitErr *exec.ExitError
+			if assert.True(t, errors.As(err, &exitErr)) {
+				assert.Equal(t, 3, exitErr.ExitCode())
+			}
+			assert.Contains(t, stderr.String(), "Error: bad flag (code: usage)\n")
+			assert.Equal(t, test.wantStack, bytes.Contains(stderr.Bytes(), []byte("[CallStack]")))
+		})
+	}
+}
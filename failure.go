@@ -19,14 +19,36 @@ func (f Failure) UnwrapError() error {
 	return f.underlying
 }
 
+// Unwrap returns the underlying error.
+// It is used by errors.Is and errors.As of the standard library.
+func (f Failure) Unwrap() error {
+	return f.underlying
+}
+
+// Is reports whether target has the same error code as f.
+// It makes errors.Is of the standard library match errors by
+// error code using a sentinel error like below.
+//
+//	var ErrNotFound = failure.New(NotFound)
+//
+//	errors.Is(err, ErrNotFound)
+func (f Failure) Is(target error) bool {
+	c := CodeOf(target)
+	return c != nil && c == resolveCode(f.code)
+}
+
 // GetCode returns the error code of the error.
 func (f Failure) GetCode() Code {
 	return f.code
 }
 
+func (f Failure) detail(p palette) string {
+	return p.paint(p.message, fmt.Sprintf("code(%s)", codeString(f.code)))
+}
+
 // Error implements the error interface.
 func (f Failure) Error() string {
-	msg := fmt.Sprintf("code(%s)", f.code.ErrorCode())
+	msg := fmt.Sprintf("code(%s)", codeString(f.code))
 	if f.underlying != nil {
 		msg = strings.Join([]string{msg, f.underlying.Error()}, ": ")
 	}
@@ -34,6 +56,7 @@ func (f Failure) Error() string {
 }
 
 // CodeOf extracts an error Code from the error.
+// If the code is aliased by AliasCode, the new code is returned.
 func CodeOf(err error) Code {
 	if err == nil {
 		return nil
@@ -47,37 +70,127 @@ func CodeOf(err error) Code {
 	for i.Next() {
 		err := i.Error()
 		if g, ok := err.(codeGetter); ok {
-			return g.GetCode()
+			return resolveCode(g.GetCode())
 		}
 	}
 
 	return nil
 }
 
+// CodesOf extracts all the error codes from the error.
+// Returned codes are ordered from the outermost, so the last one is the
+// original code of an error translated by Translate.
+func CodesOf(err error) []Code {
+	if err == nil {
+		return nil
+	}
+
+	var codes []Code
+	i := NewIterator(err)
+	for i.Next() {
+		if c := i.Code(); c != nil {
+			codes = append(codes, c)
+		}
+	}
+
+	return codes
+}
+
 // New creates a Failure from error Code.
 func New(code Code, wrappers ...Wrapper) error {
-	return newFailure(nil, code, wrappers)
+	return newFailure(nil, code, wrappers, 2)
 }
 
 // Translate translates err to an error with given code.
 // It wraps the error with given wrappers, and automatically
 // add call stack and formatter.
+//
+// The original error chain is preserved, so CodeOf and MessageOf
+// return the translated ones while the original code is still
+// available from CodesOf, errors.Is and the call stacks.
+//
+//	if errors.Is(err, sql.ErrNoRows) {
+//		return failure.Translate(err, UserNotFound, failure.Message("user not found"))
+//	}
 func Translate(err error, code Code, wrappers ...Wrapper) error {
-	return newFailure(err, code, wrappers)
+	return newFailure(err, code, wrappers, 2)
 }
 
 // Wrap wraps err with given wrappers, and automatically add
 // call stack and formatter.
 func Wrap(err error, wrappers ...Wrapper) error {
-	return Custom(err, append(wrappers, WithCallStackSkip(1), WithFormatter())...)
+	return wrap(err, wrappers, 2)
 }
 
-func newFailure(err error, code Code, wrappers []Wrapper) error {
+func wrap(err error, wrappers []Wrapper, skip int) error {
+	if shouldCapture(err, nil) {
+		wrappers = withDefaultCallStack(wrappers, err, nil, skip)
+	}
+	err = Custom(err, append(wrappers, WithFormatter())...)
+	runHooks(err)
+	return err
+}
+
+// WrapMultiple wraps errs as one error with given wrappers, and
+// automatically add call stack and formatter.
+// nil errors in errs are ignored, and it returns nil if no error
+// remains.
+//
+// The first error is treated as the primary error, so CodeOf,
+// MessageOf and other accessors using Iterator follow it.
+// All errors are visible from errors.Is and errors.As through
+// the Unwrap() []error method.
+func WrapMultiple(errs []error, wrappers ...Wrapper) error {
+	var es []error
+	for _, err := range errs {
+		if err != nil {
+			es = append(es, err)
+		}
+	}
+	if len(es) == 0 {
+		return nil
+	}
+	if shouldCapture(es[0], nil) {
+		wrappers = withDefaultCallStack(wrappers, es[0], nil, 1)
+	}
+	err := Custom(&multiError{es}, append(wrappers, WithFormatter())...)
+	runHooks(err)
+	return err
+}
+
+// multiError is used as a pointer to be comparable like withDebug.
+type multiError struct {
+	errs []error
+}
+
+func (e multiError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e multiError) UnwrapError() error {
+	return e.errs[0]
+}
+
+func (e multiError) Unwrap() []error {
+	return e.errs
+}
+
+func newFailure(err error, code Code, wrappers []Wrapper, skip int) error {
 	f := Failure{
 		code,
 		err,
 	}
-	return Custom(f, append(wrappers, WithCallStackSkip(2), WithFormatter())...)
+	if shouldCapture(nil, code) {
+		wrappers = withDefaultCallStack(wrappers, nil, code, skip)
+	}
+	err = Custom(f, append(wrappers, WithFormatter())...)
+	runHooks(err)
+	runDeprecatedCodeHook(err, code)
+	return err
 }
 
 // Custom is the general error wrapping constructor.
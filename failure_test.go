@@ -1,12 +1,12 @@
 package failure_test
 
 import (
+	stderrors "errors"
 	"fmt"
 	"io"
 	"testing"
 
 	"github.com/morikuni/failure"
-	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +18,7 @@ const (
 
 func TestFailure(t *testing.T) {
 	base := failure.New(TestCodeA, failure.Message("xxx"), failure.Debug{"zzz": true})
-	pkgErr := errors.New("yyy")
+	stackErr := newCallersError()
 	tests := map[string]struct {
 		err error
 
@@ -79,15 +79,15 @@ func TestFailure(t *testing.T) {
 			wantStackLine: 0,
 			wantError:     "",
 		},
-		"pkg/errors": {
-			err: failure.Translate(pkgErr, TestCodeB, failure.Message("aaa")),
+		"error with call stack": {
+			err: failure.Translate(stackErr, TestCodeB, failure.Message("aaa")),
 
 			shouldNil:     false,
 			wantCode:      TestCodeB,
 			wantMessage:   "aaa",
 			wantDebugs:    nil,
-			wantStackLine: 21,
-			wantError:     "TestFailure: code(1): yyy",
+			wantStackLine: 32,
+			wantError:     "TestFailure: code(1): callers",
 		},
 		"nil": {
 			err: nil,
@@ -152,7 +152,7 @@ func TestFailure_Format(t *testing.T) {
 	assert.Equal(t, want, fmt.Sprintf("%s", err))
 	assert.Equal(t, want, fmt.Sprintf("%v", err))
 
-	exp := `failure.formatter{error:failure.withCallStack{.*`
+	exp := `^failure.layers{"\[TestFailure_Format\] /.+/failure_test.go:149", "\[TestFailure_Format\] /.+/failure_test.go:148", "debug\(zzz=true\)", "message\(\\"xxx\\"\)", "code\(code_a\)", "error\(\\"yyy\\"\)"}$`
 	assert.Regexp(t, exp, fmt.Sprintf("%#v", err))
 
 	exp = `\[TestFailure_Format\] /.*/github.com/morikuni/failure/failure_test.go:149
@@ -168,7 +168,72 @@ func TestFailure_Format(t *testing.T) {
 }
 
 func BenchmarkFailure(b *testing.B) {
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		failure.Wrap(failure.Translate(failure.New(failure.StringCode("error")), failure.StringCode("failure")))
 	}
 }
+
+func TestWrapMultiple(t *testing.T) {
+	rollbackErr := fmt.Errorf("rollback")
+	base := failure.New(TestCodeA, failure.Message("xxx"))
+
+	err := failure.WrapMultiple([]error{base, nil, rollbackErr}, failure.Debug{"zzz": true})
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "xxx", failure.MessageOf(err))
+	assert.Equal(t, []failure.Debug{{"zzz": true}}, failure.DebugsOf(err))
+	assert.Equal(t, "TestWrapMultiple", failure.CallStackOf(err).HeadFrame().Func())
+	assert.EqualError(t, err, "TestWrapMultiple: TestWrapMultiple: code(code_a); rollback")
+
+	assert.True(t, stderrors.Is(err, rollbackErr))
+	assert.True(t, stderrors.Is(err, base))
+
+	assert.Nil(t, failure.WrapMultiple(nil))
+	assert.Nil(t, failure.WrapMultiple([]error{nil}))
+}
+
+func newNotFound() error {
+	return failure.New(TestCodeA, failure.WithCallStackSkip(1))
+}
+
+func wrapHelper(err error) error {
+	return failure.Wrap(err, failure.WithCallStackSkip(1))
+}
+
+func TestWithCallStackSkip(t *testing.T) {
+	err := newNotFound()
+
+	assert.Equal(t, "TestWithCallStackSkip", failure.CallStackOf(err).HeadFrame().Func())
+	assert.EqualError(t, err, "TestWithCallStackSkip: code(code_a)")
+
+	err = wrapHelper(io.EOF)
+	assert.Equal(t, "TestWithCallStackSkip", failure.CallStackOf(err).HeadFrame().Func())
+	assert.EqualError(t, err, "TestWithCallStackSkip: EOF")
+
+	cs := failure.Callers(0)
+	err = failure.Translate(io.EOF, TestCodeB, failure.WithCallStack(cs))
+	assert.Equal(t, cs, failure.CallStackOf(err))
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		failure.New(TestCodeA)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	base := failure.New(TestCodeA, failure.Message("internal"))
+	err := failure.Translate(base, TestCodeB, failure.Message("public"))
+
+	assert.Equal(t, TestCodeB, failure.CodeOf(err))
+	assert.Equal(t, "public", failure.MessageOf(err))
+	assert.Equal(t, []failure.Code{TestCodeB, TestCodeA}, failure.CodesOf(err))
+	assert.True(t, stderrors.Is(err, base))
+	assert.Len(t, failure.CallStacksOf(err), 2)
+
+	assert.Equal(t, []failure.Code{TestCodeB}, failure.CodesOf(failure.Translate(io.EOF, TestCodeB)))
+	assert.Nil(t, failure.CodesOf(io.EOF))
+	assert.Nil(t, failure.CodesOf(nil))
+}
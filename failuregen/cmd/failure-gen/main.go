@@ -0,0 +1,72 @@
+// Command failure-gen generates code of error codes from a spec file.
+// See the failuregen package for the format of the spec.
+//
+//	//go:generate failure-gen -spec codes.yaml -go codes_gen.go -ts ../web/src/codes.ts -openapi ../api/codes.yaml -openapi-responses ../api/responses.yaml -docs ../docs/errors
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/morikuni/failure/failuregen"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "failure-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("failure-gen", flag.ContinueOnError)
+	specPath := fs.String("spec", "codes.yaml", "path of the spec file")
+	goPath := fs.String("go", "", "path of the generated Go file")
+	tsPath := fs.String("ts", "", "path of the generated TypeScript file")
+	openAPIPath := fs.String("openapi", "", "path of the generated OpenAPI schema")
+	responsesPath := fs.String("openapi-responses", "", "path of the generated OpenAPI components of error responses")
+	docsDir := fs.String("docs", "", "directory of the generated documents of the codes")
+	docsFormat := fs.String("docs-format", failuregen.FormatMarkdown, "format of the documents: markdown or html")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*specPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	spec, err := failuregen.LoadSpec(f)
+	if err != nil {
+		return err
+	}
+
+	outputs := []struct {
+		path  string
+		write func(io.Writer) error
+	}{
+		{*goPath, spec.WriteGo},
+		{*tsPath, spec.WriteTypeScript},
+		{*openAPIPath, spec.WriteOpenAPIEnum},
+		{*responsesPath, spec.WriteOpenAPIResponses},
+	}
+	for _, o := range outputs {
+		if o.path == "" {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := o.write(&buf); err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.path, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	if *docsDir != "" {
+		return spec.WriteDocs(*docsDir, *docsFormat)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package failuregen
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Formats of documents generated by Docs.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var markdownPage = template.Must(template.New("page").Parse(`# {{.Code}}
+
+{{.Description}}
+
+| | |
+|---|---|
+| Constant | ` + "`{{.Name}}`" + ` |
+| HTTP status | {{if .HTTPStatus}}{{.HTTPStatus}}{{else}}500{{end}} |
+| Retryable | {{if .Retryable}}yes{{else}}no{{end}} |
+{{- if .Owner}}
+| Owner | {{.Owner}} |
+{{- end}}
+{{- if .Causes}}
+
+## Typical causes
+{{range .Causes}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if .Runbook}}
+
+## Runbook
+
+{{.Runbook}}
+{{- end}}
+`))
+
+var markdownIndex = template.Must(template.New("index").Funcs(template.FuncMap{"docFile": docFile}).Parse(`# Error codes
+
+| Code | Description |
+|---|---|
+{{- range .}}
+| [{{.Code}}]({{docFile .Code "md"}}) | {{.Description}} |
+{{- end}}
+`))
+
+var htmlPage = htmltemplate.Must(htmltemplate.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Code}}</title></head>
+<body>
+<h1>{{.Code}}</h1>
+<p>{{.Description}}</p>
+<table>
+<tr><th>Constant</th><td><code>{{.Name}}</code></td></tr>
+<tr><th>HTTP status</th><td>{{if .HTTPStatus}}{{.HTTPStatus}}{{else}}500{{end}}</td></tr>
+<tr><th>Retryable</th><td>{{if .Retryable}}yes{{else}}no{{end}}</td></tr>
+{{- if .Owner}}
+<tr><th>Owner</th><td>{{.Owner}}</td></tr>
+{{- end}}
+</table>
+{{- if .Causes}}
+<h2>Typical causes</h2>
+<ul>
+{{- range .Causes}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Runbook}}
+<h2>Runbook</h2>
+<pre>{{.Runbook}}</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+var htmlIndex = htmltemplate.Must(htmltemplate.New("index").Funcs(htmltemplate.FuncMap{"docFile": docFile}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Error codes</title></head>
+<body>
+<h1>Error codes</h1>
+<table>
+<tr><th>Code</th><th>Description</th></tr>
+{{- range .}}
+<tr><td><a href="{{docFile .Code "html"}}">{{.Code}}</a></td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// docFile returns the file name of the page of code.
+func docFile(code, ext string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(code) + "." + ext
+}
+
+// Docs returns documents of the codes keyed by their file names.
+// A page per code, which is named after the code, and the index page
+// are generated, so that errors in logs can be looked up by their
+// codes.
+// format is FormatMarkdown or FormatHTML.
+func (s *Spec) Docs(format string) (map[string][]byte, error) {
+	type executor interface {
+		Execute(w io.Writer, data interface{}) error
+	}
+
+	var (
+		page, index executor
+		ext         string
+	)
+	switch format {
+	case FormatMarkdown:
+		page, index, ext = markdownPage, markdownIndex, "md"
+	case FormatHTML:
+		page, index, ext = htmlPage, htmlIndex, "html"
+	default:
+		return nil, fmt.Errorf("failuregen: unknown format %q", format)
+	}
+
+	docs := make(map[string][]byte)
+	for _, c := range s.Codes {
+		var buf bytes.Buffer
+		if err := page.Execute(&buf, c); err != nil {
+			return nil, err
+		}
+		docs[docFile(c.Code, ext)] = buf.Bytes()
+	}
+	var buf bytes.Buffer
+	if err := index.Execute(&buf, s.Codes); err != nil {
+		return nil, err
+	}
+	docs["index."+ext] = buf.Bytes()
+	return docs, nil
+}
+
+// WriteDocs writes the documents returned by Docs into dir.
+func (s *Spec) WriteDocs(dir, format string) error {
+	docs, err := s.Docs(format)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for name, b := range docs {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
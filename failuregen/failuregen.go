@@ -0,0 +1,223 @@
+// Package failuregen generates code of error codes from a spec file,
+// so that backends and frontends share the same error codes.
+//
+// The spec is written in YAML (or JSON) like below.
+//
+//	package: errcode
+//	codes:
+//	  - name: NotFound
+//	    code: not_found
+//	    description: The resource does not exist.
+//	    http_status: 404
+//	  - name: Unavailable
+//	    code: unavailable
+//	    description: The service is temporarily unavailable.
+//	    http_status: 503
+//	    retryable: true
+//	    causes:
+//	      - The database is under maintenance.
+//	    owner: platform-team
+//	    runbook: Check the status page of the database.
+//
+// Use the failure-gen command with go:generate.
+//
+//	//go:generate failure-gen -spec codes.yaml -go codes_gen.go -ts ../web/src/codes.ts
+//
+// It also generates the error responses of OpenAPI, which is the same
+// as failure.OpenAPIComponents for the registered codes.
+package failuregen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strconv"
+
+	"github.com/morikuni/failure"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the spec of error codes.
+type Spec struct {
+	// Package is the name of the generated Go package.
+	Package string `yaml:"package"`
+	// Codes are the error codes in the generated order.
+	Codes []CodeSpec `yaml:"codes"`
+}
+
+// CodeSpec is the spec of an error code.
+type CodeSpec struct {
+	// Name is the name of the Go constant.
+	Name string `yaml:"name"`
+	// Code is the value of the error code.
+	Code string `yaml:"code"`
+	// Description describes what the code means.
+	Description string `yaml:"description"`
+	// HTTPStatus is the HTTP status code for the code.
+	// It is not registered if zero.
+	HTTPStatus int `yaml:"http_status"`
+	// Retryable tells whether errors with the code can be retried.
+	Retryable bool `yaml:"retryable"`
+	// Causes are typical causes of errors with the code, which are
+	// used only for documentation.
+	Causes []string `yaml:"causes"`
+	// Owner is the team owning the code, which is used only for
+	// documentation.
+	Owner string `yaml:"owner"`
+	// Runbook is what to do for errors with the code in Markdown,
+	// which is used only for documentation.
+	Runbook string `yaml:"runbook"`
+}
+
+// LoadSpec reads and validates a spec.
+func LoadSpec(r io.Reader) (*Spec, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	var s Spec
+	if err := d.Decode(&s); err != nil {
+		return nil, fmt.Errorf("failuregen: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate reports the first problem of s.
+func (s *Spec) Validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("failuregen: invalid package name %q", s.Package)
+	}
+	names := make(map[string]bool)
+	codes := make(map[string]bool)
+	for _, c := range s.Codes {
+		switch {
+		case !token.IsIdentifier(c.Name):
+			return fmt.Errorf("failuregen: invalid name %q", c.Name)
+		case c.Code == "":
+			return fmt.Errorf("failuregen: empty code of %s", c.Name)
+		case names[c.Name]:
+			return fmt.Errorf("failuregen: duplicate name %q", c.Name)
+		case codes[c.Code]:
+			return fmt.Errorf("failuregen: duplicate code %q", c.Code)
+		}
+		names[c.Name] = true
+		codes[c.Code] = true
+	}
+	return nil
+}
+
+// WriteGo writes Go constants of the codes, which are registered by
+// failure.RegisterCode on initialization.
+func (s *Spec) WriteGo(w io.Writer) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by failure-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", s.Package)
+	fmt.Fprintf(&buf, "import \"github.com/morikuni/failure\"\n\n")
+	fmt.Fprintf(&buf, "// Error codes.\nconst (\n")
+	for _, c := range s.Codes {
+		if c.Description != "" {
+			fmt.Fprintf(&buf, "// %s means %s\n", c.Name, lowerFirst(c.Description))
+		}
+		fmt.Fprintf(&buf, "%s failure.StringCode = %s\n", c.Name, strconv.Quote(c.Code))
+	}
+	fmt.Fprintf(&buf, ")\n\n")
+	fmt.Fprintf(&buf, "func init() {\n")
+	for _, c := range s.Codes {
+		fmt.Fprintf(&buf, "failure.RegisterCode(%s, %s, %d, %t)\n", c.Name, strconv.Quote(c.Description), c.HTTPStatus, c.Retryable)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteTypeScript writes a TypeScript object of the codes and the
+// union type of them, both named ErrorCode.
+func (s *Spec) WriteTypeScript(w io.Writer) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by failure-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "export const ErrorCode = {\n")
+	for _, c := range s.Codes {
+		if c.Description != "" {
+			fmt.Fprintf(&buf, "  /** %s */\n", c.Description)
+		}
+		fmt.Fprintf(&buf, "  %s: %s,\n", c.Name, jsString(c.Code))
+	}
+	fmt.Fprintf(&buf, "} as const;\n\n")
+	fmt.Fprintf(&buf, "export type ErrorCode = (typeof ErrorCode)[keyof typeof ErrorCode];\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteOpenAPIEnum writes an OpenAPI schema of the codes in YAML to be
+// referred from components/schemas.
+func (s *Spec) WriteOpenAPIEnum(w io.Writer) error {
+	var (
+		enum  []string
+		names []string
+		descs []string
+	)
+	for _, c := range s.Codes {
+		enum = append(enum, c.Code)
+		names = append(names, c.Name)
+		descs = append(descs, c.Description)
+	}
+	schema := map[string]interface{}{
+		"ErrorCode": map[string]interface{}{
+			"type":                "string",
+			"enum":                enum,
+			"x-enum-varnames":     names,
+			"x-enum-descriptions": descs,
+		},
+	}
+
+	io.WriteString(w, "# Code generated by failure-gen. DO NOT EDIT.\n")
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(schema); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// WriteOpenAPIResponses writes the components section of OpenAPI in
+// YAML generated by failure.OpenAPIComponents for the codes.
+func (s *Spec) WriteOpenAPIResponses(w io.Writer) error {
+	infos := make([]failure.CodeInfo, len(s.Codes))
+	for i, c := range s.Codes {
+		infos[i] = failure.CodeInfo{
+			Code:        failure.StringCode(c.Code),
+			Description: c.Description,
+			HTTPStatus:  c.HTTPStatus,
+			Retryable:   c.Retryable,
+		}
+	}
+
+	io.WriteString(w, "# Code generated by failure-gen. DO NOT EDIT.\n")
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(map[string]interface{}{"components": failure.OpenAPIComponents(infos)}); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+func lowerFirst(s string) string {
+	if s == "" || s[0] < 'A' || s[0] > 'Z' {
+		return s
+	}
+	return string(s[0]+'a'-'A') + s[1:]
+}
+
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
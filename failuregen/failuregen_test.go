@@ -0,0 +1,177 @@
+package failuregen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure/failuregen"
+	"github.com/stretchr/testify/assert"
+)
+
+const spec = `
+package: errcode
+codes:
+  - name: NotFound
+    code: not_found
+    description: The resource does not exist.
+    http_status: 404
+  - name: Unavailable
+    code: unavailable
+    description: The service is temporarily unavailable.
+    http_status: 503
+    retryable: true
+    causes:
+      - The database is under maintenance.
+    owner: platform-team
+    runbook: Check the <status page>.
+`
+
+func loadSpec(t *testing.T) *failuregen.Spec {
+	s, err := failuregen.LoadSpec(strings.NewReader(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSpec_WriteGo(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, loadSpec(t).WriteGo(&buf))
+	assert.Equal(t, `// Code generated by failure-gen. DO NOT EDIT.
+
+package errcode
+
+import "github.com/morikuni/failure"
+
+// Error codes.
+const (
+	// NotFound means the resource does not exist.
+	NotFound failure.StringCode = "not_found"
+	// Unavailable means the service is temporarily unavailable.
+	Unavailable failure.StringCode = "unavailable"
+)
+
+func init() {
+	failure.RegisterCode(NotFound, "The resource does not exist.", 404, false)
+	failure.RegisterCode(Unavailable, "The service is temporarily unavailable.", 503, true)
+}
+`, buf.String())
+}
+
+func TestSpec_WriteTypeScript(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, loadSpec(t).WriteTypeScript(&buf))
+	assert.Equal(t, `// Code generated by failure-gen. DO NOT EDIT.
+
+export const ErrorCode = {
+  /** The resource does not exist. */
+  NotFound: "not_found",
+  /** The service is temporarily unavailable. */
+  Unavailable: "unavailable",
+} as const;
+
+export type ErrorCode = (typeof ErrorCode)[keyof typeof ErrorCode];
+`, buf.String())
+}
+
+func TestSpec_WriteOpenAPIEnum(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, loadSpec(t).WriteOpenAPIEnum(&buf))
+	assert.Equal(t, `# Code generated by failure-gen. DO NOT EDIT.
+ErrorCode:
+  enum:
+    - not_found
+    - unavailable
+  type: string
+  x-enum-descriptions:
+    - The resource does not exist.
+    - The service is temporarily unavailable.
+  x-enum-varnames:
+    - NotFound
+    - Unavailable
+`, buf.String())
+}
+
+func TestLoadSpec_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid yaml":    "package: [",
+		"unknown field":   "package: a\nfoo: 1",
+		"invalid package": "package: a-b",
+		"invalid name":    "package: a\ncodes: [{name: 1a, code: a}]",
+		"empty code":      "package: a\ncodes: [{name: A}]",
+		"duplicate name":  "package: a\ncodes: [{name: A, code: a}, {name: A, code: b}]",
+		"duplicate code":  "package: a\ncodes: [{name: A, code: a}, {name: B, code: a}]",
+	}
+
+	for title, input := range tests {
+		t.Run(title, func(t *testing.T) {
+			_, err := failuregen.LoadSpec(strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSpec_WriteOpenAPIResponses(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, loadSpec(t).WriteOpenAPIResponses(&buf))
+
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, "# Code generated by failure-gen. DO NOT EDIT.\ncomponents:\n"))
+	assert.Contains(t, got, "\n    NotFound:\n      content:\n        application/json:\n")
+	assert.Contains(t, got, "\n    ServiceUnavailable:\n")
+	assert.Contains(t, got, "Retry-After")
+	assert.Contains(t, got, "$ref: '#/components/schemas/Error'")
+}
+
+func TestSpec_Docs(t *testing.T) {
+	docs, err := loadSpec(t).Docs(failuregen.FormatMarkdown)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 3)
+	assert.Equal(t, `# not_found
+
+The resource does not exist.
+
+| | |
+|---|---|
+| Constant | `+"`NotFound`"+` |
+| HTTP status | 404 |
+| Retryable | no |
+`, string(docs["not_found.md"]))
+	assert.Equal(t, `# unavailable
+
+The service is temporarily unavailable.
+
+| | |
+|---|---|
+| Constant | `+"`Unavailable`"+` |
+| HTTP status | 503 |
+| Retryable | yes |
+| Owner | platform-team |
+
+## Typical causes
+
+- The database is under maintenance.
+
+## Runbook
+
+Check the <status page>.
+`, string(docs["unavailable.md"]))
+	assert.Equal(t, `# Error codes
+
+| Code | Description |
+|---|---|
+| [not_found](not_found.md) | The resource does not exist. |
+| [unavailable](unavailable.md) | The service is temporarily unavailable. |
+`, string(docs["index.md"]))
+
+	docs, err = loadSpec(t).Docs(failuregen.FormatHTML)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 3)
+	assert.Contains(t, string(docs["unavailable.html"]), "<li>The database is under maintenance.</li>")
+	assert.Contains(t, string(docs["unavailable.html"]), "<pre>Check the &lt;status page&gt;.</pre>")
+	assert.Contains(t, string(docs["index.html"]), `<a href="not_found.html">not_found</a>`)
+
+	_, err = loadSpec(t).Docs("pdf")
+	assert.Error(t, err)
+}
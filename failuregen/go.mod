@@ -0,0 +1,16 @@
+module github.com/morikuni/failure/failuregen
+
+go 1.21
+
+replace github.com/morikuni/failure => ../
+
+require (
+	github.com/morikuni/failure v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.2.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
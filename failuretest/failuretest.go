@@ -0,0 +1,206 @@
+// Package failuretest provides helpers to test errors of the failure
+// package without matching strings against their formatted output.
+package failuretest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+)
+
+var update = flag.Bool("failuretest.update", false, "update golden files of call stacks")
+
+// AssertCode asserts that err has the code.
+func AssertCode(t testing.TB, err error, code failure.Code) bool {
+	t.Helper()
+	if got := failure.CodeOf(err); got != code {
+		t.Errorf("code of %s: got %s, want %s", quote(err), codeString(got), codeString(code))
+		return false
+	}
+	return true
+}
+
+func quote(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return strconv.Quote(err.Error())
+}
+
+func codeString(c failure.Code) string {
+	if c == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%q", c.ErrorCode())
+}
+
+// AssertMessage asserts that err has the message.
+func AssertMessage(t testing.TB, err error, msg string) bool {
+	t.Helper()
+	if got := failure.MessageOf(err); got != msg {
+		t.Errorf("message of %s: got %q, want %q", quote(err), got, msg)
+		return false
+	}
+	return true
+}
+
+// AssertContextValue asserts that err has the value for key in its
+// debug information.
+func AssertContextValue(t testing.TB, err error, key string, want interface{}) bool {
+	t.Helper()
+	got, ok := failure.ValueOf(err, key)
+	if !ok {
+		t.Errorf("value of %q in %s: not found", key, quote(err))
+		return false
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("value of %q in %s: got %#v, want %#v", key, quote(err), got, want)
+		return false
+	}
+	return true
+}
+
+// NormalizeCallStack returns a text representation of cs which does
+// not depend on the machine or the position of code.
+// Each frame is printed as a function with its package and a file name
+// without line number, and frames of the runtime and testing packages
+// are omitted.
+//
+//	github.com/foo/bar.(*Service).Get (service.go)
+//	github.com/foo/bar.TestService (service_test.go)
+func NormalizeCallStack(cs failure.CallStack) string {
+	if cs == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, f := range cs.Frames() {
+		switch failure.PkgPathOf(f) {
+		case "runtime", "testing":
+			continue
+		}
+		fmt.Fprintf(&sb, "%s (%s)\n", failure.FuncFullOf(f), f.File())
+	}
+	return sb.String()
+}
+
+// AssertCallStackGolden asserts that the call stack of err matches the
+// golden file normalized by NormalizeCallStack.
+// Running tests with -failuretest.update writes the golden file.
+func AssertCallStackGolden(t testing.TB, err error, golden string) bool {
+	t.Helper()
+	got := []byte(NormalizeCallStack(failure.CallStackOf(err)))
+
+	if *update {
+		if e := os.MkdirAll(filepath.Dir(golden), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(golden, got, 0o644); e != nil {
+			t.Fatal(e)
+		}
+		return true
+	}
+
+	want, e := os.ReadFile(golden)
+	if e != nil {
+		t.Errorf("read golden file: %v", e)
+		return false
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("call stack of %s does not match %s:\ngot:\n%s\nwant:\n%s", quote(err), golden, got, want)
+		return false
+	}
+	return true
+}
+
+// Comparer returns a function comparing errors by failure.EqualErrors
+// with opts, which can be passed to cmp.Comparer of
+// github.com/google/go-cmp to compare structs having errors.
+//
+//	cmp.Diff(want, got, cmp.Comparer(failuretest.Comparer(failure.IgnoreCallStacks(), failure.IgnoreTimestamps())))
+func Comparer(opts ...failure.CompareOption) func(a, b error) bool {
+	return func(a, b error) bool {
+		return failure.EqualErrors(a, b, opts...)
+	}
+}
+
+// Option changes errors created by ErrorWithCode.
+type Option func(*fakeOptions)
+
+type fakeOptions struct {
+	message  string
+	debug    failure.Debug
+	frames   []failure.Frame
+	wrappers []failure.Wrapper
+}
+
+// Message sets the message of the error.
+// The default is "fake error".
+func Message(msg string) Option {
+	return func(o *fakeOptions) {
+		o.message = msg
+	}
+}
+
+// Context sets the debug information of the error.
+// The default is failure.Debug{"fake": true}.
+func Context(d failure.Debug) Option {
+	return func(o *fakeOptions) {
+		o.debug = d
+	}
+}
+
+// Frames sets the call stack of the error.
+// The default is DefaultFrames.
+func Frames(frames ...failure.Frame) Option {
+	return func(o *fakeOptions) {
+		o.frames = frames
+	}
+}
+
+// Wrappers adds wrappers applied to the error.
+func Wrappers(wrappers ...failure.Wrapper) Option {
+	return func(o *fakeOptions) {
+		o.wrappers = append(o.wrappers, wrappers...)
+	}
+}
+
+// DefaultFrames are the frames of errors created by ErrorWithCode.
+var DefaultFrames = failure.MustParseFrames(
+	"example.com/fake.Do /fake/fake.go:10",
+	"example.com/fake.main /fake/main.go:5",
+)
+
+// ErrorWithCode returns a fake error with the code having a message,
+// debug information and a call stack, which are the same every time,
+// so that tests of error handling do not depend on how errors are
+// created in production code.
+//
+//	repo.EXPECT().FindUser(id).Return(nil, failuretest.ErrorWithCode(NotFound))
+func ErrorWithCode(code failure.Code, opts ...Option) error {
+	o := fakeOptions{
+		message: "fake error",
+		debug:   failure.Debug{"fake": true},
+		frames:  DefaultFrames,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	wrappers := []failure.Wrapper{failure.WithStaticCallStack(o.frames...)}
+	if o.message != "" {
+		wrappers = append(wrappers, failure.Message(o.message))
+	}
+	if len(o.debug) != 0 {
+		wrappers = append(wrappers, o.debug)
+	}
+	return failure.New(code, append(wrappers, o.wrappers...)...)
+}
@@ -0,0 +1,111 @@
+package failuretest_test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/failuretest"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+const NotFound failure.StringCode = "not_found"
+
+func newError() error {
+	return failure.New(NotFound,
+		failure.Message("user not found"),
+		failure.Debug{"user_id": 42},
+	)
+}
+
+func TestAssertCode(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, failuretest.AssertCode(r, newError(), NotFound))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, failuretest.AssertCode(r, newError(), failure.StringCode("other")))
+	assert.False(t, failuretest.AssertCode(r, io.EOF, NotFound))
+	assert.Equal(t, []string{
+		`code of "newError: code(not_found)": got "not_found", want "other"`,
+		`code of "EOF": got <nil>, want "not_found"`,
+	}, r.errors)
+}
+
+func TestAssertMessage(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, failuretest.AssertMessage(r, newError(), "user not found"))
+	assert.False(t, failuretest.AssertMessage(r, newError(), "other"))
+	assert.Len(t, r.errors, 1)
+}
+
+func TestAssertContextValue(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, failuretest.AssertContextValue(r, newError(), "user_id", 42))
+	assert.False(t, failuretest.AssertContextValue(r, newError(), "user_id", "42"))
+	assert.False(t, failuretest.AssertContextValue(r, newError(), "missing", 42))
+	assert.Len(t, r.errors, 2)
+}
+
+func TestNormalizeCallStack(t *testing.T) {
+	want := `github.com/morikuni/failure/failuretest_test.newError (failuretest_test.go)
+github.com/morikuni/failure/failuretest_test.TestNormalizeCallStack (failuretest_test.go)
+`
+	assert.Equal(t, want, failuretest.NormalizeCallStack(failure.CallStackOf(newError())))
+	assert.Equal(t, "", failuretest.NormalizeCallStack(nil))
+}
+
+func TestAssertCallStackGolden(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, failuretest.AssertCallStackGolden(r, wrapError(), filepath.Join("testdata", "stack.golden")))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, failuretest.AssertCallStackGolden(r, newError(), filepath.Join("testdata", "stack.golden")))
+	assert.False(t, failuretest.AssertCallStackGolden(r, newError(), filepath.Join("testdata", "missing.golden")))
+	assert.Len(t, r.errors, 2)
+}
+
+func wrapError() error {
+	return failure.Wrap(newError())
+}
+
+func TestComparer(t *testing.T) {
+	eq := failuretest.Comparer(failure.IgnoreCallStacks(), failure.IgnoreTimestamps())
+	assert.True(t, eq(failure.Wrap(io.EOF), failure.Wrap(io.EOF)))
+	assert.False(t, eq(failure.Wrap(io.EOF), failure.Wrap(io.ErrUnexpectedEOF)))
+}
+
+func TestErrorWithCode(t *testing.T) {
+	code := failure.StringCode("not_found")
+
+	err := failuretest.ErrorWithCode(code)
+	assert.Equal(t, code, failure.CodeOf(err))
+	assert.Equal(t, "fake error", failure.MessageOf(err))
+	assert.Equal(t, map[string]interface{}{"fake": true}, failure.ContextOf(err))
+	assert.Equal(t, failuretest.DefaultFrames, failure.CallStackOf(err).Frames())
+	assert.True(t, failure.EqualErrors(err, failuretest.ErrorWithCode(code), failure.IgnoreTimestamps()))
+
+	frames := failure.MustParseFrames("main.f /main.go:1")
+	err = failuretest.ErrorWithCode(code,
+		failuretest.Message("custom"),
+		failuretest.Context(failure.Debug{"id": 1}),
+		failuretest.Frames(frames...),
+		failuretest.Wrappers(failure.MarkRetryable()),
+	)
+	assert.Equal(t, "custom", failure.MessageOf(err))
+	assert.Equal(t, map[string]interface{}{"id": 1}, failure.ContextOf(err))
+	assert.Equal(t, frames, failure.CallStackOf(err).Frames())
+	assert.True(t, failure.IsRetryable(err))
+}
@@ -0,0 +1,40 @@
+package failure
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+)
+
+// Fingerprint returns a stable key for grouping the same errors.
+// It is a hash of the error code and the innermost frame of err.
+// The frame is identified by its package, function and line
+// instead of the file path, so the key does not change between
+// machines building the same source.
+// If err has no call stack, the type of the cause is used instead.
+// It returns an empty string if err is nil.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	if c := CodeOf(err); c != nil {
+		io.WriteString(h, c.ErrorCode())
+	}
+	h.Write([]byte{0})
+
+	if cs := CallStackOf(err); cs != nil {
+		f := cs.HeadFrame()
+		io.WriteString(h, PkgPathOf(f))
+		h.Write([]byte{0})
+		io.WriteString(h, f.Func())
+		h.Write([]byte{0})
+		io.WriteString(h, strconv.Itoa(f.Line()))
+	} else {
+		fmt.Fprintf(h, "%T", CauseOf(err))
+	}
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,43 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFingerprintError(code failure.Code) error {
+	return failure.New(code)
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := failure.Fingerprint(newFingerprintError(TestCodeA))
+	assert.NotEmpty(t, fp)
+
+	var same []string
+	for i := 0; i < 2; i++ {
+		same = append(same, failure.Fingerprint(newFingerprintError(TestCodeA)))
+	}
+	assert.Equal(t, fp, same[0])
+	assert.Equal(t, fp, same[1])
+
+	assert.Equal(t, fp, failure.Fingerprint(failure.Wrap(newFingerprintError(TestCodeA))), "wrapping should not change the fingerprint")
+	assert.NotEqual(t, fp, failure.Fingerprint(newFingerprintError(TestCodeB)), "code should change the fingerprint")
+	assert.NotEqual(t, fp, failure.Fingerprint(failure.New(TestCodeA)), "place should change the fingerprint")
+
+	assert.Equal(t, failure.Fingerprint(io.EOF), failure.Fingerprint(io.ErrUnexpectedEOF))
+	assert.NotEqual(t, failure.Fingerprint(io.EOF), failure.Fingerprint(&customError{}))
+	assert.Equal(t, "", failure.Fingerprint(nil))
+}
+
+func TestFingerprint_PathIndependent(t *testing.T) {
+	defer failure.SetPathTrimmer(nil)
+
+	err := newFingerprintError(TestCodeA)
+	fp := failure.Fingerprint(err)
+
+	failure.SetPathTrimmer(failure.TrimGoPaths())
+	assert.Equal(t, fp, failure.Fingerprint(err))
+}
@@ -0,0 +1,179 @@
+package failure
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Formatter renders errors created by this package for the verbs %s,
+// %v, %+v and %#v.
+// err is the error wrapped by the formatter layer, so it can be
+// inspected by CodeOf, MessageOf and so on, but it must not be
+// formatted by fmt with the same verb, which may recurse.
+type Formatter interface {
+	FormatError(s fmt.State, verb rune, err error)
+}
+
+// FormatterFunc is an adapter to allow the use of ordinary functions
+// as Formatter.
+type FormatterFunc func(s fmt.State, verb rune, err error)
+
+// FormatError implements the Formatter interface.
+func (f FormatterFunc) FormatError(s fmt.State, verb rune, err error) {
+	f(s, verb, err)
+}
+
+type formatterHolder struct {
+	formatter Formatter
+}
+
+var globalFormatter atomic.Value // formatterHolder
+
+// SetFormatter sets the Formatter used by errors created by this
+// package.
+// Passing nil restores DefaultFormat, which is the default.
+// It is safe to call SetFormatter concurrently.
+//
+//	failure.SetFormatter(failure.FormatterFunc(func(s fmt.State, verb rune, err error) {
+//		if verb == 'v' && s.Flag('+') {
+//			fmt.Fprintf(s, "%s: %s (%s)", failure.CodeOf(err).ErrorCode(), failure.MessageOf(err), failure.CallStackOf(err).HeadFrame())
+//			return
+//		}
+//		failure.DefaultFormat(s, verb, err)
+//	}))
+func SetFormatter(f Formatter) {
+	globalFormatter.Store(formatterHolder{f})
+}
+
+func formatterOf() Formatter {
+	h, _ := globalFormatter.Load().(formatterHolder)
+	if h.formatter == nil {
+		return FormatterFunc(DefaultFormat)
+	}
+	return h.formatter
+}
+
+// DefaultFormat is the default Formatter.
+// %s and %v print err.Error(), and %+v prints the layers of err from
+// the outermost followed by the call stacks.
+func DefaultFormat(s fmt.State, verb rune, err error) {
+	if verb != 'v' { // %s
+		io.WriteString(s, err.Error())
+		return
+	}
+
+	if s.Flag('#') { // %#v
+		writeGoSyntax(s, err)
+		return
+	}
+
+	if !s.Flag('+') { // %v
+		io.WriteString(s, err.Error())
+		return
+	}
+
+	// %+v
+	writeDetail(s, err, palette{})
+}
+
+// writeGoSyntax writes the layers of err from the outermost in the
+// format of %#v like
+//
+//	failure.layers{"[f] /main.go:10", "debug(password=[REDACTED])", "code(not_found)"}
+//
+// Raw structures are not written because they have unredacted debug
+// information.
+func writeGoSyntax(w io.Writer, err error) {
+	type layers []string
+
+	var ls layers
+	i := NewIterator(err)
+	for i.Next() {
+		switch i.Error().(type) {
+		case formatter, withCollector:
+			continue
+		}
+		ls = append(ls, treeLabel(i.Error()))
+	}
+	fmt.Fprintf(w, "%#v", ls)
+}
+
+// detailer is implemented by the layers of this package.
+// Formatting functions switch on it instead of each wrapper, so that a
+// new wrapper is not printed as an unknown error by accident.
+type detailer interface {
+	// detail returns the description of the layer like "code(x)"
+	// using the colors of p, or an empty string if the layer should
+	// not be printed.
+	detail(p palette) string
+}
+
+// writeDetail writes err in the format of %+v with colors of p.
+func writeDetail(w io.Writer, err error, p palette) {
+	type callStacker interface {
+		GetCallStack() CallStack
+	}
+	type debugger interface {
+		GetDebug() Debug
+	}
+	type messenger interface {
+		GetMessage() string
+	}
+	type coder interface {
+		GetCode() Code
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		e := i.Error()
+		switch t := e.(type) {
+		case callStacker:
+			fmt.Fprintf(w, "%s\n", p.frame(t.GetCallStack().HeadFrame()))
+		case debugger:
+			debug := t.GetDebug()
+			for k, v := range debug {
+				fmt.Fprintf(w, "    %s = %v\n", k, v)
+			}
+		case detailer:
+			if d := t.detail(p); d != "" {
+				fmt.Fprintf(w, "    %s\n", d)
+			}
+		case messenger:
+			fmt.Fprintf(w, "    %s\n", p.paint(p.message, fmt.Sprintf("message(%q)", t.GetMessage())))
+		case coder:
+			fmt.Fprintf(w, "    %s\n", p.paint(p.message, fmt.Sprintf("code(%s)", codeString(t.GetCode()))))
+		default:
+			fmt.Fprintf(w, "    %s\n", p.paint(p.message, fmt.Sprintf("error(%q)", e.Error())))
+		}
+	}
+	if i.Truncated() {
+		io.WriteString(w, "    ... (truncated)\n")
+	}
+
+	if callStackModeOf() == CallStackModeDelta {
+		writeDeltaCallStacks(w, CallStacksOf(err), p)
+	} else {
+		fmt.Fprint(w, "[CallStack]\n")
+		if cs := CallStackOf(err); cs != nil {
+			for _, f := range cs.Frames() {
+				fmt.Fprintf(w, "    %s\n", p.frame(f))
+			}
+		}
+	}
+
+	pcss := ParentCallStacksOf(err)
+	for i := len(pcss) - 1; i >= 0; i-- {
+		fmt.Fprint(w, "[ParentCallStack]\n")
+		for _, f := range pcss[i].Frames() {
+			fmt.Fprintf(w, "    %s\n", p.frame(f))
+		}
+	}
+
+	for _, g := range GoroutinesOf(err) {
+		fmt.Fprintf(w, "[Goroutine %d] %s\n", g.ID, g.State)
+		for _, f := range g.CallStack.Frames() {
+			fmt.Fprintf(w, "    %s\n", p.frame(f))
+		}
+	}
+}
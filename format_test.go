@@ -0,0 +1,34 @@
+package failure_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFormatter(t *testing.T) {
+	defer failure.SetFormatter(nil)
+
+	err := failure.New(failure.StringCode("not_found"),
+		failure.Message("user not found"),
+		failure.WithStaticCallStack(failure.NewFrame("main.f", "/src/main.go", 12)),
+	)
+	want := fmt.Sprintf("%+v", err)
+
+	failure.SetFormatter(failure.FormatterFunc(func(s fmt.State, verb rune, err error) {
+		if verb == 'v' && s.Flag('+') {
+			fmt.Fprintf(s, "%s: %s (%s)", failure.CodeOf(err).ErrorCode(), failure.MessageOf(err), failure.CallStackOf(err).HeadFrame())
+			return
+		}
+		failure.DefaultFormat(s, verb, err)
+	}))
+
+	assert.Equal(t, "not_found: user not found (/src/main.go:12)", fmt.Sprintf("%+v", err))
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, err.Error(), fmt.Sprintf("%s", err))
+
+	failure.SetFormatter(nil)
+	assert.Equal(t, want, fmt.Sprintf("%+v", err))
+}
@@ -0,0 +1,118 @@
+package failure
+
+import (
+	"container/list"
+	"runtime"
+	"sync"
+)
+
+// frameCacheSize is the maximum number of program counters whose
+// frames are cached.
+const frameCacheSize = 4096
+
+// frameCache is an LRU cache of frames resolved from program counters.
+// Errors tend to be created at the same places repeatedly, so the
+// cache saves the symbolization of runtime.CallersFrames for them.
+type frameCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[uintptr]*list.Element
+}
+
+type frameCacheEntry struct {
+	pc     uintptr
+	frames []Frame
+}
+
+func newFrameCache(size int) *frameCache {
+	return &frameCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[uintptr]*list.Element),
+	}
+}
+
+func (c *frameCache) get(pc uintptr) ([]Frame, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[pc]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*frameCacheEntry).frames, true
+}
+
+func (c *frameCache) add(pc uintptr, fs []Frame) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[pc]; ok {
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[pc] = c.ll.PushFront(&frameCacheEntry{pc, fs})
+	if c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*frameCacheEntry).pc)
+	}
+}
+
+// purge removes all the cached frames.
+func (c *frameCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[uintptr]*list.Element)
+}
+
+var frames = newFrameCache(frameCacheSize)
+
+// resolveFrames resolves pcs into frames using the frame cache.
+// A program counter may be resolved into multiple frames when
+// functions are inlined.
+func resolveFrames(pcs []uintptr) []Frame {
+	fs := make([]Frame, 0, len(pcs))
+	for i, pc := range pcs {
+		if cached, ok := frames.get(pc); ok {
+			fs = append(fs, cached...)
+			continue
+		}
+
+		var pfs []Frame
+		sigpanic := false
+		rfs := runtime.CallersFrames(pcs[i : i+1])
+		for {
+			f, more := rfs.Next()
+			pfs = append(pfs, newResolvedFrame(f))
+			sigpanic = sigpanic || f.Function == "runtime.sigpanic"
+			if !more {
+				break
+			}
+		}
+		frames.add(pc, pfs)
+		fs = append(fs, pfs...)
+
+		if sigpanic {
+			// The program counter following runtime.sigpanic is not a
+			// return address, so it can be resolved only with the
+			// preceding ones.
+			rfs := runtime.CallersFrames(pcs[i:])
+			for n := 0; ; n++ {
+				f, more := rfs.Next()
+				if n >= len(pfs) {
+					fs = append(fs, newResolvedFrame(f))
+				}
+				if !more {
+					break
+				}
+			}
+			break
+		}
+	}
+	return fs
+}
@@ -0,0 +1,65 @@
+package failure_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func runtimeFrames(pcs []uintptr) []runtime.Frame {
+	var fs []runtime.Frame
+	rfs := runtime.CallersFrames(pcs)
+	for {
+		f, more := rfs.Next()
+		fs = append(fs, f)
+		if !more {
+			break
+		}
+	}
+	return fs
+}
+
+func assertSameFrames(t *testing.T, pcs []uintptr) {
+	t.Helper()
+
+	want := runtimeFrames(pcs)
+	for i := 0; i < 2; i++ {
+		fs := failure.NewCallStack(pcs).Frames()
+		got := make([]runtime.Frame, len(fs))
+		for i, f := range fs {
+			got[i] = failure.RuntimeFrameOf(f)
+		}
+		assert.Equal(t, len(want), len(got))
+		for i := range want {
+			assert.Equal(t, want[i].Function, got[i].Function)
+			assert.Equal(t, want[i].File, got[i].File)
+			assert.Equal(t, want[i].Line, got[i].Line)
+		}
+	}
+}
+
+func TestCallStack_Frames_FrameCache(t *testing.T) {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	assertSameFrames(t, pcs)
+
+	func() {
+		defer func() {
+			recover()
+			pcs := make([]uintptr, 32)
+			pcs = pcs[:runtime.Callers(1, pcs)]
+			assertSameFrames(t, pcs)
+		}()
+		nilDeref()
+	}()
+}
+
+func BenchmarkCallStack_Frames_NoFrameCache(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pcs := make([]uintptr, 32)
+		runtimeFrames(pcs[:runtime.Callers(1, pcs)])
+	}
+}
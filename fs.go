@@ -0,0 +1,52 @@
+package failure
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// Error codes used by FromFSError.
+const (
+	NotExistCode   StringCode = "failure.not_exist"
+	ExistCode      StringCode = "failure.exist"
+	PermissionCode StringCode = "failure.permission"
+)
+
+// FilePathKey is the key of the path appended by FromFSError.
+// Call MarkSensitive(FilePathKey) to redact paths in outputs.
+const FilePathKey = "file_path"
+
+// FromFSError translates err caused by fs.ErrNotExist, fs.ErrExist or
+// fs.ErrPermission into an error with NotExistCode, ExistCode or
+// PermissionCode. Other errors are returned as they are.
+//
+// The operation and the path of *fs.PathError are appended as debug
+// information keyed by "op" and FilePathKey.
+//
+//	f, err := os.Open(name)
+//	if err != nil {
+//		return failure.FromFSError(err)
+//	}
+func FromFSError(err error) error {
+	var code Code
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = NotExistCode
+	case errors.Is(err, fs.ErrExist):
+		code = ExistCode
+	case errors.Is(err, fs.ErrPermission):
+		code = PermissionCode
+	default:
+		return err
+	}
+
+	wrappers := []Wrapper{WithCallStackSkip(1)}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		wrappers = append(wrappers, Debug{
+			"op":        pathErr.Op,
+			FilePathKey: pathErr.Path,
+		})
+	}
+	return Translate(err, code, wrappers...)
+}
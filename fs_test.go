@@ -0,0 +1,37 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromFSError(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "missing")
+	_, err := os.Open(name)
+	err = failure.FromFSError(err)
+	assert.Equal(t, failure.NotExistCode, failure.CodeOf(err))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.Equal(t, "TestFromFSError", failure.CallStackOf(err).HeadFrame().Func())
+	op, _ := failure.ValueOf(err, "op")
+	assert.Equal(t, "open", op)
+	path, _ := failure.ValueOf(err, failure.FilePathKey)
+	assert.Equal(t, name, path)
+
+	err = failure.FromFSError(fmt.Errorf("mkdir: %w", fs.ErrExist))
+	assert.Equal(t, failure.ExistCode, failure.CodeOf(err))
+	assert.Empty(t, failure.DebugsOf(err))
+
+	err = failure.FromFSError(&fs.PathError{Op: "open", Path: "/secret", Err: fs.ErrPermission})
+	assert.Equal(t, failure.PermissionCode, failure.CodeOf(err))
+
+	assert.Equal(t, io.EOF, failure.FromFSError(io.EOF))
+	assert.Nil(t, failure.FromFSError(nil))
+}
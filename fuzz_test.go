@@ -0,0 +1,84 @@
+package failure_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+)
+
+func FuzzParseStack(f *testing.F) {
+	f.Add("goroutine 1 [running]:\nmain.f(0x1)\n\t/src/main.go:10 +0x1d\n")
+	f.Add("[CallStack]\n    [f] /src/main.go:10\n")
+	f.Add("/src/main.go:10:1: f\n")
+	f.Add("[] :\n")
+	f.Fuzz(func(t *testing.T, text string) {
+		cs := failure.ParseStack(text)
+		if cs == nil {
+			return
+		}
+		_ = fmt.Sprintf("%v %+v %#v", cs, cs, cs)
+		_ = cs.HeadFrame()
+		_ = failure.ShortCallStack(cs, 0)
+		_ = failure.EncodeCallStack(cs)
+		for _, fr := range cs.Frames() {
+			_ = fmt.Sprintf("%v %+v", fr, fr)
+			_ = fr.Pkg()
+			_ = fr.Func()
+		}
+	})
+}
+
+func FuzzUnmarshalError(f *testing.F) {
+	b, err := failure.MarshalError(failure.Translate(
+		failure.Wrap(fmt.Errorf("raw"), failure.Message("msg"), failure.Debug{"k": 1}),
+		failure.IntCode(1),
+		failure.MarkRetryable(),
+		failure.WithSeverity(failure.SeverityWarn),
+	))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte(`{"layers":[{"kind":"call_stack","call_stack":[]}]}`))
+	f.Add([]byte(`{"layers":[{"kind":"code","code":""}`))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		err, e := failure.UnmarshalError(b)
+		if e != nil {
+			return
+		}
+		_ = fmt.Sprintf("%s %v %+v %#v", err, err, err, err)
+		_ = failure.Trail(err)
+		_ = failure.Tree(err)
+		if _, e := failure.MarshalError(err); e != nil {
+			t.Fatalf("marshal unmarshaled error: %v", e)
+		}
+	})
+}
+
+func FuzzFormat(f *testing.F) {
+	f.Add("code", "message", "key", "main.f /main.go:1")
+	f.Add("", "", "", "")
+	f.Fuzz(func(t *testing.T, code, msg, key, frame string) {
+		var c failure.Code
+		if code != "" {
+			c = failure.StringCode(code)
+		}
+		var frames []failure.Frame
+		if fr, err := failure.ParseFrame(frame); err == nil {
+			frames = append(frames, fr)
+		}
+		err := failure.New(c,
+			failure.Message(msg),
+			failure.Debug{key: msg},
+			failure.WithStaticCallStack(frames...),
+		)
+		_ = fmt.Sprintf("%s %v %+v %#v", err, err, err, err)
+		_ = failure.Trail(err)
+		_ = failure.Tree(err)
+		_ = failure.Validate(err)
+		if _, e := failure.MarshalError(err); e != nil {
+			t.Fatalf("marshal: %v", e)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+// Package ginutil provides a middleware of github.com/gin-gonic/gin
+// handling errors of the failure package.
+//
+//	r := gin.New()
+//	r.Use(ginutil.Middleware(httpserver.Config{}))
+//	r.GET("/users/:id", func(c *gin.Context) {
+//		if err := getUser(c); err != nil {
+//			c.Error(err)
+//			return
+//		}
+//	})
+package ginutil
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/httpserver"
+)
+
+// Middleware returns a middleware which recovers panics and handles
+// the last error added by gin.Context.Error with cfg.
+// Errors are not handled if the response has already been written.
+func Middleware(cfg httpserver.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := serve(c)
+		if err == nil {
+			if len(c.Errors) == 0 || c.Writer.Written() {
+				return
+			}
+			err = c.Errors.Last().Err
+		}
+		c.Abort()
+		cfg.HandleError(c.Writer, c.Request, err)
+	}
+}
+
+func serve(c *gin.Context) (err error) {
+	defer failure.Recover(&err, failure.PanicCode)
+	c.Next()
+	return nil
+}
@@ -0,0 +1,75 @@
+package ginutil_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/ginutil"
+	"github.com/morikuni/failure/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "ginutil.not_found"
+
+func init() {
+	failure.RegisterHTTPStatus(NotFound, http.StatusNotFound)
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware(t *testing.T) {
+	var logged error
+	r := gin.New()
+	r.Use(ginutil.Middleware(httpserver.Config{
+		Log: func(r *http.Request, err error) {
+			logged = err
+		},
+	}))
+	r.GET("/error", func(c *gin.Context) {
+		c.Error(failure.New(NotFound))
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	r.GET("/written", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+		c.Error(failure.New(NotFound))
+	})
+
+	tests := map[string]struct {
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		"error":   {"/error", http.StatusNotFound, "ginutil.not_found"},
+		"panic":   {"/panic", http.StatusInternalServerError, "failure.panic"},
+		"written": {"/written", http.StatusAccepted, ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			logged = nil
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			req.Header.Set("X-Request-Id", "req-1")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			if test.wantCode == "" {
+				assert.Nil(t, logged)
+				return
+			}
+			var res struct {
+				Code string `json:"code"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+			assert.Equal(t, test.wantCode, res.Code)
+			v, _ := failure.ValueOf(logged, httpserver.RequestIDKey)
+			assert.Equal(t, "req-1", v)
+		})
+	}
+}
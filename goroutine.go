@@ -0,0 +1,112 @@
+package failure
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Goroutine represents a goroutine in a dump of all goroutines.
+type Goroutine struct {
+	// ID is the goroutine ID.
+	ID int
+	// State is the state of the goroutine like "running" or
+	// "chan receive, 2 minutes".
+	State string
+	// CallStack is the call stack of the goroutine.
+	CallStack CallStack
+}
+
+// WithAllGoroutines appends call stacks of all goroutines to an error.
+// It stops the world while dumping goroutines, so it should be used
+// only for fatal errors like deadlocks.
+// The goroutines are printed by %+v.
+func WithAllGoroutines() Wrapper {
+	return WrapperFunc(func(err error) error {
+		return &withGoroutines{err, parseGoroutines(allGoroutines())}
+	})
+}
+
+func allGoroutines() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// parseGoroutines parses the output of runtime.Stack with all
+// goroutines. Goroutines are separated by empty lines.
+func parseGoroutines(b []byte) []Goroutine {
+	var gs []Goroutine
+	for _, block := range bytes.Split(b, []byte("\n\n")) {
+		header, _, _ := strings.Cut(string(block), "\n")
+		// goroutine 1 [running]:
+		header, ok := strings.CutPrefix(header, "goroutine ")
+		if !ok {
+			continue
+		}
+		id, state, ok := strings.Cut(header, " [")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		gs = append(gs, Goroutine{
+			ID:        n,
+			State:     strings.TrimSuffix(state, "]:"),
+			CallStack: newCallStackFromFrames(parseDebugStack(block)),
+		})
+	}
+	return gs
+}
+
+// withGoroutines is used as a pointer to be comparable like withDebug.
+type withGoroutines struct {
+	error
+	goroutines []Goroutine
+}
+
+func (w withGoroutines) UnwrapError() error {
+	return w.error
+}
+
+func (w withGoroutines) Unwrap() error {
+	return w.error
+}
+
+func (w withGoroutines) GetGoroutines() []Goroutine {
+	return w.goroutines
+}
+
+func (w withGoroutines) detail(p palette) string {
+	return fmt.Sprintf("goroutines(%d)", len(w.goroutines))
+}
+
+// GoroutinesOf extracts goroutines appended by WithAllGoroutines from
+// err. The outermost dump is returned.
+func GoroutinesOf(err error) []Goroutine {
+	if err == nil {
+		return nil
+	}
+
+	type goroutinesGetter interface {
+		GetGoroutines() []Goroutine
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(goroutinesGetter); ok {
+			return g.GetGoroutines()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func blockedGoroutine(started chan<- struct{}, done <-chan struct{}) {
+	close(started)
+	<-done
+}
+
+func TestWithAllGoroutines(t *testing.T) {
+	started, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go blockedGoroutine(started, done)
+	<-started
+
+	err := failure.Wrap(io.EOF, failure.WithAllGoroutines())
+
+	gs := failure.GoroutinesOf(err)
+	if !assert.True(t, len(gs) >= 2) {
+		return
+	}
+	assert.Equal(t, "running", gs[0].State)
+	var funcs []string
+	for _, f := range gs[0].CallStack.Frames() {
+		funcs = append(funcs, f.Func())
+	}
+	assert.Contains(t, funcs, "TestWithAllGoroutines")
+
+	var blocked *failure.Goroutine
+	for i, g := range gs {
+		for _, f := range g.CallStack.Frames() {
+			if f.Func() == "blockedGoroutine" {
+				blocked = &gs[i]
+			}
+		}
+	}
+	if assert.NotNil(t, blocked) {
+		assert.True(t, strings.HasPrefix(blocked.State, "chan receive"), blocked.State)
+		assert.NotZero(t, blocked.ID)
+	}
+
+	out := fmt.Sprintf("%+v", err)
+	assert.Contains(t, out, fmt.Sprintf("    goroutines(%d)\n", len(gs)))
+	assert.Contains(t, out, fmt.Sprintf("[Goroutine %d] %s\n", blocked.ID, blocked.State))
+	assert.Contains(t, out, "[blockedGoroutine] ")
+
+	assert.Nil(t, failure.GoroutinesOf(io.EOF))
+	assert.Nil(t, failure.GoroutinesOf(nil))
+}
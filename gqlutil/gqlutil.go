@@ -0,0 +1,96 @@
+// Package gqlutil converts errors of the failure package into GraphQL
+// errors of github.com/vektah/gqlparser, which are used by gqlgen.
+//
+//	srv.SetErrorPresenter(func(ctx context.Context, err error) *gqlerror.Error {
+//		gerr := gqlutil.ToError(err)
+//		gerr.Path = graphql.GetPath(ctx)
+//		return gerr
+//	})
+package gqlutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/morikuni/failure"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Keys of the extensions.
+const (
+	CodeKey      = "code"
+	RetryableKey = "retryable"
+	ContextKey   = "context"
+	StackKey     = "stack"
+)
+
+// DebugEnv is the environment variable enabling Encoder.Debug of
+// ToError when it is "true".
+const DebugEnv = "FAILURE_GQL_DEBUG"
+
+// InternalMessage is the message of errors without a public message.
+const InternalMessage = "internal error"
+
+// Encoder converts errors into GraphQL errors.
+type Encoder struct {
+	// Debug makes errors include the call stacks.
+	// It should be enabled only in development.
+	Debug bool
+}
+
+// Encode converts err into a GraphQL error having the code, the
+// retryability and the debug information as extensions.
+// The message is the one set by failure.WithPublicMessage, or
+// InternalMessage if err has no public message. Messages of other
+// layers and Error() are never used, as they may contain internal
+// details.
+// *gqlerror.Error without an error code, like validation errors, is
+// returned as it is.
+func (e Encoder) Encode(err error) *gqlerror.Error {
+	if err == nil {
+		return nil
+	}
+
+	var gerr *gqlerror.Error
+	if errors.As(err, &gerr) && failure.CodeOf(err) == nil {
+		return gerr
+	}
+
+	ext := map[string]interface{}{
+		RetryableKey: failure.IsRetryable(err),
+	}
+	if c := failure.CodeOf(err); c != nil {
+		ext[CodeKey] = c.ErrorCode()
+	}
+	if ctx := failure.ContextOf(err); ctx != nil {
+		ext[ContextKey] = ctx
+	}
+
+	msg := failure.PublicMessageOf(err)
+	if msg == "" {
+		msg = InternalMessage
+	}
+	if e.Debug {
+		if cs := failure.CallStackOf(err); cs != nil {
+			var stack []string
+			for _, f := range cs.Frames() {
+				stack = append(stack, fmt.Sprintf("%+v", f))
+			}
+			ext[StackKey] = stack
+		}
+	}
+
+	return &gqlerror.Error{
+		Err:        err,
+		Message:    msg,
+		Extensions: ext,
+	}
+}
+
+// ToError converts err into a GraphQL error by Encoder.
+// Encoder.Debug is enabled if the environment variable DebugEnv is
+// "true".
+func ToError(err error) *gqlerror.Error {
+	return Encoder{Debug: os.Getenv(DebugEnv) == "true"}.Encode(err)
+}
@@ -0,0 +1,56 @@
+package gqlutil_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/gqlutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Debug{"id": 1},
+		failure.MarkRetryable(),
+	)
+
+	gerr := gqlutil.Encoder{}.Encode(err)
+	assert.Equal(t, gqlutil.InternalMessage, gerr.Message)
+	assert.Equal(t, err, gerr.Err)
+	assert.Equal(t, map[string]interface{}{
+		"code":      "not_found",
+		"retryable": true,
+		"context":   map[string]interface{}{"id": 1},
+	}, gerr.Extensions)
+
+	gerr = gqlutil.Encoder{Debug: true}.Encode(err)
+	assert.Equal(t, gqlutil.InternalMessage, gerr.Message)
+	assert.NotContains(t, gerr.Extensions, "error")
+	if stack, ok := gerr.Extensions["stack"].([]string); assert.True(t, ok) {
+		assert.Contains(t, stack[0], "TestEncoder_Encode")
+	}
+
+	gerr = gqlutil.Encoder{}.Encode(failure.New(failure.StringCode("forbidden"), failure.Message("not allowed")))
+	assert.Equal(t, gqlutil.InternalMessage, gerr.Message)
+
+	gerr = gqlutil.Encoder{}.Encode(failure.New(failure.StringCode("forbidden"), failure.Message("not allowed"), failure.WithPublicMessage("forbidden")))
+	assert.Equal(t, "forbidden", gerr.Message)
+	assert.Equal(t, false, gerr.Extensions["retryable"])
+	assert.NotContains(t, gerr.Extensions, "context")
+
+	validation := gqlerror.Errorf("invalid query")
+	assert.Equal(t, validation, gqlutil.Encoder{}.Encode(validation))
+	assert.Nil(t, gqlutil.Encoder{}.Encode(nil))
+}
+
+func TestToError(t *testing.T) {
+	err := failure.New(failure.StringCode("not_found"))
+
+	t.Setenv(gqlutil.DebugEnv, "")
+	assert.NotContains(t, gqlutil.ToError(err).Extensions, "stack")
+
+	t.Setenv(gqlutil.DebugEnv, "true")
+	assert.Contains(t, gqlutil.ToError(err).Extensions, "stack")
+}
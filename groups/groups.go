@@ -0,0 +1,110 @@
+// Package groups provides a Group like golang.org/x/sync/errgroup
+// which keeps all the errors of its goroutines.
+//
+//	g, ctx := groups.WithContext(ctx)
+//	for _, id := range ids {
+//		g.Go(func() error {
+//			return fetch(ctx, id)
+//		})
+//	}
+//	if err := g.Wait(); err != nil {
+//		return err
+//	}
+package groups
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/morikuni/failure"
+)
+
+// Group is a collection of goroutines working on subtasks of the same
+// task.
+// The zero value is ready to use, and does not cancel on error.
+type Group struct {
+	cancel func(error)
+
+	wg sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []indexedError
+	n    int
+}
+
+type indexedError struct {
+	index int
+	err   error
+}
+
+// WithContext returns a new Group and an associated context derived
+// from ctx.
+// The context is canceled when a goroutine returns an error for the
+// first time or Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go calls f in a new goroutine.
+// The error returned by f is appended the call stack of the caller of
+// Go by failure.WithParentStack, so that %+v shows where the failed
+// goroutine was spawned.
+func (g *Group) Go(f func() error) {
+	parent := failure.Callers(1)
+
+	g.mu.Lock()
+	index := g.n
+	g.n++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		err := f()
+		if err == nil {
+			return
+		}
+		err = failure.Custom(err, failure.WithParentStack(parent), failure.WithFormatter())
+
+		g.mu.Lock()
+		first := len(g.errs) == 0
+		g.errs = append(g.errs, indexedError{index, err})
+		g.mu.Unlock()
+
+		if first && g.cancel != nil {
+			g.cancel(err)
+		}
+	}()
+}
+
+// Wait blocks until all the goroutines return, and returns their errors
+// joined by failure.WrapMultiple in the order of calling Go.
+// The first of them is the primary error for failure.CodeOf and other
+// accessors.
+// It returns nil if no goroutine returns an error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	g.mu.Lock()
+	ies := make([]indexedError, len(g.errs))
+	copy(ies, g.errs)
+	g.mu.Unlock()
+
+	if len(ies) == 0 {
+		return nil
+	}
+	sort.Slice(ies, func(i, j int) bool {
+		return ies[i].index < ies[j].index
+	})
+	errs := make([]error, len(ies))
+	for i, ie := range ies {
+		errs[i] = ie.err
+	}
+	return failure.WrapMultiple(errs, failure.WithCallStackSkip(1))
+}
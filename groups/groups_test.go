@@ -0,0 +1,64 @@
+package groups_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/groups"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	CodeA failure.StringCode = "code_a"
+	CodeB failure.StringCode = "code_b"
+)
+
+func TestGroup(t *testing.T) {
+	var g groups.Group
+	block := make(chan struct{})
+	g.Go(func() error {
+		<-block
+		return failure.New(CodeA)
+	})
+	g.Go(func() error {
+		return nil
+	})
+	g.Go(func() error {
+		defer close(block)
+		return io.EOF
+	})
+
+	err := g.Wait()
+	assert.Equal(t, CodeA, failure.CodeOf(err))
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, "TestGroup", failure.CallStacksOf(err)[0].HeadFrame().Func())
+	assert.EqualError(t, err, "TestGroup: TestGroup.func1: code(code_a); EOF")
+
+	pcss := failure.ParentCallStacksOf(err)
+	if assert.Len(t, pcss, 1) {
+		assert.Equal(t, "TestGroup", pcss[0].HeadFrame().Func())
+		assert.Equal(t, 23, pcss[0].HeadFrame().Line())
+	}
+	assert.Contains(t, fmt.Sprintf("%+v", err), "[ParentCallStack]\n")
+
+	assert.Nil(t, (&groups.Group{}).Wait())
+}
+
+func TestWithContext(t *testing.T) {
+	g, ctx := groups.WithContext(context.Background())
+	g.Go(func() error {
+		return failure.New(CodeB)
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	err := g.Wait()
+	assert.Equal(t, CodeB, failure.CodeOf(err))
+	assert.Equal(t, CodeB, failure.CodeOf(context.Cause(ctx)))
+}
@@ -0,0 +1,225 @@
+// Package grpcutil converts errors of the failure package from/to
+// gRPC statuses.
+package grpcutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/morikuni/failure"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the domain of errdetails.ErrorInfo attached by ToStatus.
+const Domain = "github.com/morikuni/failure"
+
+var (
+	mu       sync.RWMutex
+	toGRPC   = make(map[failure.Code]codes.Code)
+	fromGRPC = make(map[codes.Code]failure.Code)
+)
+
+// RegisterCode registers the mapping between a failure code and
+// a gRPC code.
+// If the gRPC code is already mapped from another failure code,
+// FromStatus keeps using the first registered one.
+// Codes aliased by failure.AliasCode are resolved.
+func RegisterCode(code failure.Code, c codes.Code) {
+	code = failure.ResolveCode(code)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	toGRPC[code] = c
+	if _, ok := fromGRPC[c]; !ok {
+		fromGRPC[c] = code
+	}
+}
+
+// ToStatus converts err into a gRPC status.
+// The gRPC code is looked up from the codes registered by
+// RegisterCode, and codes.Unknown is used for unregistered ones.
+// The message is the one of failure.PublicMessageOf, or the name of
+// the gRPC code if err has no public message. Messages of other layers
+// and Error() are never used, as they may contain internal details.
+// The failure code and debug information are attached as
+// errdetails.ErrorInfo so that FromStatus can restore them.
+// The hint of failure.RetryAfterOf is attached as errdetails.RetryInfo.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	code := failure.CodeOf(err)
+	c := codes.Unknown
+	if code != nil {
+		if v, ok := lookupGRPC(code); ok {
+			c = v
+		}
+	}
+
+	msg := failure.PublicMessageOf(err)
+	if msg == "" {
+		msg = c.String()
+	}
+	st := status.New(c, msg)
+
+	if d, ok := failure.RetryAfterOf(err); ok {
+		if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(d)}); err == nil {
+			st = withDetails
+		}
+	}
+
+	if code == nil {
+		return st
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   code.ErrorCode(),
+		Domain:   Domain,
+		Metadata: make(map[string]string),
+	}
+	for k, v := range failure.ContextOf(err) {
+		info.Metadata[k] = fmt.Sprint(v)
+	}
+
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus converts st into an error.
+// It returns nil if st is OK.
+// The failure code is restored from errdetails.ErrorInfo attached
+// by ToStatus, or from the codes registered by RegisterCode.
+// The reason of errdetails.ErrorInfo is restored as the code registered
+// by RegisterCode or failure.RegisterCode having the same string
+// representation, such as failure.IntCode, or as failure.StringCode
+// otherwise.
+// If neither is available, the name of the gRPC code is used as
+// failure.StringCode.
+// errdetails.RetryInfo is restored by failure.WithRetryAfter.
+func FromStatus(st *status.Status) error {
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	var (
+		code       failure.Code
+		debug      failure.Debug
+		retryAfter *durationpb.Duration
+	)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.RetryInfo:
+			retryAfter = d.GetRetryDelay()
+		case *errdetails.ErrorInfo:
+			if code != nil || d.GetDomain() != Domain {
+				continue
+			}
+			code = lookupCode(d.GetReason())
+			if md := d.GetMetadata(); len(md) != 0 {
+				debug = make(failure.Debug, len(md))
+				for k, v := range md {
+					debug[k] = v
+				}
+			}
+		}
+	}
+
+	if code == nil {
+		mu.RLock()
+		code = fromGRPC[st.Code()]
+		mu.RUnlock()
+	}
+	if code == nil {
+		code = failure.StringCode(st.Code().String())
+	}
+
+	wrappers := []failure.Wrapper{failure.Message(st.Message())}
+	if debug != nil {
+		wrappers = append(wrappers, debug)
+	}
+	if retryAfter != nil {
+		wrappers = append(wrappers, failure.WithRetryAfter(retryAfter.AsDuration()))
+	}
+	return failure.New(code, wrappers...)
+}
+
+// lookupGRPC returns the gRPC code registered for code.
+// Codes registered before they became aliases are found by resolving
+// them again.
+func lookupGRPC(code failure.Code) (codes.Code, bool) {
+	code = failure.ResolveCode(code)
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if c, ok := toGRPC[code]; ok {
+		return c, true
+	}
+	for k, c := range toGRPC {
+		if failure.ResolveCode(k) == code {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+func lookupCode(reason string) failure.Code {
+	mu.RLock()
+	for c := range toGRPC {
+		if c.ErrorCode() == reason {
+			mu.RUnlock()
+			return failure.ResolveCode(c)
+		}
+	}
+	mu.RUnlock()
+
+	for _, info := range failure.Codes() {
+		if info.Code.ErrorCode() == reason {
+			return info.Code
+		}
+	}
+	return failure.StringCode(reason)
+}
+
+// WithGRPC appends the metadata of the gRPC request of ctx to an error
+// as debug information with the keys of failure.WithRequest.
+// The full method name as the method, the peer address, the
+// x-request-id metadata and the user agent are added, and empty ones
+// are omitted.
+//
+//	failure.Wrap(err, grpcutil.WithGRPC(ctx))
+func WithGRPC(ctx context.Context) failure.Wrapper {
+	d := failure.Debug{}
+	add := func(k, v string) {
+		if v != "" {
+			d[k] = v
+		}
+	}
+
+	if m, ok := grpc.Method(ctx); ok {
+		add(failure.MethodKey, m)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		add(failure.PeerKey, p.Addr.String())
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vs := md.Get("x-request-id"); len(vs) != 0 {
+			add(failure.RequestIDKey, vs[0])
+		}
+		if vs := md.Get("user-agent"); len(vs) != 0 {
+			add(failure.UserAgentKey, vs[0])
+		}
+	}
+	return d
+}
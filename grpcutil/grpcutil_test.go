@@ -0,0 +1,163 @@
+package grpcutil_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/grpcutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	NotFound  failure.StringCode = "not_found"
+	Forbidden failure.StringCode = "forbidden"
+	Aborted   failure.IntCode    = 409
+	Conflict  failure.IntCode    = 4090
+)
+
+func init() {
+	grpcutil.RegisterCode(NotFound, codes.NotFound)
+	grpcutil.RegisterCode(Forbidden, codes.PermissionDenied)
+	grpcutil.RegisterCode(Aborted, codes.Aborted)
+	failure.RegisterCode(Conflict, "The resource conflicts.", 0, false)
+}
+
+func TestToStatus(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		"registered": {
+			err:         failure.New(NotFound, failure.Message("xxx")),
+			wantCode:    codes.NotFound,
+			wantMessage: "NotFound",
+		},
+		"public message": {
+			err:         failure.New(NotFound, failure.Message("xxx"), failure.WithPublicMessage("yyy")),
+			wantCode:    codes.NotFound,
+			wantMessage: "yyy",
+		},
+		"unregistered": {
+			err:         failure.New(failure.StringCode("unknown")),
+			wantCode:    codes.Unknown,
+			wantMessage: "Unknown",
+		},
+		"no code": {
+			err:         io.EOF,
+			wantCode:    codes.Unknown,
+			wantMessage: "Unknown",
+		},
+		"nil": {
+			err:         nil,
+			wantCode:    codes.OK,
+			wantMessage: "",
+		},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			st := grpcutil.ToStatus(test.err)
+			assert.Equal(t, test.wantCode, st.Code())
+			assert.Equal(t, test.wantMessage, st.Message())
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	err := failure.New(Forbidden,
+		failure.WithPublicMessage("xxx"),
+		failure.Debug{"id": 1},
+	)
+
+	got := grpcutil.FromStatus(grpcutil.ToStatus(err))
+
+	assert.Equal(t, Forbidden, failure.CodeOf(got))
+	assert.Equal(t, "xxx", failure.MessageOf(got))
+	assert.Equal(t, []failure.Debug{{"id": "1"}}, failure.DebugsOf(got))
+}
+
+func TestRoundTrip_IntCode(t *testing.T) {
+	got := grpcutil.FromStatus(grpcutil.ToStatus(failure.New(Aborted)))
+	assert.Equal(t, Aborted, failure.CodeOf(got))
+
+	got = grpcutil.FromStatus(grpcutil.ToStatus(failure.New(Conflict)))
+	assert.Equal(t, Conflict, failure.CodeOf(got))
+}
+
+func TestToStatus_Alias(t *testing.T) {
+	oldCode := failure.StringCode("grpcutil_test.old")
+	newCode := failure.StringCode("grpcutil_test.new")
+	older := failure.StringCode("grpcutil_test.older")
+
+	grpcutil.RegisterCode(oldCode, codes.AlreadyExists)
+	failure.AliasCode(oldCode, newCode)
+	failure.AliasCode(older, newCode)
+	grpcutil.RegisterCode(older, codes.AlreadyExists)
+
+	for _, c := range []failure.Code{oldCode, newCode, older} {
+		st := grpcutil.ToStatus(failure.New(c))
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+		assert.Equal(t, newCode, failure.CodeOf(grpcutil.FromStatus(st)))
+	}
+}
+
+func TestRoundTrip_RetryAfter(t *testing.T) {
+	got := grpcutil.FromStatus(grpcutil.ToStatus(failure.New(Forbidden, failure.WithRetryAfter(3*time.Second))))
+
+	d, ok := failure.RetryAfterOf(got)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	got = grpcutil.FromStatus(grpcutil.ToStatus(failure.Wrap(io.EOF, failure.WithRetryAfter(time.Second))))
+	d, ok = failure.RetryAfterOf(got)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, d)
+}
+
+func TestFromStatus(t *testing.T) {
+	err := grpcutil.FromStatus(status.New(codes.NotFound, "xxx"))
+	assert.Equal(t, NotFound, failure.CodeOf(err))
+	assert.Equal(t, "xxx", failure.MessageOf(err))
+
+	err = grpcutil.FromStatus(status.New(codes.Internal, "yyy"))
+	assert.Equal(t, failure.StringCode("Internal"), failure.CodeOf(err))
+
+	assert.NoError(t, grpcutil.FromStatus(status.New(codes.OK, "")))
+}
+
+type serverTransportStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s serverTransportStream) Method() string {
+	return s.method
+}
+
+func TestWithGRPC(t *testing.T) {
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), serverTransportStream{method: "/pkg.Service/Get"})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "req-1", "user-agent", "grpc-go/1.65.0"))
+
+	err := failure.Wrap(io.EOF, grpcutil.WithGRPC(ctx))
+	assert.Equal(t, []failure.Debug{{
+		"method":     "/pkg.Service/Get",
+		"peer":       "192.0.2.1:1234",
+		"request_id": "req-1",
+		"user_agent": "grpc-go/1.65.0",
+	}}, failure.DebugsOf(err))
+
+	err = failure.Wrap(io.EOF, grpcutil.WithGRPC(context.Background()))
+	assert.Equal(t, []failure.Debug{{}}, failure.DebugsOf(err))
+}
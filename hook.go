@@ -0,0 +1,50 @@
+package failure
+
+import "sync"
+
+// Hook is called with errors created by New, Translate, Wrap and
+// WrapMultiple.
+type Hook interface {
+	// HandleError is called with a created error.
+	// It must be safe to call concurrently and should return quickly
+	// because it runs synchronously on every error creation.
+	HandleError(err error)
+}
+
+// HookFunc is an adaptor to use function as the Hook interface.
+type HookFunc func(err error)
+
+// HandleError implements the Hook interface.
+func (f HookFunc) HandleError(err error) {
+	f(err)
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []Hook
+)
+
+// RegisterHook registers the hook called on every error creation.
+// Hooks are called in the registered order.
+func RegisterHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	hooks = append(hooks[:len(hooks):len(hooks)], h)
+}
+
+func runHooks(err error) {
+	if err == nil {
+		return
+	}
+
+	collect(err)
+
+	hooksMu.RLock()
+	hs := hooks
+	hooksMu.RUnlock()
+
+	for _, h := range hs {
+		h.HandleError(err)
+	}
+}
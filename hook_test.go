@@ -0,0 +1,36 @@
+package failure_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterHook(t *testing.T) {
+	const code failure.StringCode = "hook_test"
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	failure.RegisterHook(failure.HookFunc(func(err error) {
+		if failure.CodeOf(err) != code {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+
+	e1 := failure.New(code)
+	e2 := failure.Translate(io.EOF, code)
+	e3 := failure.Wrap(e1)
+	e4 := failure.WrapMultiple([]error{e2, io.EOF})
+	failure.New(TestCodeA)
+	failure.Custom(e1, failure.Message("custom"))
+
+	assert.Equal(t, []error{e1, e2, e3, e4}, errs)
+}
@@ -0,0 +1,109 @@
+package failure
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+var (
+	httpStatusesMu sync.RWMutex
+	httpStatuses   = make(map[Code]int)
+)
+
+// RegisterHTTPStatus registers the HTTP status code for the error code.
+func RegisterHTTPStatus(code Code, status int) {
+	httpStatusesMu.Lock()
+	defer httpStatusesMu.Unlock()
+
+	httpStatuses[resolveCode(code)] = status
+}
+
+// HTTPStatusOf returns the HTTP status code registered for the error
+// code of err.
+// It returns http.StatusOK if err is nil, and
+// http.StatusInternalServerError if no status is registered.
+func HTTPStatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	c := CodeOf(err)
+	if c == nil {
+		return http.StatusInternalServerError
+	}
+
+	httpStatusesMu.RLock()
+	defer httpStatusesMu.RUnlock()
+
+	if s, ok := lookupAliased(httpStatuses, c); ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPErrorWriter writes errors as JSON HTTP responses.
+//
+//	{"code": "not_found", "message": "user not found", "stack": [...]}
+//
+// The message is the one of PublicMessageOf, or the status text if err
+// has no public message. Messages of other layers and Error() are never
+// written, as they may contain internal details.
+// The status code of the response is decided by HTTPStatusOf, and
+// the Retry-After header is set if err has RetryAfterOf.
+type HTTPErrorWriter struct {
+	// IncludeCallStack makes the response include the call stack.
+	// It should be enabled only in development.
+	IncludeCallStack bool
+}
+
+type httpErrorResponse struct {
+	Code    string    `json:"code,omitempty"`
+	Message string    `json:"message"`
+	Stack   CallStack `json:"stack,omitempty"`
+}
+
+// WriteError writes err to w.
+func (hw HTTPErrorWriter) WriteError(w http.ResponseWriter, err error) {
+	status := HTTPStatusOf(err)
+
+	res := httpErrorResponse{
+		Message: PublicMessageOf(err),
+	}
+	if c := CodeOf(err); c != nil {
+		res.Code = c.ErrorCode()
+	}
+	if res.Message == "" {
+		res.Message = http.StatusText(status)
+	}
+	if hw.IncludeCallStack {
+		res.Stack = CallStackOf(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if d, ok := RetryAfterOf(err); ok {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
+
+// WriteHTTPError writes err to w as a JSON HTTP response
+// without the call stack.
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	HTTPErrorWriter{}.WriteError(w, err)
+}
+
+// HTTPHandlerFunc is an http.Handler which can return an error.
+// The returned error is written by WriteHTTPError.
+type HTTPHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements the http.Handler interface.
+func (f HTTPHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteHTTPError(w, err)
+	}
+}
@@ -0,0 +1,131 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	HTTPNotFound  failure.StringCode = "http_not_found"
+	HTTPForbidden failure.StringCode = "http_forbidden"
+)
+
+func init() {
+	failure.RegisterHTTPStatus(HTTPNotFound, http.StatusNotFound)
+	failure.RegisterHTTPStatus(HTTPForbidden, http.StatusForbidden)
+}
+
+func TestHTTPStatusOf(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, failure.HTTPStatusOf(failure.New(HTTPNotFound)))
+	assert.Equal(t, http.StatusForbidden, failure.HTTPStatusOf(failure.Translate(failure.New(HTTPNotFound), HTTPForbidden)))
+	assert.Equal(t, http.StatusInternalServerError, failure.HTTPStatusOf(failure.New(TestCodeA)))
+	assert.Equal(t, http.StatusInternalServerError, failure.HTTPStatusOf(io.EOF))
+	assert.Equal(t, http.StatusOK, failure.HTTPStatusOf(nil))
+}
+
+func TestHTTPErrorWriter(t *testing.T) {
+	tests := map[string]struct {
+		writer failure.HTTPErrorWriter
+		err    error
+
+		wantStatus     int
+		wantCode       string
+		wantMessage    string
+		wantStack      bool
+		wantRetryAfter string
+	}{
+		"registered": {
+			writer:      failure.HTTPErrorWriter{},
+			err:         failure.New(HTTPNotFound, failure.Message("xxx")),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "http_not_found",
+			wantMessage: "Not Found",
+			wantStack:   false,
+		},
+		"public message": {
+			writer:      failure.HTTPErrorWriter{},
+			err:         failure.New(HTTPNotFound, failure.Message("no row for id=1"), failure.WithPublicMessage("not found")),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "http_not_found",
+			wantMessage: "not found",
+			wantStack:   false,
+		},
+		"no message": {
+			writer:      failure.HTTPErrorWriter{},
+			err:         io.EOF,
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    "",
+			wantMessage: "Internal Server Error",
+			wantStack:   false,
+		},
+		"include call stack": {
+			writer:      failure.HTTPErrorWriter{IncludeCallStack: true},
+			err:         failure.New(HTTPForbidden),
+			wantStatus:  http.StatusForbidden,
+			wantCode:    "http_forbidden",
+			wantMessage: "Forbidden",
+			wantStack:   true,
+		},
+		"retry after": {
+			writer:         failure.HTTPErrorWriter{},
+			err:            failure.New(HTTPForbidden, failure.WithRetryAfter(1500*time.Millisecond)),
+			wantStatus:     http.StatusForbidden,
+			wantCode:       "http_forbidden",
+			wantMessage:    "Forbidden",
+			wantRetryAfter: "2",
+		},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			test.writer.WriteError(rec, test.err)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+			assert.Equal(t, test.wantRetryAfter, rec.Header().Get("Retry-After"))
+
+			var body struct {
+				Code    string                   `json:"code"`
+				Message string                   `json:"message"`
+				Stack   []map[string]interface{} `json:"stack"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, test.wantCode, body.Code)
+			assert.Equal(t, test.wantMessage, body.Message)
+			if test.wantStack {
+				if assert.NotEmpty(t, body.Stack) {
+					assert.Equal(t, "TestHTTPErrorWriter", body.Stack[0]["func"])
+				}
+			} else {
+				assert.Empty(t, body.Stack)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerFunc(t *testing.T) {
+	h := failure.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("ok") != "" {
+			io.WriteString(w, "ok")
+			return nil
+		}
+		return failure.New(HTTPNotFound)
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?ok=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
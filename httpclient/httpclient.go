@@ -0,0 +1,109 @@
+// Package httpclient classifies errors of HTTP clients into errors of
+// the failure package.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/morikuni/failure"
+)
+
+// Codes of errors of HTTP clients.
+// They share the "httpclient" namespace so that
+// failure.CodeMatches(err, "httpclient.*") matches all of them.
+const (
+	// Timeout means the request timed out.
+	Timeout failure.StringCode = "httpclient.timeout"
+	// DNS means the host name could not be resolved.
+	DNS failure.StringCode = "httpclient.dns"
+	// ConnRefused means the server refused the connection.
+	ConnRefused failure.StringCode = "httpclient.conn_refused"
+	// TLS means the TLS handshake or certificate verification failed.
+	TLS failure.StringCode = "httpclient.tls"
+	// Canceled means the context of the request was canceled.
+	Canceled failure.StringCode = "httpclient.canceled"
+	// Transport means any other transport error.
+	Transport failure.StringCode = "httpclient.transport"
+)
+
+// Classify returns the code for err returned by http.Client or
+// http.RoundTripper.
+func Classify(err error) failure.Code {
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		recordErr    tls.RecordHeaderError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.As(err, &dnsErr):
+		return DNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ConnRefused
+	case errors.As(err, &recordErr),
+		errors.As(err, &verifyErr),
+		errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr):
+		return TLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Timeout
+	}
+	return Transport
+}
+
+// Do sends req with c, and translates the error into a failure error
+// with the code decided by Classify.
+// The method and the URL of the request are appended as debug
+// information keyed by "method" and "url".
+func Do(c *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, translate(err, req)
+	}
+	return resp, nil
+}
+
+// RoundTripper wraps Base to translate errors like Do.
+// http.DefaultTransport is used if Base is nil.
+// Note that http.Client replaces errors with its own one when
+// http.Client.Timeout is exceeded, so use Do to classify them.
+type RoundTripper struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rt RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, translate(err, req)
+	}
+	return resp, nil
+}
+
+func translate(err error, req *http.Request) error {
+	return failure.Translate(err, Classify(err),
+		failure.Debug{
+			"method": req.Method,
+			"url":    req.URL.Redacted(),
+		},
+		failure.WithCallStackSkip(2),
+	)
+}
@@ -0,0 +1,105 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedURL := "http://" + l.Addr().String()
+	l.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := map[string]struct {
+		client *http.Client
+		ctx    context.Context
+		url    string
+
+		want failure.Code
+	}{
+		"timeout":      {&http.Client{Timeout: 10 * time.Millisecond}, context.Background(), slow.URL, httpclient.Timeout},
+		"conn refused": {http.DefaultClient, context.Background(), refusedURL, httpclient.ConnRefused},
+		"tls":          {http.DefaultClient, context.Background(), tlsServer.URL, httpclient.TLS},
+		"canceled":     {http.DefaultClient, canceled, slow.URL, httpclient.Canceled},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(test.ctx, http.MethodGet, test.url+"/path", nil)
+
+			resp, err := httpclient.Do(test.client, req)
+			assert.Nil(t, resp)
+			assert.Equal(t, test.want, failure.CodeOf(err))
+
+			url, _ := failure.ValueOf(err, "url")
+			assert.Equal(t, test.url+"/path", url)
+			method, _ := failure.ValueOf(err, "method")
+			assert.Equal(t, http.MethodGet, method)
+			assert.Equal(t, "TestDo.func3", failure.CallStackOf(err).HeadFrame().Func())
+		})
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	c := &http.Client{Transport: httpclient.RoundTripper{}}
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	_, err := c.Get(tlsServer.URL)
+	assert.Equal(t, httpclient.TLS, failure.CodeOf(err))
+	url, _ := failure.ValueOf(err, "url")
+	assert.Equal(t, tlsServer.URL, url)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := c.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		want failure.Code
+	}{
+		"dns":       {&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, httpclient.DNS},
+		"deadline":  {context.DeadlineExceeded, httpclient.Timeout},
+		"transport": {io.ErrUnexpectedEOF, httpclient.Transport},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, httpclient.Classify(test.err))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package httpserver provides a middleware of HTTP servers handling
+// errors of the failure package.
+//
+// Middleware works with net/http and routers compatible with it like
+// github.com/go-chi/chi.
+//
+//	r := chi.NewRouter()
+//	r.Use(httpserver.Middleware)
+//	r.Method(http.MethodGet, "/users/{id}", httpserver.HandlerFunc(getUser))
+//
+// Adapters for other frameworks are provided by echoutil and ginutil.
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/morikuni/failure"
+)
+
+// RequestIDKey is the key of the debug information having the request
+// ID.
+const RequestIDKey = failure.RequestIDKey
+
+// Config configures how errors are handled.
+// The zero value is ready to use.
+type Config struct {
+	// RequestID returns the ID of the request attached to errors as
+	// debug information keyed by RequestIDKey.
+	// The X-Request-Id header is used if it is nil.
+	RequestID func(r *http.Request) string
+	// Log logs errors.
+	// The error is logged by log.Printf with its call stack if it is
+	// nil.
+	Log func(r *http.Request, err error)
+	// Writer writes errors as HTTP responses using the status
+	// registered by failure.RegisterHTTPStatus.
+	Writer failure.HTTPErrorWriter
+}
+
+// HandleError attaches the request ID to err, logs it and writes it
+// to w.
+// In Middleware, err is only logged if the handler has already written
+// the response, so that the response is not corrupted.
+func (c Config) HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	if id := c.requestID(r); id != "" {
+		err = failure.Wrap(err, failure.Debug{RequestIDKey: id})
+	}
+
+	if c.Log != nil {
+		c.Log(r, err)
+	} else {
+		log.Printf("%s %s: %+v", r.Method, r.URL.Path, err)
+	}
+
+	if rw, ok := w.(*responseWriter); ok && rw.written {
+		return
+	}
+	c.Writer.WriteError(w, err)
+}
+
+func (c Config) requestID(r *http.Request) string {
+	if c.RequestID != nil {
+		return c.RequestID(r)
+	}
+	return r.Header.Get("X-Request-Id")
+}
+
+// Middleware returns a middleware which recovers panics of next and
+// handles them by HandleError.
+// Errors returned by handlers of HandlerFunc are handled as well.
+func (c Config) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		if err := serve(next, rw, r); err != nil {
+			c.HandleError(rw, r, err)
+		}
+	})
+}
+
+// Middleware is the middleware of the zero Config.
+func Middleware(next http.Handler) http.Handler {
+	return Config{}.Middleware(next)
+}
+
+// responseWriter tracks whether the response has been written.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	w.written = true
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying http.ResponseWriter for
+// http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type errorKey struct{}
+
+type errorHolder struct {
+	err error
+}
+
+// HandlerFunc is an http.Handler which can return an error.
+// The returned error is handled by Middleware, or served as
+// failure.HTTPHandlerFunc if Middleware is not used.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements the http.Handler interface.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, ok := r.Context().Value(errorKey{}).(*errorHolder); ok {
+		h.err = f(w, r)
+		return
+	}
+	failure.HTTPHandlerFunc(f).ServeHTTP(w, r)
+}
+
+func serve(h http.Handler, w http.ResponseWriter, r *http.Request) error {
+	holder := &errorHolder{}
+	r = r.WithContext(context.WithValue(r.Context(), errorKey{}, holder))
+	if err := recoverServe(h, w, r); err != nil {
+		if errors.Is(err, http.ErrAbortHandler) {
+			// http.Server handles it to abort the response silently.
+			panic(http.ErrAbortHandler)
+		}
+		return err
+	}
+	return holder.err
+}
+
+func recoverServe(h http.Handler, w http.ResponseWriter, r *http.Request) (err error) {
+	defer failure.Recover(&err, failure.PanicCode)
+	h.ServeHTTP(w, r)
+	return nil
+}
@@ -0,0 +1,125 @@
+package httpserver_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/httpserver"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "httpserver.not_found"
+
+func init() {
+	failure.RegisterHTTPStatus(NotFound, http.StatusNotFound)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		handler    http.Handler
+		wantStatus int
+		wantCode   string
+		wantErr    bool
+	}{
+		"error": {
+			handler: httpserver.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				return failure.New(NotFound)
+			}),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "httpserver.not_found",
+			wantErr:    true,
+		},
+		"panic": {
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			}),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "failure.panic",
+			wantErr:    true,
+		},
+		"no error": {
+			handler: httpserver.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}),
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logged error
+			mw := httpserver.Config{
+				Log: func(r *http.Request, err error) {
+					logged = err
+				},
+			}.Middleware
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Request-Id", "req-1")
+			mw(test.handler).ServeHTTP(w, r)
+
+			assert.Equal(t, test.wantStatus, w.Code)
+			if !test.wantErr {
+				assert.Nil(t, logged)
+				return
+			}
+
+			var res struct {
+				Code string `json:"code"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+			assert.Equal(t, test.wantCode, res.Code)
+			v, ok := failure.ValueOf(logged, httpserver.RequestIDKey)
+			assert.True(t, ok)
+			assert.Equal(t, "req-1", v)
+			assert.NotNil(t, failure.CallStackOf(logged))
+		})
+	}
+}
+
+func TestMiddleware_Written(t *testing.T) {
+	var logged error
+	mw := httpserver.Config{
+		Log: func(r *http.Request, err error) {
+			logged = err
+		},
+	}.Middleware
+	h := mw(httpserver.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "partial")
+		return failure.New(NotFound)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+	assert.True(t, failure.Is(logged, NotFound))
+}
+
+func TestMiddleware_AbortHandler(t *testing.T) {
+	h := httpserver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestHandlerFunc(t *testing.T) {
+	h := httpserver.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return failure.Translate(io.EOF, NotFound)
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
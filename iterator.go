@@ -1,14 +1,50 @@
 package failure
 
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// DefaultMaxUnwrapDepth is the default number of errors visited by
+// Iterator.
+const DefaultMaxUnwrapDepth = 1000
+
+var maxUnwrapDepth int32 = DefaultMaxUnwrapDepth
+
+// SetMaxUnwrapDepth sets the maximum number of errors visited by
+// Iterator, which protects accessors and %+v from pathological
+// chains.
+// If depth is less than 1, DefaultMaxUnwrapDepth is used.
+// It is safe to call SetMaxUnwrapDepth concurrently.
+func SetMaxUnwrapDepth(depth int) {
+	if depth < 1 {
+		depth = DefaultMaxUnwrapDepth
+	}
+	atomic.StoreInt32(&maxUnwrapDepth, int32(depth))
+}
+
+// MaxUnwrapDepth returns the maximum number of errors visited by
+// Iterator.
+func MaxUnwrapDepth() int {
+	return int(atomic.LoadInt32(&maxUnwrapDepth))
+}
+
 // NewIterator creates an iterator for given err.
 func NewIterator(err error) *Iterator {
-	return &Iterator{guardianUnwapper{err}}
+	return &Iterator{err: guardianUnwapper{err}}
 }
 
 // Iterator is designed to iterate errors by unwrapping it
 // with for loop.
+// It stops when more than MaxUnwrapDepth errors are visited or the
+// chain has a cycle, like an error unwrapped to itself.
 type Iterator struct {
-	err error
+	err       error
+	guard     chainGuard
+	truncated bool
+	// probe is set for iterators looking for codes of joined errors,
+	// which follow the first error without looking for codes again.
+	probe bool
 }
 
 // Next try to unwrap an error and returns whether the next
@@ -18,16 +54,92 @@ func (i *Iterator) Next() bool {
 	if i.err == nil {
 		return false
 	}
+	if !i.guard.visit(i.err) {
+		i.err = nil
+		i.truncated = true
+		return false
+	}
+	return true
+}
+
+// Truncated reports whether the iteration stopped before the end of
+// the chain because of MaxUnwrapDepth or a cycle.
+func (i *Iterator) Truncated() bool {
+	return i.truncated
+}
+
+// cycleCheckDepth is the depth where chainGuard starts detecting
+// cycles. Cycles are detected a bit late, but ordinary chains are
+// iterated without the cost.
+const cycleCheckDepth = 16
+
+// chainGuard detects chains which are too deep or have a cycle.
+type chainGuard struct {
+	depth int
+	seen  map[pointerKey]struct{}
+}
+
+type pointerKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// visit reports whether err can be visited.
+func (g *chainGuard) visit(err error) bool {
+	g.depth++
+	if g.depth > MaxUnwrapDepth() {
+		return false
+	}
+	if g.depth <= cycleCheckDepth {
+		return true
+	}
+
+	// Only pointers are checked because values can't refer
+	// themselves, and comparing them may panic.
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Ptr {
+		return true
+	}
+	if g.seen == nil {
+		g.seen = make(map[pointerKey]struct{})
+	}
+	k := pointerKey{v.Type(), v.Pointer()}
+	if _, ok := g.seen[k]; ok {
+		return false
+	}
+	g.seen[k] = struct{}{}
 	return true
 }
 
 func (i *Iterator) unwrapError() error {
+	type stdUnwrapper interface {
+		Unwrap() error
+	}
+	type multiUnwrapper interface {
+		Unwrap() []error
+	}
 	type causer interface {
 		Cause() error
 	}
 	switch t := i.err.(type) {
 	case Unwrapper:
 		return t.UnwrapError()
+	case stdUnwrapper:
+		return t.Unwrap()
+	case multiUnwrapper:
+		// follow the first error having a code as a primary error,
+		// like fmt.Errorf("%w: %w", ErrX, failure.New(Code)).
+		errs := t.Unwrap()
+		if !i.probe {
+			for _, err := range errs {
+				if hasCode(err) {
+					return err
+				}
+			}
+		}
+		if len(errs) != 0 {
+			return errs[0]
+		}
 	case causer:
 		return t.Cause()
 	}
@@ -39,6 +151,77 @@ func (i *Iterator) Error() error {
 	return i.err
 }
 
+// Code returns the error code of current error.
+// It returns nil if current error itself has no error code.
+func (i *Iterator) Code() Code {
+	type codeGetter interface {
+		GetCode() Code
+	}
+	if g, ok := i.err.(codeGetter); ok {
+		return g.GetCode()
+	}
+	return nil
+}
+
+// Message returns the message of current error.
+// It returns an empty string if current error itself has no message.
+func (i *Iterator) Message() string {
+	type messageGetter interface {
+		GetMessage() string
+	}
+	if g, ok := i.err.(messageGetter); ok {
+		return g.GetMessage()
+	}
+	return ""
+}
+
+// Debug returns the debug information of current error.
+// It returns nil if current error itself has no debug information.
+func (i *Iterator) Debug() Debug {
+	type debugGetter interface {
+		GetDebug() Debug
+	}
+	if g, ok := i.err.(debugGetter); ok {
+		return g.GetDebug()
+	}
+	return nil
+}
+
+// CallStack returns the call stack of current error.
+// It returns nil if current error itself has no call stack.
+func (i *Iterator) CallStack() CallStack {
+	return callStackOfLayer(i.err)
+}
+
+// Chain returns a sequence of err and its underlying errors from
+// the outermost.
+// It has the same signature as iter.Seq[error], so it can be used
+// with range statement since Go 1.23.
+//
+//	for e := range failure.Chain(err) {
+//		...
+//	}
+func Chain(err error) func(yield func(error) bool) {
+	return func(yield func(error) bool) {
+		i := NewIterator(err)
+		for i.Next() {
+			if !yield(i.Error()) {
+				return
+			}
+		}
+	}
+}
+
+func hasCode(err error) bool {
+	i := &Iterator{err: guardianUnwapper{err}, probe: true}
+	for i.Next() {
+		if i.Code() != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type guardianUnwapper struct {
 	error
 }
@@ -1,11 +1,12 @@
 package failure_test
 
 import (
+	stderrors "errors"
+	"fmt"
 	"io"
 	"testing"
 
 	"github.com/morikuni/failure"
-	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,13 +39,216 @@ func TestIterator(t *testing.T) {
 	}
 }
 
+// causer is an error unwrapped by Cause like errors of github.com/pkg/errors.
+type causer struct {
+	err error
+}
+
+func (c causer) Error() string {
+	return c.err.Error()
+}
+
+func (c causer) Cause() error {
+	return c.err
+}
+
 func TestCauseOf(t *testing.T) {
 	f := failure.Wrap(io.EOF)
 	assert.Equal(t, io.EOF, failure.CauseOf(f))
 
 	base := failure.Wrap(io.EOF)
-	pkgErr := errors.Wrap(base, "aaa")
-	assert.Equal(t, io.EOF, failure.CauseOf(failure.Wrap(pkgErr)))
+	causerErr := causer{base}
+	assert.Equal(t, io.EOF, failure.CauseOf(failure.Wrap(causerErr)))
 
 	assert.Nil(t, failure.CauseOf(nil))
 }
+
+func TestIterator_StdUnwrap(t *testing.T) {
+	base := a{io.EOF}
+	err := fmt.Errorf("wrap: %w", base)
+	wantErrs := []error{err, base, io.EOF}
+
+	i := failure.NewIterator(err)
+	var errs []error
+	for i.Next() {
+		errs = append(errs, i.Error())
+	}
+	assert.Equal(t, wantErrs, errs)
+}
+
+func TestIterator_Join(t *testing.T) {
+	err := stderrors.Join(failure.New(TestCodeB), io.EOF)
+
+	assert.Equal(t, TestCodeB, failure.CodeOf(err))
+}
+
+func TestIterator_Accessors(t *testing.T) {
+	base := failure.New(failure.StringCode("a"), failure.Message("xxx"), failure.Debug{"zzz": true})
+	err := failure.Translate(base, failure.StringCode("b"))
+
+	var (
+		codes    []failure.Code
+		messages []string
+		debugs   []failure.Debug
+		stacks   []string
+	)
+	i := failure.NewIterator(err)
+	for i.Next() {
+		if c := i.Code(); c != nil {
+			codes = append(codes, c)
+		}
+		if m := i.Message(); m != "" {
+			messages = append(messages, m)
+		}
+		if d := i.Debug(); d != nil {
+			debugs = append(debugs, d)
+		}
+		if cs := i.CallStack(); cs != nil {
+			stacks = append(stacks, cs.HeadFrame().Func())
+		}
+	}
+
+	assert.Equal(t, []failure.Code{failure.StringCode("b"), failure.StringCode("a")}, codes)
+	assert.Equal(t, []string{"xxx"}, messages)
+	assert.Equal(t, []failure.Debug{{"zzz": true}}, debugs)
+	assert.Equal(t, []string{"TestIterator_Accessors", "TestIterator_Accessors"}, stacks)
+}
+
+func TestChain(t *testing.T) {
+	err := a{b{a{io.EOF}}}
+
+	var errs []error
+	failure.Chain(err)(func(e error) bool {
+		errs = append(errs, e)
+		return true
+	})
+	assert.Equal(t, []error{err, err.error, io.EOF}, errs)
+
+	errs = nil
+	failure.Chain(err)(func(e error) bool {
+		errs = append(errs, e)
+		return len(errs) < 2
+	})
+	assert.Len(t, errs, 2)
+}
+
+func TestIterator_ErrorfInterop(t *testing.T) {
+	base := failure.New(TestCodeA, failure.Message("xxx"), failure.Debug{"zzz": true})
+
+	t.Run("failure wrapped by fmt.Errorf", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", base)
+
+		assert.Equal(t, TestCodeA, failure.CodeOf(err))
+		assert.Equal(t, "xxx", failure.MessageOf(err))
+		assert.Equal(t, []failure.Debug{{"zzz": true}}, failure.DebugsOf(err))
+		assert.Equal(t, failure.CallStackOf(base), failure.CallStackOf(err))
+		assert.True(t, failure.Is(err, TestCodeA))
+		assert.True(t, stderrors.Is(err, base))
+		assert.Equal(t, "TestIterator_ErrorfInterop", failure.CallStackOf(err).HeadFrame().Func())
+	})
+
+	t.Run("fmt.Errorf wrapped by failure", func(t *testing.T) {
+		err := failure.Wrap(fmt.Errorf("middle: %w", fmt.Errorf("inner: %w", base)), failure.Debug{"yyy": 1})
+
+		assert.Equal(t, TestCodeA, failure.CodeOf(err))
+		assert.Equal(t, "xxx", failure.MessageOf(err))
+		assert.Equal(t, []failure.Debug{{"yyy": 1}, {"zzz": true}}, failure.DebugsOf(err))
+		assert.Len(t, failure.CallStacksOf(err), 2)
+		assert.Equal(t, "TestIterator_ErrorfInterop", failure.CallStackOf(err).HeadFrame().Func())
+		assert.True(t, stderrors.Is(err, base))
+		assert.Equal(t, io.EOF, failure.CauseOf(failure.Wrap(fmt.Errorf("x: %w", io.EOF))))
+	})
+
+	t.Run("fmt.Errorf with multiple %w", func(t *testing.T) {
+		err := failure.Wrap(fmt.Errorf("%w; %w", io.EOF, base))
+
+		assert.Equal(t, TestCodeA, failure.CodeOf(err))
+		assert.Equal(t, "xxx", failure.MessageOf(err))
+		assert.True(t, stderrors.Is(err, io.EOF))
+		assert.True(t, stderrors.Is(err, base))
+	})
+
+	t.Run("translated fmt.Errorf", func(t *testing.T) {
+		err := failure.Translate(fmt.Errorf("x: %w", base), TestCodeB)
+
+		assert.Equal(t, TestCodeB, failure.CodeOf(err))
+		assert.Equal(t, []failure.Code{TestCodeB, TestCodeA}, failure.CodesOf(err))
+		assert.True(t, stderrors.Is(err, base))
+	})
+}
+
+func TestIterator_JoinPrimary(t *testing.T) {
+	err := stderrors.Join(io.EOF, failure.New(TestCodeB))
+	assert.Equal(t, TestCodeB, failure.CodeOf(err))
+
+	err = stderrors.Join(io.EOF, io.ErrUnexpectedEOF)
+	assert.Equal(t, io.EOF, failure.CauseOf(err))
+}
+
+// cyclicError is an error unwrapped to itself by mistake.
+type cyclicError struct {
+	next error
+}
+
+func (e *cyclicError) Error() string {
+	return "cyclic"
+}
+
+func (e *cyclicError) Unwrap() error {
+	return e.next
+}
+
+func TestIterator_Cycle(t *testing.T) {
+	a := &cyclicError{}
+	b := &cyclicError{a}
+	a.next = b
+	err := failure.Translate(a, TestCodeA)
+
+	var n int
+	i := failure.NewIterator(err)
+	for i.Next() {
+		n++
+	}
+	assert.True(t, i.Truncated())
+	assert.True(t, n < 30, n)
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "    ... (truncated)\n")
+	assert.Contains(t, failure.Tree(err), "... (truncated)\n")
+
+	joined := &cyclicError{}
+	joined.next = stderrors.Join(joined, io.EOF)
+	assert.Nil(t, failure.CodeOf(joined))
+}
+
+// deepError is unwrapped to a new error infinitely.
+type deepError struct{}
+
+func (deepError) Error() string {
+	return "deep"
+}
+
+func (deepError) Unwrap() error {
+	return deepError{}
+}
+
+func TestSetMaxUnwrapDepth(t *testing.T) {
+	defer failure.SetMaxUnwrapDepth(0)
+
+	assert.Equal(t, failure.DefaultMaxUnwrapDepth, failure.MaxUnwrapDepth())
+	failure.SetMaxUnwrapDepth(3)
+	assert.Equal(t, 3, failure.MaxUnwrapDepth())
+
+	var errs []error
+	i := failure.NewIterator(deepError{})
+	for i.Next() {
+		errs = append(errs, i.Error())
+	}
+	assert.Len(t, errs, 3)
+	assert.True(t, i.Truncated())
+
+	i = failure.NewIterator(io.EOF)
+	for i.Next() {
+	}
+	assert.False(t, i.Truncated())
+}
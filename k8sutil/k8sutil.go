@@ -0,0 +1,109 @@
+// Package k8sutil classifies errors of k8s.io/apimachinery into errors
+// of the failure package, so that controllers and operators get
+// consistent codes across Kubernetes API calls.
+package k8sutil
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/morikuni/failure"
+)
+
+// Codes of errors of the Kubernetes API.
+// They share the "k8s" namespace so that
+// failure.CodeMatches(err, "k8s.*") matches all of them.
+const (
+	NotFound           failure.StringCode = "k8s.not_found"
+	AlreadyExists      failure.StringCode = "k8s.already_exists"
+	Conflict           failure.StringCode = "k8s.conflict"
+	Invalid            failure.StringCode = "k8s.invalid"
+	BadRequest         failure.StringCode = "k8s.bad_request"
+	Unauthorized       failure.StringCode = "k8s.unauthorized"
+	Forbidden          failure.StringCode = "k8s.forbidden"
+	Gone               failure.StringCode = "k8s.gone"
+	TooManyRequests    failure.StringCode = "k8s.too_many_requests"
+	Timeout            failure.StringCode = "k8s.timeout"
+	ServiceUnavailable failure.StringCode = "k8s.service_unavailable"
+	Internal           failure.StringCode = "k8s.internal"
+	// Unknown means any other error.
+	Unknown failure.StringCode = "k8s.unknown"
+)
+
+// Keys of the debug information added by Translate.
+const (
+	ReasonKey     = "reason"
+	HTTPStatusKey = "http_status"
+)
+
+func init() {
+	// These errors go away by retrying with a fresh object or after a
+	// while.
+	for _, c := range []failure.Code{Conflict, TooManyRequests, Timeout, ServiceUnavailable} {
+		failure.RegisterRetryability(c, failure.Retryable)
+	}
+}
+
+// Classify returns the code for err returned by the Kubernetes API.
+// Conflict, TooManyRequests, Timeout and ServiceUnavailable are
+// registered as retryable.
+func Classify(err error) failure.Code {
+	switch {
+	case apierrors.IsNotFound(err):
+		return NotFound
+	case apierrors.IsAlreadyExists(err):
+		return AlreadyExists
+	case apierrors.IsConflict(err):
+		return Conflict
+	case apierrors.IsInvalid(err):
+		return Invalid
+	case apierrors.IsBadRequest(err):
+		return BadRequest
+	case apierrors.IsUnauthorized(err):
+		return Unauthorized
+	case apierrors.IsForbidden(err):
+		return Forbidden
+	case apierrors.IsGone(err), apierrors.IsResourceExpired(err):
+		return Gone
+	case apierrors.IsTooManyRequests(err):
+		return TooManyRequests
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return Timeout
+	case apierrors.IsServiceUnavailable(err):
+		return ServiceUnavailable
+	case apierrors.IsInternalError(err):
+		return Internal
+	}
+	return Unknown
+}
+
+// Translate translates err into a failure error with the code decided
+// by Classify.
+// The reason and the HTTP status of the API status are appended as
+// debug information if available.
+// It returns nil if err is nil.
+//
+//	if err := c.Get(ctx, key, &pod); err != nil {
+//		return k8sutil.Translate(err)
+//	}
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	debug := failure.Debug{}
+	if r := apierrors.ReasonForError(err); r != "" {
+		debug[ReasonKey] = string(r)
+	}
+	var s apierrors.APIStatus
+	if errors.As(err, &s) && s.Status().Code != 0 {
+		debug[HTTPStatusKey] = int(s.Status().Code)
+	}
+
+	wrappers := []failure.Wrapper{failure.WithCallStackSkip(1)}
+	if len(debug) != 0 {
+		wrappers = append(wrappers, debug)
+	}
+	return failure.Translate(err, Classify(err), wrappers...)
+}
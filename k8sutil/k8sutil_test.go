@@ -0,0 +1,64 @@
+package k8sutil_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/k8sutil"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var pods = schema.GroupResource{Resource: "pods"}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want failure.Code
+	}{
+		"not found":           {apierrors.NewNotFound(pods, "a"), k8sutil.NotFound},
+		"wrapped not found":   {fmt.Errorf("get: %w", apierrors.NewNotFound(pods, "a")), k8sutil.NotFound},
+		"already exists":      {apierrors.NewAlreadyExists(pods, "a"), k8sutil.AlreadyExists},
+		"conflict":            {apierrors.NewConflict(pods, "a", errors.New("modified")), k8sutil.Conflict},
+		"invalid":             {apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, "a", nil), k8sutil.Invalid},
+		"bad request":         {apierrors.NewBadRequest("bad"), k8sutil.BadRequest},
+		"unauthorized":        {apierrors.NewUnauthorized("who"), k8sutil.Unauthorized},
+		"forbidden":           {apierrors.NewForbidden(pods, "a", errors.New("denied")), k8sutil.Forbidden},
+		"gone":                {apierrors.NewGone("gone"), k8sutil.Gone},
+		"resource expired":    {apierrors.NewResourceExpired("expired"), k8sutil.Gone},
+		"too many requests":   {apierrors.NewTooManyRequests("slow down", 1), k8sutil.TooManyRequests},
+		"timeout":             {apierrors.NewTimeoutError("timeout", 1), k8sutil.Timeout},
+		"server timeout":      {apierrors.NewServerTimeout(pods, "get", 1), k8sutil.Timeout},
+		"service unavailable": {apierrors.NewServiceUnavailable("down"), k8sutil.ServiceUnavailable},
+		"internal":            {apierrors.NewInternalError(io.EOF), k8sutil.Internal},
+		"unknown":             {io.EOF, k8sutil.Unknown},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, k8sutil.Classify(test.err))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	err := k8sutil.Translate(apierrors.NewConflict(pods, "a", errors.New("modified")))
+	assert.Equal(t, k8sutil.Conflict, failure.CodeOf(err))
+	assert.Equal(t, []failure.Debug{{"reason": "Conflict", "http_status": http.StatusConflict}}, failure.DebugsOf(err))
+	assert.True(t, failure.IsRetryable(err))
+	assert.Equal(t, "TestTranslate", failure.CallStackOf(err).HeadFrame().Func())
+
+	err = k8sutil.Translate(apierrors.NewNotFound(pods, "a"))
+	assert.False(t, failure.IsRetryable(err))
+
+	err = k8sutil.Translate(io.EOF)
+	assert.Equal(t, k8sutil.Unknown, failure.CodeOf(err))
+	assert.Empty(t, failure.DebugsOf(err))
+
+	assert.Nil(t, k8sutil.Translate(nil))
+}
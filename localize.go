@@ -0,0 +1,78 @@
+package failure
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type localizedMessageKey struct {
+	code Code
+	lang string
+}
+
+var (
+	localizedMessagesMu sync.RWMutex
+	localizedMessages   = make(map[localizedMessageKey]string)
+)
+
+// RegisterLocalizedMessage registers the message shown to end users
+// for the error code in the language lang.
+// The language is a BCP 47 tag like "en" or "ja-JP".
+// The message is a template rendered with the debug information of
+// the error as Messagef.
+//
+//	failure.RegisterLocalizedMessage(NotFound, "en", "User {{.user_id}} is not found.")
+//	failure.RegisterLocalizedMessage(NotFound, "ja", "ユーザー{{.user_id}}が見つかりません。")
+func RegisterLocalizedMessage(code Code, lang, msg string) {
+	localizedMessagesMu.Lock()
+	defer localizedMessagesMu.Unlock()
+
+	localizedMessages[localizedMessageKey{resolveCode(code), lang}] = msg
+}
+
+// LocalizedMessage returns the message registered for the code of err
+// in the language lang.
+// If no message is registered for lang, the base language of lang
+// (e.g. "ja" for "ja-JP") is tried.
+// It returns false if no message is registered.
+//
+// Unlike MessageOf, which is meant for developers, the message is
+// meant to be shown to end users.
+func LocalizedMessage(err error, lang string) (string, bool) {
+	code := CodeOf(err)
+	if code == nil {
+		return "", false
+	}
+
+	localizedMessagesMu.RLock()
+	msg, ok := lookupLocalizedMessage(code, lang)
+	if !ok {
+		if i := strings.IndexByte(lang, '-'); i >= 0 {
+			msg, ok = lookupLocalizedMessage(code, lang[:i])
+		}
+	}
+	localizedMessagesMu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+	return RenderMessage(err, msg), true
+}
+
+// lookupLocalizedMessage looks up the message like lookupAliased.
+// The caller must hold localizedMessagesMu.
+func lookupLocalizedMessage(code Code, lang string) (string, bool) {
+	code = resolveCode(code)
+	if msg, ok := localizedMessages[localizedMessageKey{code, lang}]; ok {
+		return msg, true
+	}
+	if atomic.LoadInt32(&hasAliases) != 0 {
+		for k, msg := range localizedMessages {
+			if k.lang == lang && resolveCode(k.code) == code {
+				return msg, true
+			}
+		}
+	}
+	return "", false
+}
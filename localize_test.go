@@ -0,0 +1,43 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalizedMessage(t *testing.T) {
+	const code failure.StringCode = "localize_test"
+	failure.RegisterLocalizedMessage(code, "en", "User {{.user_id}} is not found.")
+	failure.RegisterLocalizedMessage(code, "ja", "ユーザー{{.user_id}}が見つかりません。")
+	failure.RegisterLocalizedMessage(code, "ja-Kana", "ユーザー{{.user_id}}ガ ミツカリマセン。")
+
+	err := failure.New(code, failure.Debug{"user_id": 42}, failure.Message("user not found in db"))
+
+	tests := map[string]struct {
+		err  error
+		lang string
+
+		want   string
+		wantOK bool
+	}{
+		"en":            {err, "en", "User 42 is not found.", true},
+		"exact":         {err, "ja-Kana", "ユーザー42ガ ミツカリマセン。", true},
+		"base language": {err, "ja-JP", "ユーザー42が見つかりません。", true},
+		"no language":   {err, "fr", "", false},
+		"no code":       {io.EOF, "en", "", false},
+		"nil":           {nil, "en", "", false},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			got, ok := failure.LocalizedMessage(test.err, test.lang)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+
+	assert.Equal(t, "user not found in db", failure.MessageOf(err))
+}
@@ -0,0 +1,97 @@
+// Package logrusutil provides a hook and fields to log errors of the
+// failure package with github.com/sirupsen/logrus.
+//
+//	logrus.AddHook(logrusutil.Hook{})
+//
+//	logrus.WithError(err).Error("failed")
+package logrusutil
+
+import (
+	"github.com/morikuni/failure"
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of the fields.
+// Debug information is flattened with ContextKeyPrefix like
+// "context.user_id".
+const (
+	CodeKey          = "code"
+	MessageKey       = "message"
+	StackKey         = "stack"
+	ContextKeyPrefix = "context."
+)
+
+// DefaultStackDepth is the number of frames logged when the depth is
+// not specified.
+const DefaultStackDepth = 3
+
+// Fields flattens err into fields.
+// The code, message, debug information and the top DefaultStackDepth
+// frames of the call stack in one line by failure.ShortCallStack are
+// included.
+// If the same debug key appears more than once, the outermost one is
+// used.
+func Fields(err error) logrus.Fields {
+	return fields(err, DefaultStackDepth)
+}
+
+func fields(err error, depth int) logrus.Fields {
+	fs := logrus.Fields{}
+	if err == nil {
+		return fs
+	}
+
+	if c := failure.CodeOf(err); c != nil {
+		fs[CodeKey] = c.ErrorCode()
+	}
+	if msg := failure.MessageOf(err); msg != "" {
+		fs[MessageKey] = msg
+	}
+	for k, v := range failure.ContextOf(err) {
+		fs[ContextKeyPrefix+k] = v
+	}
+	if cs := failure.CallStackOf(err); cs != nil && depth > 0 {
+		fs[StackKey] = failure.ShortCallStack(cs, depth)
+	}
+	return fs
+}
+
+// Hook is a logrus.Hook which adds Fields of the error set by
+// WithError to entries.
+// Fields already set to the entry are not overwritten.
+type Hook struct {
+	// StackDepth is the number of frames to log.
+	// DefaultStackDepth is used if it is 0, and no frame is logged if
+	// it is negative.
+	StackDepth int
+	// LogLevels are the levels the hook fires for.
+	// logrus.AllLevels is used if it is empty.
+	LogLevels []logrus.Level
+}
+
+// Levels implements the logrus.Hook interface.
+func (h Hook) Levels() []logrus.Level {
+	if len(h.LogLevels) == 0 {
+		return logrus.AllLevels
+	}
+	return h.LogLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (h Hook) Fire(e *logrus.Entry) error {
+	err, ok := e.Data[logrus.ErrorKey].(error)
+	if !ok {
+		return nil
+	}
+
+	depth := h.StackDepth
+	if depth == 0 {
+		depth = DefaultStackDepth
+	}
+	for k, v := range fields(err, depth) {
+		if _, ok := e.Data[k]; !ok {
+			e.Data[k] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package logrusutil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/logrusutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("xxx"),
+		failure.Debug{"id": 1},
+	)
+	err = failure.Wrap(err, failure.Debug{"id": 2, "name": "a"})
+
+	fs := logrusutil.Fields(err)
+	assert.Equal(t, "not_found", fs["code"])
+	assert.Equal(t, "xxx", fs["message"])
+	assert.Equal(t, 2, fs["context.id"])
+	assert.Equal(t, "a", fs["context.name"])
+	if stack, ok := fs["stack"].(string); assert.True(t, ok) {
+		assert.Len(t, strings.Split(stack, " <- "), logrusutil.DefaultStackDepth)
+		assert.True(t, strings.HasPrefix(stack, "logrusutil_test.TestFields (logrusutil_test.go:17) <- "), stack)
+	}
+
+	assert.Equal(t, logrus.Fields{}, logrusutil.Fields(io.EOF))
+	assert.Equal(t, logrus.Fields{}, logrusutil.Fields(nil))
+}
+
+func TestHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	logger.AddHook(logrusutil.Hook{StackDepth: 1})
+
+	err := failure.New(failure.StringCode("not_found"), failure.Debug{"id": 1})
+	logger.WithError(err).WithField("code", "keep").Error("failed")
+
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, err.Error(), got["error"])
+	assert.Equal(t, "keep", got["code"])
+	assert.Equal(t, float64(1), got["context.id"])
+	assert.Equal(t, "logrusutil_test.TestHook (logrusutil_test.go:44)", got["stack"])
+
+	buf.Reset()
+	logger.Error("no error")
+	got = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.NotContains(t, got, "stack")
+}
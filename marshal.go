@@ -0,0 +1,323 @@
+package failure
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Kinds of layers in the JSON representation of an error.
+const (
+	layerCode          = "code"
+	layerMessage       = "message"
+	layerDebug         = "debug"
+	layerCallStack     = "call_stack"
+	layerRetryable     = "retryable"
+	layerSeverity      = "severity"
+	layerRetryAfter    = "retry_after"
+	layerPublicMessage = "public_message"
+	layerPayload       = "payload"
+	layerAnnotation    = "annotation"
+	layerParentStack   = "parent_stack"
+	layerGoroutines    = "goroutines"
+	layerError         = "error"
+)
+
+// SchemaVersion is the version of the JSON representation of errors
+// encoded by MarshalError.
+// Within the same version, fields are never renamed or removed, and
+// their meanings never change. New optional fields may be added.
+// The schema is described in JSON Schema at schema/error.v1.json.
+const SchemaVersion = 1
+
+// jsonError is the JSON representation of an error.
+// The summary fields are for consumers like log pipelines, and the
+// layers are for reconstructing the error by UnmarshalError.
+//
+//	{
+//	  "version": 1,
+//	  "error": "f: code(not_found): sql: no rows in result set",
+//	  "code": "not_found",
+//	  "messages": ["not found"],
+//	  "context": {"user_id": 42},
+//	  "stack": [{"path": "/main.go", "file": "main.go", "line": 10, "func": "f", "pkg": "main"}],
+//	  "layers": [
+//	    {"kind": "call_stack", "call_stack": [{"function": "main.f", "file": "/main.go", "line": 10}]},
+//	    {"kind": "debug", "debug": {"user_id": 42}},
+//	    {"kind": "message", "message": "not found"},
+//	    {"kind": "public_message", "public_message": "The user was not found."},
+//	    {"kind": "code", "code": "not_found"},
+//	    {"kind": "error", "error": "sql: no rows in result set"}
+//	  ]
+//	}
+type jsonError struct {
+	Version  int                    `json:"version"`
+	Error    string                 `json:"error"`
+	Code     string                 `json:"code,omitempty"`
+	Messages []string               `json:"messages,omitempty"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+	Stack    []jsonFrame            `json:"stack,omitempty"`
+	Layers   []jsonLayer            `json:"layers"`
+}
+
+// jsonLayer represents a layer of the error chain.
+// Only the field for the kind is set.
+type jsonLayer struct {
+	Kind          string           `json:"kind"`
+	Code          string           `json:"code,omitempty"`
+	CodeType      string           `json:"code_type,omitempty"`
+	Message       string           `json:"message,omitempty"`
+	Debug         Debug            `json:"debug,omitempty"`
+	CallStack     []jsonStackFrame `json:"call_stack,omitempty"`
+	Retryable     *bool            `json:"retryable,omitempty"`
+	Severity      *Severity        `json:"severity,omitempty"`
+	RetryAfter    *time.Duration   `json:"retry_after,omitempty"`
+	RetryAt       *time.Time       `json:"retry_at,omitempty"`
+	PublicMessage string           `json:"public_message,omitempty"`
+	Payload       interface{}      `json:"payload,omitempty"`
+	Annotation    string           `json:"annotation,omitempty"`
+	Goroutines    []jsonGoroutine  `json:"goroutines,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+type jsonStackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type jsonGoroutine struct {
+	ID        int              `json:"id"`
+	State     string           `json:"state"`
+	CallStack []jsonStackFrame `json:"call_stack"`
+}
+
+// MarshalError encodes err into JSON following the schema of
+// SchemaVersion.
+// The code, messages from the outermost, merged debug information as
+// context and the deepest call stack are encoded as summary fields.
+// The error chain is also encoded layer by layer from the outermost, so
+// that UnmarshalError can reconstruct the error.
+// Errors not created by this package are encoded by their messages.
+// Payloads appended by WithPayload are encoded by encoding/json, so an
+// error is returned if they cannot be encoded.
+func MarshalError(err error) ([]byte, error) {
+	if err == nil {
+		return nil, fmt.Errorf("failure: cannot marshal nil error")
+	}
+
+	type messageGetter interface {
+		GetMessage() string
+	}
+	type retryabilityGetter interface {
+		GetRetryability() Retryability
+	}
+	type severityGetter interface {
+		GetSeverity() Severity
+	}
+
+	je := jsonError{
+		Version: SchemaVersion,
+		Error:   err.Error(),
+	}
+	if c := CodeOf(err); c != nil {
+		je.Code = c.ErrorCode()
+	}
+	je.Context = ContextOf(err)
+	if cs := CallStackOf(err); cs != nil {
+		for _, f := range cs.Frames() {
+			je.Stack = append(je.Stack, newJSONFrame(f))
+		}
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		e := i.Error()
+		switch e.(type) {
+		case formatter, withCollector:
+			continue
+		}
+
+		var l jsonLayer
+		switch t := e.(type) {
+		case messageGetter:
+			l = jsonLayer{Kind: layerMessage, Message: t.GetMessage()}
+			je.Messages = append(je.Messages, l.Message)
+		case *withDebug:
+			l = jsonLayer{Kind: layerDebug, Debug: t.GetDebug()}
+		case retryabilityGetter:
+			r := t.GetRetryability() == Retryable
+			l = jsonLayer{Kind: layerRetryable, Retryable: &r}
+		case severityGetter:
+			s := t.GetSeverity()
+			l = jsonLayer{Kind: layerSeverity, Severity: &s}
+		case withRetryAfter:
+			l = jsonLayer{Kind: layerRetryAfter}
+			if t.at.IsZero() {
+				l.RetryAfter = &t.after
+			} else {
+				l.RetryAt = &t.at
+			}
+		case withPublicMessage:
+			l = jsonLayer{Kind: layerPublicMessage, PublicMessage: t.message}
+		case *withPayload:
+			l = jsonLayer{Kind: layerPayload, Payload: t.payload}
+		case withAnnotation:
+			l = jsonLayer{Kind: layerAnnotation, Annotation: t.annotation}
+		case withParentStack:
+			l = jsonLayer{Kind: layerParentStack, CallStack: newJSONStackFrames(t.parent)}
+		case *withGoroutines:
+			l = jsonLayer{Kind: layerGoroutines, Goroutines: make([]jsonGoroutine, len(t.goroutines))}
+			for i, g := range t.goroutines {
+				l.Goroutines[i] = jsonGoroutine{g.ID, g.State, newJSONStackFrames(g.CallStack)}
+			}
+		default:
+			if c := i.Code(); c != nil {
+				l = jsonLayer{Kind: layerCode, Code: c.ErrorCode()}
+				if _, ok := c.(IntCode); ok {
+					l.CodeType = "int"
+				}
+			} else if cs := i.CallStack(); cs != nil {
+				l = jsonLayer{Kind: layerCallStack, CallStack: newJSONStackFrames(cs)}
+			} else {
+				l = jsonLayer{Kind: layerError, Error: e.Error()}
+			}
+		}
+		je.Layers = append(je.Layers, l)
+	}
+
+	return json.Marshal(je)
+}
+
+func newJSONStackFrames(cs CallStack) []jsonStackFrame {
+	if cs == nil {
+		return nil
+	}
+	fs := cs.Frames()
+	jfs := make([]jsonStackFrame, len(fs))
+	for i, f := range fs {
+		rf := RuntimeFrameOf(f)
+		jfs[i] = jsonStackFrame{rf.Function, rf.File, rf.Line}
+	}
+	return jfs
+}
+
+func newCallStackFromJSON(jfs []jsonStackFrame) CallStack {
+	fs := make([]Frame, len(jfs))
+	for i, f := range jfs {
+		fs[i] = frame{runtime.Frame{Function: f.Function, File: f.File, Line: f.Line}}
+	}
+	return newCallStackFromFrames(fs)
+}
+
+// UnmarshalError decodes JSON encoded by MarshalError into an error.
+// Only the layers are used, and JSON of newer schema versions is
+// rejected.
+// Codes are restored as StringCode or IntCode, and values of debug
+// information and payloads are restored as JSON values like float64.
+// Errors not created by this package are restored as errors having
+// the same messages.
+func UnmarshalError(b []byte) (error, error) {
+	var je jsonError
+	if err := json.Unmarshal(b, &je); err != nil {
+		return nil, err
+	}
+	if je.Version > SchemaVersion {
+		return nil, fmt.Errorf("failure: unsupported schema version %d", je.Version)
+	}
+	if len(je.Layers) == 0 {
+		return nil, fmt.Errorf("failure: no error layer")
+	}
+
+	var err error
+	for i := len(je.Layers) - 1; i >= 0; i-- {
+		l := je.Layers[i]
+		if err == nil && l.Kind != layerCode && l.Kind != layerError {
+			return nil, fmt.Errorf("failure: the innermost layer %q cannot be restored", l.Kind)
+		}
+		switch l.Kind {
+		case layerCode:
+			var c Code = StringCode(l.Code)
+			if l.CodeType == "int" {
+				n, e := strconv.ParseInt(l.Code, 10, 64)
+				if e != nil {
+					return nil, fmt.Errorf("failure: invalid int code %q", l.Code)
+				}
+				c = IntCode(n)
+			}
+			err = Failure{c, err}
+		case layerMessage:
+			err = withMessage{err, l.Message}
+		case layerDebug:
+			err = &withDebug{err, l.Debug}
+		case layerCallStack:
+			err = withCallStack{err, newCallStackFromJSON(l.CallStack)}
+		case layerRetryable:
+			r := NotRetryable
+			if l.Retryable != nil && *l.Retryable {
+				r = Retryable
+			}
+			err = withRetryability{err, r}
+		case layerSeverity:
+			var s Severity
+			if l.Severity != nil {
+				s = *l.Severity
+			}
+			err = withSeverity{err, s}
+		case layerRetryAfter:
+			r := withRetryAfter{error: err}
+			if l.RetryAfter != nil {
+				r.after = *l.RetryAfter
+			}
+			if l.RetryAt != nil {
+				r.at = *l.RetryAt
+			}
+			err = r
+		case layerPublicMessage:
+			err = withPublicMessage{err, l.PublicMessage}
+		case layerPayload:
+			err = &withPayload{err, l.Payload}
+		case layerAnnotation:
+			err = withAnnotation{err, l.Annotation}
+		case layerParentStack:
+			var parent CallStack
+			if l.CallStack != nil {
+				parent = newCallStackFromJSON(l.CallStack)
+			}
+			err = withParentStack{err, parent}
+		case layerGoroutines:
+			gs := make([]Goroutine, len(l.Goroutines))
+			for i, g := range l.Goroutines {
+				gs[i] = Goroutine{g.ID, g.State, newCallStackFromJSON(g.CallStack)}
+			}
+			err = &withGoroutines{err, gs}
+		case layerError:
+			err = unmarshaledError{l.Error, err}
+		default:
+			return nil, fmt.Errorf("failure: unknown layer kind %q", l.Kind)
+		}
+	}
+
+	return formatter{error: err}, nil
+}
+
+// unmarshaledError is an error restored by UnmarshalError which was
+// not created by this package.
+type unmarshaledError struct {
+	message    string
+	underlying error
+}
+
+func (e unmarshaledError) Error() string {
+	return e.message
+}
+
+func (e unmarshaledError) UnwrapError() error {
+	return e.underlying
+}
+
+func (e unmarshaledError) Unwrap() error {
+	return e.underlying
+}
@@ -0,0 +1,205 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalError(t *testing.T) {
+	base := failure.Wrap(io.EOF, failure.Message("read failed"), failure.Debug{"id": "a1"})
+	err := failure.Translate(base, TestCodeA,
+		failure.Message("cannot load"),
+		failure.MarkRetryable(),
+		failure.WithSeverity(failure.SeverityWarn),
+	)
+	err = fmt.Errorf("load: %w", err)
+
+	b, e := failure.MarshalError(err)
+	assert.NoError(t, e)
+
+	got, e := failure.UnmarshalError(b)
+	assert.NoError(t, e)
+
+	assert.Equal(t, err.Error(), got.Error())
+	assert.Equal(t, TestCodeA, failure.CodeOf(got))
+	assert.True(t, failure.Is(got, TestCodeA))
+	assert.Equal(t, "cannot load", failure.MessageOf(got))
+	assert.Equal(t, []failure.Debug{{"id": "a1"}}, failure.DebugsOf(got))
+	assert.True(t, failure.IsRetryable(got))
+	assert.Equal(t, failure.SeverityWarn, failure.SeverityOf(got))
+	assert.Equal(t, io.EOF.Error(), failure.CauseOf(got).Error())
+
+	want := failure.CallStacksOf(err)
+	css := failure.CallStacksOf(got)
+	if assert.Len(t, css, len(want)) {
+		for i := range want {
+			assert.Equal(t, len(want[i].Frames()), len(css[i].Frames()))
+			wf, gf := want[i].HeadFrame(), css[i].HeadFrame()
+			assert.Equal(t, wf.Path(), gf.Path())
+			assert.Equal(t, wf.Line(), gf.Line())
+			assert.Equal(t, wf.Func(), gf.Func())
+			assert.Equal(t, failure.PkgPathOf(wf), failure.PkgPathOf(gf))
+		}
+	}
+
+	b2, e := failure.MarshalError(got)
+	assert.NoError(t, e)
+	assert.JSONEq(t, string(b), string(b2))
+}
+
+func TestMarshalError_Wrappers(t *testing.T) {
+	err := failure.Translate(io.EOF, TestCodeA,
+		failure.WithRetryAfter(time.Minute),
+		failure.WithPublicMessage("try again later"),
+		failure.WithPayload(map[string]interface{}{"limit": float64(10)}),
+		failure.WithParentStack(failure.Callers(0)),
+		failure.WithAllGoroutines(),
+	)
+	err = failure.WrapHere(err)
+
+	b, e := failure.MarshalError(err)
+	assert.NoError(t, e)
+
+	got, e := failure.UnmarshalError(b)
+	assert.NoError(t, e)
+
+	assert.Equal(t, err.Error(), got.Error())
+	d, ok := failure.RetryAfterOf(got)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, d)
+	assert.Equal(t, "try again later", failure.PublicMessageOf(got))
+	p, ok := failure.PayloadAs[map[string]interface{}](got)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"limit": float64(10)}, p)
+	assert.Equal(t, failure.Breadcrumb(err), failure.Breadcrumb(got))
+	if css := failure.ParentCallStacksOf(got); assert.Len(t, css, 1) {
+		want := failure.ParentCallStacksOf(err)[0]
+		assert.Equal(t, want.HeadFrame().Func(), css[0].HeadFrame().Func())
+		assert.Equal(t, len(want.Frames()), len(css[0].Frames()))
+	}
+	want := failure.GoroutinesOf(err)
+	gs := failure.GoroutinesOf(got)
+	if assert.Len(t, gs, len(want)) {
+		for i := range want {
+			assert.Equal(t, want[i].ID, gs[i].ID)
+			assert.Equal(t, want[i].State, gs[i].State)
+			assert.Equal(t, len(want[i].CallStack.Frames()), len(gs[i].CallStack.Frames()))
+		}
+	}
+
+	b2, e := failure.MarshalError(got)
+	assert.NoError(t, e)
+	assert.JSONEq(t, string(b), string(b2))
+}
+
+func TestMarshalError_RetryAt(t *testing.T) {
+	at := time.Now().Add(time.Hour).UTC()
+	b, e := failure.MarshalError(failure.New(TestCodeA, failure.WithRetryAt(at)))
+	assert.NoError(t, e)
+
+	got, e := failure.UnmarshalError(b)
+	assert.NoError(t, e)
+
+	d, ok := failure.RetryAfterOf(got)
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, d, float64(time.Minute))
+}
+
+func TestMarshalError_IntCode(t *testing.T) {
+	b, err := failure.MarshalError(failure.New(TestCodeB))
+	assert.NoError(t, err)
+
+	got, err := failure.UnmarshalError(b)
+	assert.NoError(t, err)
+	assert.Equal(t, TestCodeB, failure.CodeOf(got))
+}
+
+func TestMarshalError_Nil(t *testing.T) {
+	_, err := failure.MarshalError(nil)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalError_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":     `{`,
+		"no layers":    `{"layers":[]}`,
+		"unknown kind": `{"layers":[{"kind":"unknown"}]}`,
+		"no cause":     `{"layers":[{"kind":"message","message":"a"}]}`,
+		"invalid int":  `{"layers":[{"kind":"code","code":"a","code_type":"int"},{"kind":"error","error":"e"}]}`,
+	}
+
+	for title, input := range tests {
+		t.Run(title, func(t *testing.T) {
+			err, e := failure.UnmarshalError([]byte(input))
+			assert.Nil(t, err)
+			assert.Error(t, e)
+		})
+	}
+}
+
+func TestMarshalError_Schema(t *testing.T) {
+	err := failure.Translate(io.EOF, TestCodeA,
+		failure.Message("inner"),
+		failure.Debug{"user_id": 42, "name": "a"},
+	)
+	err = failure.Wrap(err, failure.Message("outer"), failure.Debug{"name": "b"})
+
+	b, e := failure.MarshalError(err)
+	assert.NoError(t, e)
+
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &got))
+
+	assert.Equal(t, float64(failure.SchemaVersion), got["version"])
+	assert.Equal(t, err.Error(), got["error"])
+	assert.Equal(t, "code_a", got["code"])
+	assert.Equal(t, []interface{}{"outer", "inner"}, got["messages"])
+	assert.Equal(t, map[string]interface{}{"user_id": float64(42), "name": "b"}, got["context"])
+	if stack, ok := got["stack"].([]interface{}); assert.True(t, ok) && assert.NotEmpty(t, stack) {
+		assert.Equal(t, "TestMarshalError_Schema", stack[0].(map[string]interface{})["func"])
+	}
+
+	// All properties must be defined in the schema.
+	f, e := os.ReadFile(filepath.Join("schema", "error.v1.json"))
+	assert.NoError(t, e)
+	var schema struct {
+		Required   []string               `json:"required"`
+		Properties map[string]interface{} `json:"properties"`
+		Defs       map[string]struct {
+			Required   []string               `json:"required"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"$defs"`
+	}
+	assert.NoError(t, json.Unmarshal(f, &schema))
+	for _, k := range schema.Required {
+		assert.Contains(t, got, k)
+	}
+	for k := range got {
+		assert.Contains(t, schema.Properties, k)
+	}
+	for _, l := range got["layers"].([]interface{}) {
+		for k := range l.(map[string]interface{}) {
+			assert.Contains(t, schema.Defs["layer"].Properties, k)
+		}
+	}
+	for k := range got["stack"].([]interface{})[0].(map[string]interface{}) {
+		assert.Contains(t, schema.Defs["frame"].Properties, k)
+	}
+}
+
+func TestUnmarshalError_Version(t *testing.T) {
+	_, e := failure.UnmarshalError([]byte(`{"version":2,"layers":[{"kind":"error","error":"e"}]}`))
+	assert.Error(t, e)
+
+	err, e := failure.UnmarshalError([]byte(`{"layers":[{"kind":"error","error":"e"}]}`))
+	assert.NoError(t, e)
+	assert.EqualError(t, err, "e")
+}
@@ -0,0 +1,120 @@
+package failure
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Messagef appends a message rendered from the template tmpl to an
+// error.
+// The template is written in text/template syntax, and rendered with
+// the debug information of the wrapped error each time the message is
+// requested, so that the message and the debug information never
+// disagree.
+// Debug information must be appended before the message to be used in
+// the template.
+//
+//	err := failure.New(NotFound,
+//		failure.Debug{"user_id": 42},
+//		failure.Messagef("user {{.user_id}} not found"),
+//	)
+//	failure.MessageOf(err) // "user 42 not found"
+//
+// If tmpl cannot be parsed or rendered, for example because a key is
+// missing in the debug information, tmpl itself is used as the message.
+func Messagef(tmpl string) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withMessageTemplate{err, tmpl}
+	})
+}
+
+type withMessageTemplate struct {
+	error
+	template string
+}
+
+func (w withMessageTemplate) UnwrapError() error {
+	return w.error
+}
+
+func (w withMessageTemplate) Unwrap() error {
+	return w.error
+}
+
+func (w withMessageTemplate) GetMessage() string {
+	return RenderMessage(w.error, w.template)
+}
+
+func (w withMessageTemplate) detail(p palette) string {
+	return p.paint(p.message, fmt.Sprintf("message(%q)", w.GetMessage()))
+}
+
+func (w withMessageTemplate) GetMessageTemplate() string {
+	return w.template
+}
+
+// MessageTemplateOf extracts the template of the message from err.
+// It returns false if the message of err is not appended by Messagef.
+// Translation layers can use it to render a translated template with
+// RenderMessage.
+func MessageTemplateOf(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+
+	type messageGetter interface {
+		GetMessage() string
+	}
+	type messageTemplateGetter interface {
+		GetMessageTemplate() string
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		switch t := i.Error().(type) {
+		case messageTemplateGetter:
+			return t.GetMessageTemplate(), true
+		case messageGetter:
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+// RenderMessage renders the template tmpl with the debug information
+// of err.
+// If the same key is appended more than once, the outermost one is used.
+// If tmpl cannot be parsed or rendered, including when it refers to a
+// key not in the debug information, tmpl itself is returned.
+func RenderMessage(err error, tmpl string) string {
+	t, e := template.New("message").Option("missingkey=error").Parse(tmpl)
+	if e != nil {
+		return tmpl
+	}
+
+	ctx := ContextOf(err)
+	if ctx == nil {
+		ctx = map[string]interface{}{}
+	}
+	var sb strings.Builder
+	if e := t.Execute(&sb, ctx); e != nil {
+		return tmpl
+	}
+	return sb.String()
+}
+
+// mergeDebugs merges debugs into one.
+// If the same key appears more than once, the first one is used.
+func mergeDebugs(debugs []Debug) map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, d := range debugs {
+		for k, v := range d {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,82 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagef(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		wantMessage  string
+		wantTemplate string
+		wantOK       bool
+	}{
+		"render": {
+			failure.New(TestCodeA, failure.Debug{"user_id": 42}, failure.Messagef("user {{.user_id}} not found")),
+			"user 42 not found", "user {{.user_id}} not found", true,
+		},
+		"outer debug wins": {
+			failure.Wrap(
+				failure.New(TestCodeA, failure.Debug{"id": 1}),
+				failure.Debug{"id": 2},
+				failure.Messagef("id={{.id}}"),
+			),
+			"id=2", "id={{.id}}", true,
+		},
+		"debug appended later is not used": {
+			failure.Wrap(
+				failure.New(TestCodeA, failure.Messagef("id={{.id}}")),
+				failure.Debug{"id": 1},
+			),
+			"id={{.id}}", "id={{.id}}", true,
+		},
+		"invalid template": {
+			failure.New(TestCodeA, failure.Messagef("{{.id")),
+			"{{.id", "{{.id", true,
+		},
+		"plain message": {
+			failure.Wrap(
+				failure.New(TestCodeA, failure.Messagef("a")),
+				failure.Message("b"),
+			),
+			"b", "", false,
+		},
+		"no message": {io.EOF, "", "", false},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.wantMessage, failure.MessageOf(test.err))
+			tmpl, ok := failure.MessageTemplateOf(test.err)
+			assert.Equal(t, test.wantTemplate, tmpl)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
+
+func TestMessagef_Format(t *testing.T) {
+	err := failure.New(TestCodeA, failure.Debug{"user_id": 42}, failure.Messagef("user {{.user_id}} not found"))
+	assert.Contains(t, fmt.Sprintf("%+v", err), `message("user 42 not found")`)
+}
+
+func TestRenderMessage(t *testing.T) {
+	err := failure.New(TestCodeA, failure.Debug{"user_id": 42}, failure.Messagef("user {{.user_id}} not found"))
+	tmpl, _ := failure.MessageTemplateOf(err)
+	assert.Equal(t, "user 42 not found", failure.RenderMessage(err, tmpl))
+	assert.Equal(t, "ユーザー 42 が見つかりません", failure.RenderMessage(err, "ユーザー {{.user_id}} が見つかりません"))
+}
+
+func TestRenderMessage_MissingKey(t *testing.T) {
+	tmpl := "user {{.user_id}} not found"
+
+	assert.Equal(t, tmpl, failure.RenderMessage(failure.New(TestCodeA), tmpl))
+	assert.Equal(t, tmpl, failure.RenderMessage(failure.New(TestCodeA, failure.Debug{"id": 1}), tmpl))
+	assert.Equal(t, tmpl, failure.RenderMessage(nil, tmpl))
+	assert.Equal(t, "no key", failure.RenderMessage(failure.New(TestCodeA), "no key"))
+}
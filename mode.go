@@ -0,0 +1,133 @@
+package failure
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// CallStackMode represents how %+v prints call stacks of an error.
+type CallStackMode int32
+
+// CallStackMode values.
+const (
+	// CallStackModeDeepest prints only the deepest call stack.
+	CallStackModeDeepest CallStackMode = iota
+	// CallStackModeDelta prints call stacks of all the wrapped
+	// layers from the outermost.
+	// Frames shared with the outer call stack are omitted, and only
+	// the number of them is printed.
+	CallStackModeDelta
+)
+
+var callStackMode int32
+
+// SetCallStackMode sets how %+v prints call stacks.
+// The default is CallStackModeDeepest.
+// It is safe to call SetCallStackMode concurrently.
+func SetCallStackMode(m CallStackMode) {
+	atomic.StoreInt32(&callStackMode, int32(m))
+}
+
+func callStackModeOf() CallStackMode {
+	return CallStackMode(atomic.LoadInt32(&callStackMode))
+}
+
+// FrameStyle represents how %+v prints a frame.
+type FrameStyle int32
+
+// FrameStyle values.
+const (
+	// FrameStyleDefault prints a frame like "[Func] /path/to/file.go:12".
+	FrameStyleDefault FrameStyle = iota
+	// FrameStyleIDE prints a frame like "/path/to/file.go:12:1: Func"
+	// as compilers print locations, so that terminals of editors make
+	// it a link to the source.
+	FrameStyleIDE
+	// FrameStyleIDERelative is FrameStyleIDE with paths relative to
+	// the working directory at the time of SetFrameStyle, which
+	// GoLand and VSCode resolve against the project root.
+	// Paths outside of the directory are printed as they are.
+	FrameStyleIDERelative
+)
+
+type frameStyleHolder struct {
+	style FrameStyle
+	dir   string
+}
+
+var frameStyle atomic.Value // frameStyleHolder
+
+// SetFrameStyle sets how %+v prints frames.
+// The default is FrameStyleDefault.
+// It is safe to call SetFrameStyle concurrently.
+func SetFrameStyle(s FrameStyle) {
+	h := frameStyleHolder{style: s}
+	if s == FrameStyleIDERelative {
+		h.dir, _ = os.Getwd()
+	}
+	frameStyle.Store(h)
+}
+
+func frameStyleOf() frameStyleHolder {
+	h, _ := frameStyle.Load().(frameStyleHolder)
+	return h
+}
+
+// formatFrame writes f in the FrameStyle.
+func formatFrame(w io.Writer, f Frame) {
+	h := frameStyleOf()
+	switch h.style {
+	case FrameStyleIDE, FrameStyleIDERelative:
+		p := f.Path()
+		if h.dir != "" && filepath.IsAbs(p) {
+			if rel, err := filepath.Rel(h.dir, p); err == nil && !strings.HasPrefix(rel, "..") {
+				p = rel
+			}
+		}
+		fmt.Fprintf(w, "%s:%d:1: %s", p, f.Line(), f.Func())
+	default:
+		fmt.Fprintf(w, "[%s] %s:%d", f.Func(), f.Path(), f.Line())
+	}
+}
+
+// DeltaFrames returns frames of cs which are not shared with outer.
+// outer is a call stack captured later than cs in the same
+// goroutine, like the call stack of a wrapping error.
+// The shared frames are the common tail of both call stacks, and
+// the number of them is returned as well.
+func DeltaFrames(cs, outer CallStack) ([]Frame, int) {
+	fs := cs.Frames()
+	if outer == nil {
+		return fs, 0
+	}
+
+	ofs := outer.Frames()
+	n := 0
+	for n < len(fs) && n < len(ofs) && sameFrame(fs[len(fs)-1-n], ofs[len(ofs)-1-n]) {
+		n++
+	}
+	return fs[:len(fs)-n], n
+}
+
+func sameFrame(a, b Frame) bool {
+	return a.Path() == b.Path() && a.Line() == b.Line() && a.Func() == b.Func()
+}
+
+func writeDeltaCallStacks(w io.Writer, css []CallStack, p palette) {
+	var outer CallStack
+	for _, cs := range css {
+		fmt.Fprint(w, "[CallStack]\n")
+		fs, n := DeltaFrames(cs, outer)
+		for _, f := range fs {
+			fmt.Fprintf(w, "    %s\n", p.frame(f))
+		}
+		if n > 0 {
+			fmt.Fprintf(w, "    ... %d more\n", n)
+		}
+		outer = cs
+	}
+}
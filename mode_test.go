@@ -0,0 +1,97 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func deltaInner() error {
+	return failure.Translate(io.EOF, TestCodeA)
+}
+
+func deltaOuter() error {
+	err := deltaInner()
+	return failure.Wrap(err)
+}
+
+func TestCallStacksOf(t *testing.T) {
+	err := deltaOuter()
+
+	css := failure.CallStacksOf(err)
+	if assert.Len(t, css, 2) {
+		assert.Equal(t, "deltaOuter", css[0].HeadFrame().Func())
+		assert.Equal(t, "deltaInner", css[1].HeadFrame().Func())
+	}
+	assert.Equal(t, css[1], failure.CallStackOf(err))
+
+	assert.Nil(t, failure.CallStacksOf(io.EOF))
+	assert.Nil(t, failure.CallStacksOf(nil))
+}
+
+func TestDeltaFrames(t *testing.T) {
+	css := failure.CallStacksOf(deltaOuter())
+
+	fs, n := failure.DeltaFrames(css[1], css[0])
+	if assert.Len(t, fs, 2) {
+		assert.Equal(t, "deltaInner", fs[0].Func())
+		assert.Equal(t, "deltaOuter", fs[1].Func())
+	}
+	assert.Equal(t, len(css[1].Frames())-2, n)
+
+	fs, n = failure.DeltaFrames(css[0], nil)
+	assert.Equal(t, css[0].Frames(), fs)
+	assert.Equal(t, 0, n)
+}
+
+func TestSetCallStackMode(t *testing.T) {
+	defer failure.SetCallStackMode(failure.CallStackModeDeepest)
+	failure.SetCallStackMode(failure.CallStackModeDelta)
+
+	err := deltaOuter()
+
+	assert.Regexp(t, `^\[deltaOuter\] /.+/mode_test.go:20
+\[deltaInner\] /.+/mode_test.go:15
+    code\(code_a\)
+    error\("EOF"\)
+\[CallStack\]
+    \[deltaOuter\] /.+/mode_test.go:20
+    \[TestSetCallStackMode\] /.+/mode_test.go:56
+(    .+\n)+\[CallStack\]
+    \[deltaInner\] /.+/mode_test.go:15
+    \[deltaOuter\] /.+/mode_test.go:19
+    \.\.\. \d+ more
+$`, fmt.Sprintf("%+v", err))
+}
+
+func TestSetFrameStyle(t *testing.T) {
+	defer failure.SetFrameStyle(failure.FrameStyleDefault)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := failure.NewCallStackFromFrames(
+		failure.NewFrame("main.f", filepath.Join(wd, "sub", "main.go"), 12),
+		failure.NewFrame("main.main", "/outside/main.go", 5),
+	)
+
+	assert.Equal(t, "[f] "+filepath.Join(wd, "sub", "main.go")+":12\n[main] /outside/main.go:5\n", fmt.Sprintf("%+v", cs))
+
+	failure.SetFrameStyle(failure.FrameStyleIDE)
+	assert.Equal(t, filepath.Join(wd, "sub", "main.go")+":12:1: f\n/outside/main.go:5:1: main\n", fmt.Sprintf("%+v", cs))
+	if parsed := failure.ParseStack(fmt.Sprintf("%+v", cs)); assert.NotNil(t, parsed) {
+		f := parsed.HeadFrame()
+		assert.Equal(t, "f", f.Func())
+		assert.Equal(t, filepath.Join(wd, "sub", "main.go"), f.Path())
+		assert.Equal(t, 12, f.Line())
+	}
+
+	failure.SetFrameStyle(failure.FrameStyleIDERelative)
+	assert.Equal(t, filepath.Join("sub", "main.go")+":12:1: f\n/outside/main.go:5:1: main\n", fmt.Sprintf("%+v", cs))
+}
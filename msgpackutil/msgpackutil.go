@@ -0,0 +1,81 @@
+// Package msgpackutil encodes errors of the failure package in
+// MessagePack, which is much smaller than JSON for shipping a large
+// number of errors.
+//
+// The encoded value has the same structure as the JSON encoded by
+// failure.MarshalError, so it can be decoded by any MessagePack
+// library following schema/error.v1.json.
+package msgpackutil
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/morikuni/failure"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Marshal encodes err into MessagePack.
+func Marshal(err error) ([]byte, error) {
+	b, err := failure.MarshalError(err)
+	if err != nil {
+		return nil, err
+	}
+	return fromJSON(b)
+}
+
+// MarshalCallStack encodes cs into MessagePack in the same structure as
+// the JSON encoded by json.Marshal.
+func MarshalCallStack(cs failure.CallStack) ([]byte, error) {
+	b, err := json.Marshal(cs)
+	if err != nil {
+		return nil, err
+	}
+	return fromJSON(b)
+}
+
+// Unmarshal decodes MessagePack encoded by Marshal into an error in the
+// same way as failure.UnmarshalError.
+func Unmarshal(b []byte) (error, error) {
+	var v interface{}
+	if err := msgpack.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	jb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return failure.UnmarshalError(jb)
+}
+
+func fromJSON(b []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(compactNumbers(v))
+}
+
+// compactNumbers converts json.Number in v into int64 if possible,
+// which is encoded smaller than float64.
+func compactNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = compactNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = compactNumbers(e)
+		}
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package msgpackutil_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/msgpackutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func TestMarshal(t *testing.T) {
+	err := failure.Translate(io.EOF, NotFound,
+		failure.Message("cannot load"),
+		failure.Debug{"id": "a1", "n": 3, "ratio": 0.5},
+		failure.MarkRetryable(),
+	)
+
+	b, e := msgpackutil.Marshal(err)
+	assert.NoError(t, e)
+
+	jb, _ := failure.MarshalError(err)
+	assert.True(t, len(b) < len(jb), "%d < %d", len(b), len(jb))
+
+	got, e := msgpackutil.Unmarshal(b)
+	assert.NoError(t, e)
+	assert.Equal(t, err.Error(), got.Error())
+	assert.Equal(t, NotFound, failure.CodeOf(got))
+	assert.Equal(t, "cannot load", failure.MessageOf(got))
+	assert.Equal(t, []failure.Debug{{"id": "a1", "n": float64(3), "ratio": 0.5}}, failure.DebugsOf(got))
+	assert.True(t, failure.IsRetryable(got))
+
+	want, _ := failure.MarshalError(err)
+	gb, _ := failure.MarshalError(got)
+	assert.JSONEq(t, string(want), string(gb))
+
+	_, e = msgpackutil.Marshal(nil)
+	assert.Error(t, e)
+	_, e = msgpackutil.Unmarshal([]byte{0xc1})
+	assert.Error(t, e)
+}
+
+func TestMarshalCallStack(t *testing.T) {
+	cs := failure.NewCallStackFromFrames(failure.NewFrame("main.f", "/src/main.go", 12))
+
+	b, err := msgpackutil.MarshalCallStack(cs)
+	assert.NoError(t, err)
+
+	var got []map[string]interface{}
+	assert.NoError(t, msgpack.Unmarshal(b, &got))
+
+	jb, _ := json.Marshal(cs)
+	var want []map[string]interface{}
+	json.Unmarshal(jb, &want)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "/src/main.go", got[0]["path"])
+		assert.EqualValues(t, 12, got[0]["line"])
+		assert.Equal(t, want[0]["func"], got[0]["func"])
+	}
+}
@@ -0,0 +1,85 @@
+package failure
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Error codes used by FromNetError.
+const (
+	NetworkTimeoutCode  StringCode = "failure.network_timeout"
+	ConnectionResetCode StringCode = "failure.connection_reset"
+	DNSFailureCode      StringCode = "failure.dns_failure"
+	TLSVerificationCode StringCode = "failure.tls_verification"
+)
+
+// FromNetError translates err caused by the network into an error with
+// one of the following codes. Other errors are returned as they are.
+//
+//   - TLSVerificationCode for certificates failed to be verified.
+//   - DNSFailureCode for *net.DNSError.
+//   - ConnectionResetCode for ECONNRESET and EPIPE.
+//   - NetworkTimeoutCode for net.Error timed out.
+//
+// The syscall errno, the operation and the remote address of
+// *net.OpError are appended as debug information keyed by "errno",
+// "op" and "addr".
+//
+//	if _, err := conn.Read(buf); err != nil {
+//		return failure.FromNetError(err)
+//	}
+func FromNetError(err error) error {
+	code := classifyNetError(err)
+	if code == nil {
+		return err
+	}
+
+	debug := Debug{}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		debug["errno"] = errno
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		debug["op"] = opErr.Op
+		if opErr.Addr != nil {
+			debug["addr"] = opErr.Addr.String()
+		}
+	}
+
+	wrappers := []Wrapper{WithCallStackSkip(1)}
+	if len(debug) != 0 {
+		wrappers = append(wrappers, debug)
+	}
+	return Translate(err, code, wrappers...)
+}
+
+func classifyNetError(err error) Code {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		dnsErr       *net.DNSError
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &verifyErr),
+		errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr):
+		return TLSVerificationCode
+	case errors.As(err, &dnsErr):
+		return DNSFailureCode
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ConnectionResetCode
+	case errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return NetworkTimeoutCode
+	}
+	return nil
+}
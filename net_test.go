@@ -0,0 +1,59 @@
+package failure_test
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromNetError(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 80}
+	tests := map[string]struct {
+		err  error
+		want failure.Code
+	}{
+		"tls": {
+			x509.UnknownAuthorityError{},
+			failure.TLSVerificationCode,
+		},
+		"dns": {
+			&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "example.invalid"}},
+			failure.DNSFailureCode,
+		},
+		"reset": {
+			&net.OpError{Op: "read", Addr: addr, Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			failure.ConnectionResetCode,
+		},
+		"timeout": {
+			&net.OpError{Op: "read", Addr: addr, Err: os.ErrDeadlineExceeded},
+			failure.NetworkTimeoutCode,
+		},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			err := failure.FromNetError(test.err)
+			assert.Equal(t, test.want, failure.CodeOf(err))
+			assert.True(t, errors.Is(err, test.err))
+			assert.Equal(t, "TestFromNetError.func1", failure.CallStackOf(err).HeadFrame().Func())
+		})
+	}
+
+	err := failure.FromNetError(tests["reset"].err)
+	errno, _ := failure.ValueOf(err, "errno")
+	assert.Equal(t, syscall.ECONNRESET, errno)
+	op, _ := failure.ValueOf(err, "op")
+	assert.Equal(t, "read", op)
+	a, _ := failure.ValueOf(err, "addr")
+	assert.Equal(t, "127.0.0.1:80", a)
+
+	assert.Equal(t, io.EOF, failure.FromNetError(io.EOF))
+	assert.Nil(t, failure.FromNetError(nil))
+}
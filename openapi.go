@@ -0,0 +1,117 @@
+package failure
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIComponents returns the components section of OpenAPI 3 for
+// the error responses written by HTTPErrorWriter, so that API docs
+// match the responses actually written.
+// It has the "Error" schema and a response per HTTP status of codes,
+// which is named after the status text like "NotFound" and has an
+// example per code. Codes without HTTP status are put in the response
+// of http.StatusInternalServerError as HTTPStatusOf does.
+// The result can be encoded in JSON or YAML.
+//
+//	b, _ := json.Marshal(map[string]interface{}{
+//		"components": failure.OpenAPIComponents(failure.Codes()),
+//	})
+func OpenAPIComponents(codes []CodeInfo) map[string]interface{} {
+	byStatus := make(map[int][]CodeInfo)
+	var enum []string
+	for _, c := range codes {
+		s := c.HTTPStatus
+		if s == 0 {
+			s = http.StatusInternalServerError
+		}
+		byStatus[s] = append(byStatus[s], c)
+		enum = append(enum, c.Code.ErrorCode())
+	}
+	sort.Strings(enum)
+
+	code := map[string]interface{}{"type": "string"}
+	if len(enum) != 0 {
+		code["enum"] = enum
+	}
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"message"},
+			"properties": map[string]interface{}{
+				"code":    code,
+				"message": map[string]interface{}{"type": "string"},
+				"stack": map[string]interface{}{
+					"type":        "array",
+					"description": "Call stack written only if HTTPErrorWriter.IncludeCallStack is enabled.",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{"type": "string"},
+							"file": map[string]interface{}{"type": "string"},
+							"line": map[string]interface{}{"type": "integer"},
+							"func": map[string]interface{}{"type": "string"},
+							"pkg":  map[string]interface{}{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	responses := make(map[string]interface{})
+	for status, cs := range byStatus {
+		examples := make(map[string]interface{})
+		retryable := false
+		for _, c := range cs {
+			examples[c.Code.ErrorCode()] = map[string]interface{}{
+				"summary": c.Description,
+				"value": map[string]interface{}{
+					"code":    c.Code.ErrorCode(),
+					"message": http.StatusText(status),
+				},
+			}
+			retryable = retryable || c.Retryable
+		}
+
+		res := map[string]interface{}{
+			"description": statusDescription(status),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema":   map[string]interface{}{"$ref": "#/components/schemas/Error"},
+					"examples": examples,
+				},
+			},
+		}
+		if retryable {
+			res["headers"] = map[string]interface{}{
+				"Retry-After": map[string]interface{}{
+					"description": "Seconds to wait before retrying.",
+					"schema":      map[string]interface{}{"type": "integer"},
+				},
+			}
+		}
+		responses[statusResponseName(status)] = res
+	}
+
+	return map[string]interface{}{
+		"schemas":   schemas,
+		"responses": responses,
+	}
+}
+
+func statusDescription(status int) string {
+	if t := http.StatusText(status); t != "" {
+		return t
+	}
+	return "Status " + strconv.Itoa(status)
+}
+
+func statusResponseName(status int) string {
+	if t := http.StatusText(status); t != "" {
+		return strings.NewReplacer(" ", "", "-", "", "'", "").Replace(t)
+	}
+	return "Status" + strconv.Itoa(status)
+}
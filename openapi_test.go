@@ -0,0 +1,64 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIComponents(t *testing.T) {
+	codes := []failure.CodeInfo{
+		{failure.StringCode("not_found"), "The resource does not exist.", http.StatusNotFound, false},
+		{failure.StringCode("gone"), "The resource was deleted.", http.StatusNotFound, false},
+		{failure.StringCode("unavailable"), "The service is unavailable.", http.StatusServiceUnavailable, true},
+		{failure.StringCode("internal"), "Something went wrong.", 0, false},
+	}
+
+	b, err := json.Marshal(failure.OpenAPIComponents(codes))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var got struct {
+		Schemas struct {
+			Error struct {
+				Properties struct {
+					Code struct {
+						Enum []string `json:"enum"`
+					} `json:"code"`
+				} `json:"properties"`
+			}
+		} `json:"schemas"`
+		Responses map[string]struct {
+			Description string                 `json:"description"`
+			Headers     map[string]interface{} `json:"headers"`
+			Content     struct {
+				JSON struct {
+					Schema   map[string]string `json:"schema"`
+					Examples map[string]struct {
+						Summary string            `json:"summary"`
+						Value   map[string]string `json:"value"`
+					} `json:"examples"`
+				} `json:"application/json"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+	assert.NoError(t, json.Unmarshal(b, &got))
+
+	assert.Equal(t, []string{"gone", "internal", "not_found", "unavailable"}, got.Schemas.Error.Properties.Code.Enum)
+	assert.Len(t, got.Responses, 3)
+
+	nf := got.Responses["NotFound"]
+	assert.Equal(t, "Not Found", nf.Description)
+	assert.Equal(t, "#/components/schemas/Error", nf.Content.JSON.Schema["$ref"])
+	assert.Len(t, nf.Content.JSON.Examples, 2)
+	assert.Equal(t, "The resource was deleted.", nf.Content.JSON.Examples["gone"].Summary)
+	assert.Equal(t, map[string]string{"code": "gone", "message": "Not Found"}, nf.Content.JSON.Examples["gone"].Value)
+	assert.Nil(t, nf.Headers)
+
+	assert.Contains(t, got.Responses["ServiceUnavailable"].Headers, "Retry-After")
+	assert.Contains(t, got.Responses["InternalServerError"].Content.JSON.Examples, "internal")
+}
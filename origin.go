@@ -0,0 +1,75 @@
+package failure
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// Origin represents where the code of a frame comes from.
+type Origin int
+
+// Origin values.
+const (
+	// OriginApp is the code of the main module.
+	OriginApp Origin = iota + 1
+	// OriginDependency is the code of a module the main module
+	// depends on.
+	OriginDependency
+	// OriginStdlib is the code of the standard library.
+	OriginStdlib
+)
+
+// String implements the fmt.Stringer interface.
+func (o Origin) String() string {
+	switch o {
+	case OriginApp:
+		return "app"
+	case OriginDependency:
+		return "dependency"
+	case OriginStdlib:
+		return "stdlib"
+	}
+	return "unknown"
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModule     string
+)
+
+func mainModulePath() string {
+	mainModuleOnce.Do(func() {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			mainModule = bi.Main.Path
+		}
+	})
+	return mainModule
+}
+
+// OriginOf returns whether the function of f belongs to the main
+// module, a dependency or the standard library.
+func OriginOf(f Frame) Origin {
+	return pkgOrigin(PkgPathOf(f))
+}
+
+// pkgOrigin returns the Origin of the package.
+// Packages of the main module, and the main package built without
+// module, are OriginApp. Other packages whose first path element has
+// no dot are OriginStdlib.
+func pkgOrigin(pkgPath string) Origin {
+	switch pkgPath {
+	case "main", "command-line-arguments":
+		return OriginApp
+	}
+	if m := mainModulePath(); m != "" {
+		if pkgPath == m || strings.HasPrefix(pkgPath, m+"/") || strings.HasPrefix(pkgPath, m+"_test") {
+			return OriginApp
+		}
+	}
+	first, _, _ := strings.Cut(pkgPath, "/")
+	if !strings.Contains(first, ".") {
+		return OriginStdlib
+	}
+	return OriginDependency
+}
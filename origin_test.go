@@ -0,0 +1,58 @@
+package failure_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginOf(t *testing.T) {
+	tests := map[string]struct {
+		function string
+
+		want failure.Origin
+	}{
+		"main module":      {"github.com/morikuni/failure.Wrap", failure.OriginApp},
+		"main module test": {"github.com/morikuni/failure_test.X", failure.OriginApp},
+		"sub package":      {"github.com/morikuni/failure/slogutil.Log", failure.OriginApp},
+		"main package":     {"main.main", failure.OriginApp},
+		"dependency":       {"github.com/pkg/errors.New", failure.OriginDependency},
+		"similar path":     {"github.com/morikuni/failurex.New", failure.OriginDependency},
+		"stdlib":           {"net/http.(*Client).Do", failure.OriginStdlib},
+		"runtime":          {"runtime.goexit", failure.OriginStdlib},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			f := newTestCallStack(test.function + " /a.go:1").HeadFrame()
+			assert.Equal(t, test.want, failure.OriginOf(f))
+		})
+	}
+
+	assert.Equal(t, failure.OriginApp, failure.OriginOf(failure.Callers(0).HeadFrame()))
+}
+
+func TestOrigin_String(t *testing.T) {
+	assert.Equal(t, "app", failure.OriginApp.String())
+	assert.Equal(t, "dependency", failure.OriginDependency.String())
+	assert.Equal(t, "stdlib", failure.OriginStdlib.String())
+	assert.Equal(t, "unknown", failure.Origin(0).String())
+}
+
+func TestCallStack_Format_App(t *testing.T) {
+	cs := newTestCallStack(
+		"database/sql.(*DB).Query /a.go:1",
+		"github.com/pkg/errors.New /b.go:2",
+		"github.com/morikuni/failure_test.X /c.go:3",
+		"main.main /d.go:4",
+	)
+	assert.Equal(t, "(*DB).Query: New: [X]: main", fmt.Sprintf("%v", cs))
+
+	cs = newTestCallStack("github.com/morikuni/failure_test.X /c.go:3", "main.main /d.go:4")
+	assert.Equal(t, "X: main", fmt.Sprintf("%v", cs))
+
+	cs = newTestCallStack("net/http.Get /a.go:1")
+	assert.Equal(t, "Get", fmt.Sprintf("%v", cs))
+}
@@ -0,0 +1,76 @@
+// Package otelutil records errors of the failure package on
+// OpenTelemetry spans.
+package otelutil
+
+import (
+	"fmt"
+
+	"github.com/morikuni/failure"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CodeKey is the attribute key of the failure code.
+const CodeKey = attribute.Key("failure.code")
+
+// DebugKeyPrefix is the prefix of attribute keys of debug information.
+const DebugKeyPrefix = "failure.debug."
+
+// RecordError records err on span.
+//
+// The span status is set to codes.Error described by the failure code,
+// or by the error message if err has no code.
+// An exception event is added following the semantic conventions,
+// where exception.stacktrace is the call stack of err instead of the
+// current goroutine.
+// The failure code and debug information are set as span attributes.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	code := failure.CodeOf(err)
+	if code != nil {
+		span.SetStatus(codes.Error, code.ErrorCode())
+	} else {
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(fmt.Sprintf("%T", failure.CauseOf(err))),
+		semconv.ExceptionMessage(err.Error()),
+	}
+	if cs := failure.CallStackOf(err); cs != nil {
+		attrs = append(attrs, semconv.ExceptionStacktrace(fmt.Sprintf("%+v", cs)))
+	}
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(attrs...))
+
+	attrs = attrs[:0]
+	if code != nil {
+		attrs = append(attrs, CodeKey.String(code.ErrorCode()))
+	}
+	for k, v := range failure.ContextOf(err) {
+		attrs = append(attrs, debugAttribute(DebugKeyPrefix+k, v))
+	}
+	span.SetAttributes(attrs...)
+}
+
+func debugAttribute(key string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.Stringer(key, v)
+	}
+	return attribute.String(key, fmt.Sprint(v))
+}
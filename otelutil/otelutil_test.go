@@ -0,0 +1,80 @@
+package otelutil_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/otelutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func record(err error) sdktrace.ReadOnlySpan {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	_, span := tp.Tracer("test").Start(context.Background(), "test")
+	otelutil.RecordError(span, err)
+	span.End()
+	return sr.Ended()[0]
+}
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestRecordError(t *testing.T) {
+	err := failure.Translate(io.EOF, NotFound,
+		failure.Debug{"user_id": 42, "name": "foo", "ok": true},
+	)
+	err = failure.Wrap(err, failure.Debug{"name": "bar"})
+
+	span := record(err)
+
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "not_found", span.Status().Description)
+
+	attrs := attributes(span.Attributes())
+	assert.Equal(t, "not_found", attrs["failure.code"].AsString())
+	assert.Equal(t, int64(42), attrs["failure.debug.user_id"].AsInt64())
+	assert.Equal(t, "bar", attrs["failure.debug.name"].AsString())
+	assert.Equal(t, true, attrs["failure.debug.ok"].AsBool())
+
+	if assert.Len(t, span.Events(), 1) {
+		ev := span.Events()[0]
+		assert.Equal(t, "exception", ev.Name)
+		attrs := attributes(ev.Attributes)
+		assert.Equal(t, "*errors.errorString", attrs["exception.type"].AsString())
+		assert.Equal(t, err.Error(), attrs["exception.message"].AsString())
+		assert.Contains(t, attrs["exception.stacktrace"].AsString(), "[TestRecordError]")
+	}
+}
+
+func TestRecordError_NoCode(t *testing.T) {
+	span := record(io.EOF)
+
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "EOF", span.Status().Description)
+	assert.Empty(t, span.Attributes())
+	if assert.Len(t, span.Events(), 1) {
+		_, ok := attributes(span.Events()[0].Attributes)["exception.stacktrace"]
+		assert.False(t, ok)
+	}
+}
+
+func TestRecordError_Nil(t *testing.T) {
+	span := record(nil)
+
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	assert.Empty(t, span.Events())
+}
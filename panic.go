@@ -0,0 +1,77 @@
+package failure
+
+import "fmt"
+
+// PanicCode is the error code used by RecoverFunc.
+const PanicCode StringCode = "failure.panic"
+
+// Recover converts a panic into an error with given code and stores
+// it to errp.
+// It must be called directly by defer statement.
+//
+//	func f() (err error) {
+//		defer failure.Recover(&err, PanicCode)
+//		...
+//	}
+//
+// The call stack of the error is the one where the panic occurred,
+// not where it was recovered.
+// The recovered value is appended as debug information keyed by
+// "panic".
+// Hooks registered by RegisterHook are called with the error like New.
+func Recover(errp *error, code Code, wrappers ...Wrapper) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	*errp = newPanicError(r, code, panicCallers(), wrappers)
+}
+
+// RecoverFunc calls f and converts a panic in f into an error with
+// PanicCode.
+func RecoverFunc(f func() error) (err error) {
+	defer Recover(&err, PanicCode)
+	return f()
+}
+
+func newPanicError(r interface{}, code Code, cs CallStack, wrappers []Wrapper) error {
+	cause, ok := r.(error)
+	if !ok {
+		cause = fmt.Errorf("panic: %v", r)
+	}
+
+	f := Failure{
+		code,
+		cause,
+	}
+	err := Custom(f, append(wrappers, Debug{"panic": r}, WithCallStack(cs), WithFormatter())...)
+	runHooks(err)
+	runDeprecatedCodeHook(err, code)
+	return err
+}
+
+// panicCallers returns the call stack where the current panic
+// occurred.
+// It drops the frames of the deferred calls and the runtime panic
+// handling from the call stack of the caller.
+func panicCallers() CallStack {
+	cs := callers(2, MaxStackDepth())
+	if cs == nil {
+		return nil
+	}
+
+	fs := cs.Frames()
+	for i, f := range fs {
+		if RuntimeFrameOf(f).Function != "runtime.gopanic" {
+			continue
+		}
+		fs = fs[i+1:]
+		// drop runtime frames raising the panic like runtime.sigpanic.
+		for len(fs) > 0 && PkgPathOf(fs[0]) == "runtime" {
+			fs = fs[1:]
+		}
+		break
+	}
+	return newCallStackFromFrames(fs)
+}
@@ -0,0 +1,94 @@
+package failure_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func panicWith(v interface{}) {
+	panic(v)
+}
+
+func nilDeref() int {
+	var p *int
+	return *p
+}
+
+func recoverPanic(v interface{}) (err error) {
+	defer failure.Recover(&err, TestCodeA, failure.Message("xxx"))
+	panicWith(v)
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	err := recoverPanic("boom")
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "xxx", failure.MessageOf(err))
+	assert.EqualError(t, err, "panicWith: code(code_a): panic: boom")
+	assert.Equal(t, []failure.Debug{{"panic": "boom"}}, failure.DebugsOf(err))
+
+	fs := failure.CallStackOf(err).Frames()
+	if assert.True(t, len(fs) >= 3) {
+		assert.Equal(t, "panicWith", fs[0].Func())
+		assert.Equal(t, 13, fs[0].Line())
+		assert.Equal(t, "recoverPanic", fs[1].Func())
+		assert.Equal(t, "TestRecover", fs[2].Func())
+	}
+}
+
+func TestRecover_Error(t *testing.T) {
+	err := recoverPanic(io.EOF)
+
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, io.EOF, failure.CauseOf(err))
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	err := func() (err error) {
+		defer failure.Recover(&err, TestCodeA)
+		return io.EOF
+	}()
+
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRecoverFunc(t *testing.T) {
+	err := failure.RecoverFunc(func() error {
+		nilDeref()
+		return nil
+	})
+
+	assert.Equal(t, failure.PanicCode, failure.CodeOf(err))
+	fs := failure.CallStackOf(err).Frames()
+	if assert.NotEmpty(t, fs) {
+		assert.Equal(t, "nilDeref", fs[0].Func())
+		assert.Equal(t, 18, fs[0].Line())
+	}
+
+	assert.NoError(t, failure.RecoverFunc(func() error { return nil }))
+	assert.Equal(t, io.EOF, failure.RecoverFunc(func() error { return io.EOF }))
+}
+
+func TestRecover_Hook(t *testing.T) {
+	const code failure.StringCode = "panic_hook_test"
+
+	var got []error
+	failure.RegisterHook(failure.HookFunc(func(err error) {
+		if failure.CodeOf(err) == code {
+			got = append(got, err)
+		}
+	}))
+
+	err := func() (err error) {
+		defer failure.Recover(&err, code)
+		panicWith("boom")
+		return nil
+	}()
+
+	assert.Equal(t, []error{err}, got)
+}
@@ -0,0 +1,133 @@
+package failure
+
+import (
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// PathTrimmer rewrites a file path of a frame.
+// It is used to hide absolute paths of the build machine.
+type PathTrimmer func(path string) string
+
+type pathTrimmerHolder struct {
+	trim PathTrimmer
+}
+
+var pathTrimmer atomic.Value // pathTrimmerHolder
+
+// SetPathTrimmer sets the PathTrimmer applied to Frame.Path and
+// formatting of frames.
+// Passing nil disables trimming, which is the default.
+// It is safe to call SetPathTrimmer concurrently.
+func SetPathTrimmer(t PathTrimmer) {
+	pathTrimmer.Store(pathTrimmerHolder{t})
+}
+
+func trimPath(path string) string {
+	h, _ := pathTrimmer.Load().(pathTrimmerHolder)
+	if h.trim == nil {
+		return path
+	}
+	return h.trim(path)
+}
+
+// PathRewriter rewrites a file path of a frame when the frame is
+// resolved from a program counter.
+type PathRewriter func(path string) string
+
+type pathRewriterHolder struct {
+	rewrite PathRewriter
+}
+
+var pathRewriter atomic.Value // pathRewriterHolder
+
+// SetPathRewriter sets the PathRewriter applied to frames on
+// resolution, to map paths of the build environment like bazel
+// sandboxes or vendor directories back to paths in the source
+// repository.
+// Unlike PathTrimmer, the rewritten path is used everywhere including
+// Frame.RuntimeFrame, Frame.Source and encoding of call stacks.
+// It applies only to frames resolved after the call.
+// Passing nil disables rewriting, which is the default.
+// It is safe to call SetPathRewriter concurrently.
+//
+//	failure.SetPathRewriter(failure.RewritePrefixes(map[string]string{
+//		"/sandbox/execroot/_main/": "/home/me/repo/",
+//	}))
+func SetPathRewriter(r PathRewriter) {
+	pathRewriter.Store(pathRewriterHolder{r})
+	frames.purge()
+}
+
+func rewritePath(path string) string {
+	h, _ := pathRewriter.Load().(pathRewriterHolder)
+	if h.rewrite == nil {
+		return path
+	}
+	return h.rewrite(path)
+}
+
+// newResolvedFrame creates a frame resolved from a program counter.
+func newResolvedFrame(f runtime.Frame) frame {
+	f.File = rewritePath(f.File)
+	return frame{f}
+}
+
+// RewritePrefixes returns a PathRewriter which replaces the first
+// matched prefix of the longest ones with the corresponding value.
+func RewritePrefixes(prefixes map[string]string) PathRewriter {
+	keys := make([]string, 0, len(prefixes))
+	for k := range prefixes {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+	return func(path string) string {
+		for _, k := range keys {
+			if strings.HasPrefix(path, k) {
+				return prefixes[k] + strings.TrimPrefix(path, k)
+			}
+		}
+		return path
+	}
+}
+
+// TrimPrefixes returns a PathTrimmer which removes the first matched
+// prefix like a module root directory.
+func TrimPrefixes(prefixes ...string) PathTrimmer {
+	return func(path string) string {
+		for _, p := range prefixes {
+			if strings.HasPrefix(path, p) {
+				return strings.TrimLeft(strings.TrimPrefix(path, p), "/")
+			}
+		}
+		return path
+	}
+}
+
+// TrimGoPaths returns a PathTrimmer which removes GOPATH and GOROOT
+// from paths by cutting them at the last "/pkg/mod/" or "/src/".
+//
+//	/home/ci/go/src/github.com/foo/bar/bar.go -> github.com/foo/bar/bar.go
+//	/home/ci/go/pkg/mod/github.com/foo/bar@v1.0.0/bar.go -> github.com/foo/bar@v1.0.0/bar.go
+//	/usr/local/go/src/net/http/server.go -> net/http/server.go
+//
+// Relative paths, which are produced by -trimpath builds, are kept
+// as they are.
+func TrimGoPaths() PathTrimmer {
+	return func(path string) string {
+		if !filepath.IsAbs(path) {
+			return path
+		}
+		for _, sep := range []string{"/pkg/mod/", "/src/"} {
+			if i := strings.LastIndex(path, sep); i >= 0 {
+				return path[i+len(sep):]
+			}
+		}
+		return path
+	}
+}
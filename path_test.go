@@ -0,0 +1,72 @@
+package failure_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimPrefixes(t *testing.T) {
+	trim := failure.TrimPrefixes("/home/ci/project", "/tmp/")
+
+	assert.Equal(t, "foo/bar.go", trim("/home/ci/project/foo/bar.go"))
+	assert.Equal(t, "bar.go", trim("/tmp/bar.go"))
+	assert.Equal(t, "/usr/bar.go", trim("/usr/bar.go"))
+}
+
+func TestTrimGoPaths(t *testing.T) {
+	trim := failure.TrimGoPaths()
+
+	assert.Equal(t, "github.com/foo/bar/bar.go", trim("/home/ci/go/src/github.com/foo/bar/bar.go"))
+	assert.Equal(t, "github.com/foo/bar@v1.0.0/bar.go", trim("/home/ci/go/pkg/mod/github.com/foo/bar@v1.0.0/bar.go"))
+	assert.Equal(t, "net/http/server.go", trim("/usr/local/go/src/net/http/server.go"))
+	assert.Equal(t, "github.com/foo/bar/bar.go", trim("github.com/foo/bar/bar.go"))
+	assert.Equal(t, "/opt/bar.go", trim("/opt/bar.go"))
+}
+
+func TestSetPathTrimmer(t *testing.T) {
+	defer failure.SetPathTrimmer(nil)
+
+	f := X().HeadFrame()
+	raw := f.Path()
+
+	failure.SetPathTrimmer(func(path string) string {
+		return "trimmed/" + f.File()
+	})
+	assert.Equal(t, "trimmed/callstack_test.go", f.Path())
+	assert.Equal(t, "trimmed/callstack_test.go:"+fmt.Sprint(f.Line()), fmt.Sprintf("%v", f))
+	assert.Equal(t, raw, failure.RuntimeFrameOf(f).File)
+
+	failure.SetPathTrimmer(nil)
+	assert.Equal(t, raw, f.Path())
+}
+
+func TestRewritePrefixes(t *testing.T) {
+	rewrite := failure.RewritePrefixes(map[string]string{
+		"/sandbox/":            "/repo/",
+		"/sandbox/vendor/foo/": "/foo/",
+	})
+
+	assert.Equal(t, "/repo/bar.go", rewrite("/sandbox/bar.go"))
+	assert.Equal(t, "/foo/foo.go", rewrite("/sandbox/vendor/foo/foo.go"))
+	assert.Equal(t, "/usr/bar.go", rewrite("/usr/bar.go"))
+}
+
+func TestSetPathRewriter(t *testing.T) {
+	defer failure.SetPathRewriter(nil)
+
+	raw := X().HeadFrame()
+
+	failure.SetPathRewriter(func(path string) string {
+		return "/rewritten/" + raw.File()
+	})
+	f := X().HeadFrame()
+	assert.Equal(t, "/rewritten/callstack_test.go", f.Path())
+	assert.Equal(t, "/rewritten/callstack_test.go", failure.RuntimeFrameOf(f).File)
+	assert.Equal(t, raw.Path(), failure.RuntimeFrameOf(raw).File)
+
+	failure.SetPathRewriter(nil)
+	assert.Equal(t, raw.Path(), X().HeadFrame().Path())
+}
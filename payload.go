@@ -0,0 +1,64 @@
+package failure
+
+import "fmt"
+
+// WithPayload appends a typed payload to an error, so that structured
+// details can be passed to the handler of the error without encoding
+// them into debug information.
+// Use PayloadAs to retrieve it.
+//
+//	err := failure.New(InvalidArgument, failure.WithPayload(Violations{...}))
+//	...
+//	if v, ok := failure.PayloadAs[Violations](err); ok {
+//		...
+//	}
+func WithPayload(payload interface{}) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return &withPayload{err, payload}
+	})
+}
+
+// withPayload is used as a pointer to be comparable like withDebug.
+type withPayload struct {
+	error
+	payload interface{}
+}
+
+func (w *withPayload) UnwrapError() error {
+	return w.error
+}
+
+func (w *withPayload) Unwrap() error {
+	return w.error
+}
+
+func (w *withPayload) GetPayload() interface{} {
+	return w.payload
+}
+
+func (w *withPayload) detail(p palette) string {
+	return fmt.Sprintf("payload(%T)", w.payload)
+}
+
+// PayloadAs returns the outermost payload of type T appended by
+// WithPayload.
+// It returns false if err has no such payload.
+func PayloadAs[T any](err error) (T, bool) {
+	type payloadGetter interface {
+		GetPayload() interface{}
+	}
+
+	if err != nil {
+		i := NewIterator(err)
+		for i.Next() {
+			if g, ok := i.Error().(payloadGetter); ok {
+				if t, ok := g.GetPayload().(T); ok {
+					return t, true
+				}
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,36 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+type violations struct {
+	Fields []string
+}
+
+func TestPayloadAs(t *testing.T) {
+	err := failure.New(failure.StringCode("invalid"), failure.WithPayload(violations{[]string{"name"}}))
+	err = failure.Wrap(err, failure.WithPayload(42))
+
+	v, ok := failure.PayloadAs[violations](err)
+	assert.True(t, ok)
+	assert.Equal(t, violations{[]string{"name"}}, v)
+
+	n, ok := failure.PayloadAs[int](err)
+	assert.True(t, ok)
+	assert.Equal(t, 42, n)
+
+	_, ok = failure.PayloadAs[string](err)
+	assert.False(t, ok)
+	_, ok = failure.PayloadAs[int](nil)
+	assert.False(t, ok)
+
+	assert.True(t, errors.Is(err, err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), "    payload(failure_test.violations)\n")
+	assert.Contains(t, failure.Tree(err), "payload(int)")
+}
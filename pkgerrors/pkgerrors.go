@@ -0,0 +1,36 @@
+// Package pkgerrors converts call stacks of github.com/pkg/errors
+// into call stacks of the failure package.
+//
+// failure.CallStackOf recognizes errors created by github.com/pkg/errors
+// without this package. Extract is for the code building its own
+// failure.CallStackExtractor chain.
+package pkgerrors
+
+import (
+	"github.com/morikuni/failure"
+	"github.com/pkg/errors"
+)
+
+// CallStack converts st into a failure.CallStack.
+func CallStack(st errors.StackTrace) failure.CallStack {
+	pcs := make([]uintptr, len(st))
+	for i, v := range st {
+		pcs[i] = uintptr(v)
+	}
+
+	return failure.NewCallStack(pcs)
+}
+
+// Extract extracts the call stack from err having the StackTrace
+// method of github.com/pkg/errors.
+// It implements failure.CallStackExtractor.
+func Extract(err error) failure.CallStack {
+	type stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+
+	if st, ok := err.(stackTracer); ok {
+		return CallStack(st.StackTrace())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package pkgerrors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/pkgerrors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Y() error {
+	return errors.New("aaa")
+}
+
+func TestCallStack(t *testing.T) {
+	err := Y()
+
+	fs := pkgerrors.CallStack(err.(interface{ StackTrace() errors.StackTrace }).StackTrace()).Frames()
+
+	assert.Contains(t, fs[0].Path(), "github.com/morikuni/failure/pkgerrors/pkgerrors_test.go")
+	assert.Equal(t, "Y", fs[0].Func())
+	assert.Equal(t, 14, fs[0].Line())
+	assert.Equal(t, "pkgerrors_test", fs[0].Pkg())
+
+	assert.Equal(t, "TestCallStack", fs[1].Func())
+	assert.Equal(t, 18, fs[1].Line())
+}
+
+func TestExtract(t *testing.T) {
+	assert.Nil(t, pkgerrors.Extract(io.EOF))
+	assert.Nil(t, pkgerrors.Extract(failure.Wrap(Y())))
+
+	failure.RegisterCallStackExtractor(pkgerrors.Extract)
+
+	err := failure.Translate(Y(), failure.StringCode("code"))
+	cs := failure.CallStackOf(err)
+	if assert.NotNil(t, cs) {
+		assert.Equal(t, "Y", cs.HeadFrame().Func())
+		assert.Equal(t, 14, cs.HeadFrame().Line())
+	}
+
+	pkgErr := errors.Wrap(failure.New(failure.StringCode("code")), "aaa")
+	assert.Len(t, failure.CallStacksOf(pkgErr), 2)
+}
+
+func TestCallStackOf(t *testing.T) {
+	err := Y()
+
+	fs := failure.CallStackOf(err).Frames()
+
+	assert.Contains(t, fs[0].Path(), "github.com/morikuni/failure/pkgerrors/pkgerrors_test.go")
+	assert.Equal(t, "Y", fs[0].Func())
+	assert.Equal(t, 14, fs[0].Line())
+	assert.Equal(t, "pkgerrors_test", fs[0].Pkg())
+
+	assert.Equal(t, "TestCallStackOf", fs[1].Func())
+	assert.Equal(t, 49, fs[1].Line())
+}
+
+func TestTranslate(t *testing.T) {
+	pkgErr := errors.New("yyy")
+	err := failure.Translate(pkgErr, failure.StringCode("code"), failure.Message("aaa"))
+
+	assert.Equal(t, failure.StringCode("code"), failure.CodeOf(err))
+	assert.Equal(t, "aaa", failure.MessageOf(err))
+	assert.Nil(t, failure.DebugsOf(err))
+	assert.Equal(t, "TestTranslate: code(code): yyy", err.Error())
+	assert.Equal(t, 63, failure.CallStackOf(err).HeadFrame().Line())
+}
+
+func TestCauseOf(t *testing.T) {
+	base := failure.Wrap(io.EOF)
+	pkgErr := errors.Wrap(base, "aaa")
+	assert.Equal(t, io.EOF, failure.CauseOf(failure.Wrap(pkgErr)))
+}
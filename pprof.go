@@ -0,0 +1,28 @@
+package failure
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// ProfileLabelKey is the key of the pprof label set by DoWithCode.
+const ProfileLabelKey = "failure_code"
+
+// DoWithCode calls f with a copy of ctx having the code of err as the
+// pprof label keyed by ProfileLabelKey, which is also set to the
+// goroutine during f, so that CPU profiles of error handling can be
+// segmented by codes.
+// The label is "unknown" if err has no code.
+//
+//	if err != nil {
+//		failure.DoWithCode(ctx, err, func(ctx context.Context) {
+//			handleError(ctx, err)
+//		})
+//	}
+func DoWithCode(ctx context.Context, err error, f func(context.Context)) {
+	code := "unknown"
+	if c := CodeOf(err); c != nil {
+		code = c.ErrorCode()
+	}
+	pprof.Do(ctx, pprof.Labels(ProfileLabelKey, code), f)
+}
@@ -0,0 +1,33 @@
+package failure_test
+
+import (
+	"context"
+	"io"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoWithCode(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want string
+	}{
+		"code":    {failure.New(failure.StringCode("not_found")), "not_found"},
+		"no code": {io.EOF, "unknown"},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			called := false
+			failure.DoWithCode(context.Background(), test.err, func(ctx context.Context) {
+				called = true
+				label, _ := pprof.Label(ctx, failure.ProfileLabelKey)
+				assert.Equal(t, test.want, label)
+			})
+			assert.True(t, called)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package promutil provides a hook of the failure package to count
+// errors with Prometheus.
+package promutil
+
+import (
+	"github.com/morikuni/failure"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels of the counter created by NewCounter.
+const (
+	// LabelCode is the error code, or empty if the error has no code.
+	LabelCode = "code"
+	// LabelPackage is the import path of the package where the error
+	// is created or wrapped.
+	LabelPackage = "package"
+)
+
+// NewCounter creates a counter having LabelCode and LabelPackage.
+//
+//	c := promutil.NewCounter(prometheus.CounterOpts{Name: "errors_total"})
+//	prometheus.MustRegister(c)
+//	failure.RegisterHook(promutil.Hook(c))
+func NewCounter(opts prometheus.CounterOpts) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(opts, []string{LabelCode, LabelPackage})
+}
+
+// Hook returns a failure.Hook which increments c for every error
+// created by failure.New, Translate, Wrap and WrapMultiple.
+// c must have LabelCode and LabelPackage as NewCounter creates.
+// The package is taken from the call stack of the outermost layer,
+// which is where the hooked function is called.
+func Hook(c *prometheus.CounterVec) failure.Hook {
+	return failure.HookFunc(func(err error) {
+		var code string
+		if cd := failure.CodeOf(err); cd != nil {
+			code = cd.ErrorCode()
+		}
+		var pkg string
+		if css := failure.CallStacksOf(err); len(css) != 0 {
+			pkg = failure.PkgPathOf(css[0].HeadFrame())
+		}
+		c.WithLabelValues(code, pkg).Inc()
+	})
+}
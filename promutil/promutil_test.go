@@ -0,0 +1,30 @@
+package promutil_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/promutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func TestHook(t *testing.T) {
+	c := promutil.NewCounter(prometheus.CounterOpts{Name: "errors_total"})
+	failure.RegisterHook(promutil.Hook(c))
+
+	const pkg = "github.com/morikuni/failure/promutil_test"
+
+	failure.New(NotFound)
+	failure.Translate(io.EOF, NotFound)
+	failure.Wrap(io.EOF)
+	failure.Wrap(io.EOF, failure.WithCallStack(failure.FilterCallStack(failure.Callers(0), failure.ExcludePackages(pkg))))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.WithLabelValues("not_found", pkg)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.WithLabelValues("", pkg)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.WithLabelValues("", "testing")))
+}
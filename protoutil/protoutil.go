@@ -0,0 +1,345 @@
+// Package protoutil encodes errors of the failure package in Protocol
+// Buffers, so that consumers written in other languages can parse
+// them with the definition at schema/error.v1.proto.
+//
+// The encoded message has the same structure as the JSON encoded by
+// failure.MarshalError, and values of debug information are encoded
+// as JSON texts.
+package protoutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/morikuni/failure"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// jsonError mirrors the JSON representation of failure.MarshalError.
+type jsonError struct {
+	Version  int                        `json:"version"`
+	Error    string                     `json:"error"`
+	Code     string                     `json:"code,omitempty"`
+	Messages []string                   `json:"messages,omitempty"`
+	Context  map[string]json.RawMessage `json:"context,omitempty"`
+	Stack    []jsonFrame                `json:"stack,omitempty"`
+	Layers   []jsonLayer                `json:"layers"`
+}
+
+type jsonFrame struct {
+	Path string `json:"path"`
+	File string `json:"file"`
+	Line int64  `json:"line"`
+	Func string `json:"func"`
+	Pkg  string `json:"pkg"`
+}
+
+type jsonLayer struct {
+	Kind      string                     `json:"kind"`
+	Code      string                     `json:"code,omitempty"`
+	CodeType  string                     `json:"code_type,omitempty"`
+	Message   string                     `json:"message,omitempty"`
+	Debug     map[string]json.RawMessage `json:"debug,omitempty"`
+	CallStack []jsonStackFrame           `json:"call_stack,omitempty"`
+	Retryable *bool                      `json:"retryable,omitempty"`
+	Severity  *int64                     `json:"severity,omitempty"`
+	Error     string                     `json:"error,omitempty"`
+}
+
+type jsonStackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int64  `json:"line"`
+}
+
+// Field numbers of schema/error.v1.proto.
+const (
+	errorVersion  = 1
+	errorError    = 2
+	errorCode     = 3
+	errorMessages = 4
+	errorContext  = 5
+	errorStack    = 6
+	errorLayers   = 7
+
+	frameFunction = 1
+	framePath     = 2
+	frameLine     = 3
+	frameFile     = 4
+	frameFunc     = 5
+	framePkg      = 6
+
+	layerKind      = 1
+	layerCode      = 2
+	layerCodeType  = 3
+	layerMessage   = 4
+	layerDebug     = 5
+	layerCallStack = 6
+	layerRetryable = 7
+	layerSeverity  = 8
+	layerError     = 9
+
+	mapKey   = 1
+	mapValue = 2
+)
+
+// Marshal encodes err into the wire format of failure.v1.Error.
+func Marshal(err error) ([]byte, error) {
+	b, err := failure.MarshalError(err)
+	if err != nil {
+		return nil, err
+	}
+	var je jsonError
+	if err := json.Unmarshal(b, &je); err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	buf = appendVarint(buf, errorVersion, uint64(je.Version))
+	buf = appendString(buf, errorError, je.Error)
+	buf = appendString(buf, errorCode, je.Code)
+	for _, m := range je.Messages {
+		buf = protowire.AppendTag(buf, errorMessages, protowire.BytesType)
+		buf = protowire.AppendString(buf, m)
+	}
+	buf = appendMap(buf, errorContext, je.Context)
+	for _, f := range je.Stack {
+		var fb []byte
+		fb = appendString(fb, framePath, f.Path)
+		fb = appendVarint(fb, frameLine, uint64(f.Line))
+		fb = appendString(fb, frameFile, f.File)
+		fb = appendString(fb, frameFunc, f.Func)
+		fb = appendString(fb, framePkg, f.Pkg)
+		buf = appendMessage(buf, errorStack, fb)
+	}
+	for _, l := range je.Layers {
+		buf = appendMessage(buf, errorLayers, marshalLayer(l))
+	}
+	return buf, nil
+}
+
+func marshalLayer(l jsonLayer) []byte {
+	var b []byte
+	b = appendString(b, layerKind, l.Kind)
+	b = appendString(b, layerCode, l.Code)
+	b = appendString(b, layerCodeType, l.CodeType)
+	b = appendString(b, layerMessage, l.Message)
+	b = appendMap(b, layerDebug, l.Debug)
+	for _, f := range l.CallStack {
+		var fb []byte
+		fb = appendString(fb, frameFunction, f.Function)
+		fb = appendString(fb, framePath, f.File)
+		fb = appendVarint(fb, frameLine, uint64(f.Line))
+		b = appendMessage(b, layerCallStack, fb)
+	}
+	if l.Retryable != nil {
+		b = protowire.AppendTag(b, layerRetryable, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(*l.Retryable))
+	}
+	if l.Severity != nil {
+		b = protowire.AppendTag(b, layerSeverity, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(*l.Severity))
+	}
+	b = appendString(b, layerError, l.Error)
+	return b
+}
+
+// appendString appends a string field omitting the default value as
+// proto3 does.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, m []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, m)
+}
+
+// appendMap appends entries of m sorted by key for deterministic
+// output.
+func appendMap(b []byte, num protowire.Number, m map[string]json.RawMessage) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var eb []byte
+		eb = appendString(eb, mapKey, k)
+		eb = appendString(eb, mapValue, string(m[k]))
+		b = appendMessage(b, num, eb)
+	}
+	return b
+}
+
+// Unmarshal decodes the wire format of failure.v1.Error into an error
+// in the same way as failure.UnmarshalError.
+func Unmarshal(b []byte) (error, error) {
+	var je jsonError
+	err := consumeFields(b, func(num protowire.Number, v []byte, n uint64) error {
+		switch num {
+		case errorVersion:
+			je.Version = int(n)
+		case errorError:
+			je.Error = string(v)
+		case errorCode:
+			je.Code = string(v)
+		case errorMessages:
+			je.Messages = append(je.Messages, string(v))
+		case errorContext:
+			return consumeMapEntry(v, &je.Context)
+		case errorStack:
+			var f jsonFrame
+			err := consumeFields(v, func(num protowire.Number, v []byte, n uint64) error {
+				switch num {
+				case framePath:
+					f.Path = string(v)
+				case frameLine:
+					f.Line = int64(n)
+				case frameFile:
+					f.File = string(v)
+				case frameFunc:
+					f.Func = string(v)
+				case framePkg:
+					f.Pkg = string(v)
+				}
+				return nil
+			})
+			je.Stack = append(je.Stack, f)
+			return err
+		case errorLayers:
+			l, err := unmarshalLayer(v)
+			je.Layers = append(je.Layers, l)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jb, err := json.Marshal(je)
+	if err != nil {
+		return nil, err
+	}
+	return failure.UnmarshalError(jb)
+}
+
+func unmarshalLayer(b []byte) (jsonLayer, error) {
+	var l jsonLayer
+	err := consumeFields(b, func(num protowire.Number, v []byte, n uint64) error {
+		switch num {
+		case layerKind:
+			l.Kind = string(v)
+		case layerCode:
+			l.Code = string(v)
+		case layerCodeType:
+			l.CodeType = string(v)
+		case layerMessage:
+			l.Message = string(v)
+		case layerDebug:
+			return consumeMapEntry(v, &l.Debug)
+		case layerCallStack:
+			var f jsonStackFrame
+			err := consumeFields(v, func(num protowire.Number, v []byte, n uint64) error {
+				switch num {
+				case frameFunction:
+					f.Function = string(v)
+				case framePath:
+					f.File = string(v)
+				case frameLine:
+					f.Line = int64(n)
+				}
+				return nil
+			})
+			l.CallStack = append(l.CallStack, f)
+			return err
+		case layerRetryable:
+			r := protowire.DecodeBool(n)
+			l.Retryable = &r
+		case layerSeverity:
+			s := int64(n)
+			l.Severity = &s
+		case layerError:
+			l.Error = string(v)
+		}
+		return nil
+	})
+	return l, err
+}
+
+func consumeMapEntry(b []byte, m *map[string]json.RawMessage) error {
+	var k, v string
+	err := consumeFields(b, func(num protowire.Number, b []byte, n uint64) error {
+		switch num {
+		case mapKey:
+			k = string(b)
+		case mapValue:
+			v = string(b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !json.Valid([]byte(v)) {
+		return fmt.Errorf("protoutil: invalid JSON value of %q", k)
+	}
+	if *m == nil {
+		*m = make(map[string]json.RawMessage)
+	}
+	(*m)[k] = json.RawMessage(v)
+	return nil
+}
+
+// consumeFields calls f for each field of b.
+// v is the payload of length-delimited fields, and n is the value of
+// varint fields. Fields of other types are skipped.
+func consumeFields(b []byte, f func(num protowire.Number, v []byte, n uint64) error) error {
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		b = b[l:]
+
+		var (
+			v []byte
+			n uint64
+		)
+		switch typ {
+		case protowire.BytesType:
+			v, l = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			n, l = protowire.ConsumeVarint(b)
+		default:
+			l = protowire.ConsumeFieldValue(num, typ, b)
+			if l >= 0 {
+				b = b[l:]
+				continue
+			}
+		}
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		b = b[l:]
+
+		if err := f(num, v, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
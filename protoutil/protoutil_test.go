@@ -0,0 +1,122 @@
+package protoutil_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/protoutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func TestMarshal(t *testing.T) {
+	base := failure.Wrap(io.EOF, failure.Message("read failed"), failure.Debug{"id": "a1", "n": 3})
+	err := failure.Translate(base, NotFound,
+		failure.Message("cannot load"),
+		failure.MarkRetryable(),
+		failure.WithSeverity(failure.SeverityWarn),
+	)
+	err = fmt.Errorf("load: %w", err)
+
+	b, e := protoutil.Marshal(err)
+	assert.NoError(t, e)
+
+	got, e := protoutil.Unmarshal(b)
+	assert.NoError(t, e)
+
+	assert.Equal(t, err.Error(), got.Error())
+	assert.Equal(t, NotFound, failure.CodeOf(got))
+	assert.Equal(t, "cannot load", failure.MessageOf(got))
+	assert.Equal(t, []failure.Debug{{"id": "a1", "n": float64(3)}}, failure.DebugsOf(got))
+	assert.True(t, failure.IsRetryable(got))
+	assert.Equal(t, failure.SeverityWarn, failure.SeverityOf(got))
+	assert.Equal(t, io.EOF.Error(), failure.CauseOf(got).Error())
+	assert.Equal(t, failure.CallStackOf(err).HeadFrame().Path(), failure.CallStackOf(got).HeadFrame().Path())
+	assert.Equal(t, failure.CallStackOf(err).HeadFrame().Line(), failure.CallStackOf(got).HeadFrame().Line())
+
+	want, _ := failure.MarshalError(err)
+	jb, _ := failure.MarshalError(got)
+	assert.JSONEq(t, string(want), string(jb))
+
+	b2, e := protoutil.Marshal(got)
+	assert.NoError(t, e)
+	assert.Equal(t, b, b2)
+}
+
+func TestMarshal_Fields(t *testing.T) {
+	b, err := protoutil.Marshal(failure.New(NotFound, failure.Debug{"id": "a1"}))
+	assert.NoError(t, err)
+
+	fields := map[protowire.Number]int{}
+	var context []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if num == 5 {
+			context, _ = protowire.ConsumeBytes(b)
+		}
+		b = b[n:]
+		fields[num]++
+	}
+	assert.Equal(t, 1, fields[1], "version")
+	assert.Equal(t, 1, fields[2], "error")
+	assert.Equal(t, 1, fields[3], "code")
+	assert.Equal(t, 1, fields[5], "context")
+	assert.NotZero(t, fields[6], "stack")
+	assert.Equal(t, 3, fields[7], "layers")
+
+	want := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "id")
+	want = protowire.AppendString(protowire.AppendTag(want, 2, protowire.BytesType), `"a1"`)
+	assert.Equal(t, want, context)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+	b = protowire.AppendTag(b, 100, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, 1)
+	var l []byte
+	l = protowire.AppendTag(l, 1, protowire.BytesType)
+	l = protowire.AppendString(l, "error")
+	l = protowire.AppendTag(l, 9, protowire.BytesType)
+	l = protowire.AppendString(l, "boom")
+	b = protowire.AppendTag(b, 7, protowire.BytesType)
+	b = protowire.AppendBytes(b, l)
+
+	err, e := protoutil.Unmarshal(b)
+	assert.NoError(t, e)
+	assert.Equal(t, "boom", err.Error())
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	var invalidJSON []byte
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, "id")
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendString(entry, "{")
+	invalidJSON = protowire.AppendTag(invalidJSON, 5, protowire.BytesType)
+	invalidJSON = protowire.AppendBytes(invalidJSON, entry)
+
+	tests := map[string][]byte{
+		"truncated":    {0x12, 0x05, 'a'},
+		"no layers":    {0x08, 0x01},
+		"invalid json": invalidJSON,
+	}
+
+	for title, input := range tests {
+		t.Run(title, func(t *testing.T) {
+			_, err := protoutil.Unmarshal(input)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := protoutil.Marshal(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package failure
+
+import "fmt"
+
+// WithPublicMessage appends a message safe to be shown to clients of
+// APIs, while messages appended by Message may contain internal
+// details like IDs and queries and are meant for logs.
+// HTTPErrorWriter and the integrations of RPC frameworks respond with
+// the public message in preference to the message.
+//
+//	return failure.Translate(err, NotFound,
+//		failure.Message(fmt.Sprintf("no row in users for id=%d", id)),
+//		failure.WithPublicMessage("The user does not exist."),
+//	)
+func WithPublicMessage(msg string) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withPublicMessage{err, msg}
+	})
+}
+
+type withPublicMessage struct {
+	error
+	message string
+}
+
+func (w withPublicMessage) UnwrapError() error {
+	return w.error
+}
+
+func (w withPublicMessage) Unwrap() error {
+	return w.error
+}
+
+func (w withPublicMessage) GetPublicMessage() string {
+	return w.message
+}
+
+func (w withPublicMessage) detail(p palette) string {
+	return p.paint(p.message, fmt.Sprintf("public_message(%q)", w.message))
+}
+
+// PublicMessageOf extracts the outermost public message appended by
+// WithPublicMessage from err.
+// It returns an empty string if err has no public message.
+func PublicMessageOf(err error) string {
+	type publicMessageGetter interface {
+		GetPublicMessage() string
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(publicMessageGetter); ok {
+			return g.GetPublicMessage()
+		}
+	}
+	return ""
+}
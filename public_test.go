@@ -0,0 +1,24 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPublicMessage(t *testing.T) {
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("no row for id=1"),
+		failure.WithPublicMessage("The user does not exist."),
+	)
+	err = failure.Wrap(err, failure.WithPublicMessage("The page does not exist."))
+
+	assert.Equal(t, "The page does not exist.", failure.PublicMessageOf(err))
+	assert.Equal(t, "no row for id=1", failure.MessageOf(err))
+	assert.Contains(t, fmt.Sprintf("%+v", err), `    public_message("The user does not exist.")`)
+	assert.Equal(t, "", failure.PublicMessageOf(io.EOF))
+	assert.Equal(t, "", failure.PublicMessageOf(nil))
+}
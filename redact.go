@@ -0,0 +1,86 @@
+package failure
+
+import (
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces values of sensitive keys in debug information.
+const RedactedValue = "[REDACTED]"
+
+var (
+	sensitiveKeysMu sync.RWMutex
+	sensitiveKeys   = make(map[string]struct{})
+)
+
+// MarkSensitive marks keys of debug information as sensitive.
+// Keys are compared case-insensitively.
+//
+// Values of sensitive keys are replaced with RedactedValue in
+// DebugsOf, formatting, logging and serialization of errors including
+// the integrations built on them. Use ValueOf, ValueAs or
+// UnredactedDebugsOf to access raw values in the process.
+func MarkSensitive(keys ...string) {
+	sensitiveKeysMu.Lock()
+	defer sensitiveKeysMu.Unlock()
+
+	for _, k := range keys {
+		sensitiveKeys[strings.ToLower(k)] = struct{}{}
+	}
+}
+
+func isSensitive(key string) bool {
+	sensitiveKeysMu.RLock()
+	defer sensitiveKeysMu.RUnlock()
+
+	if len(sensitiveKeys) == 0 {
+		return false
+	}
+	_, ok := sensitiveKeys[strings.ToLower(key)]
+	return ok
+}
+
+// redactDebug returns d with values of sensitive keys replaced.
+// d itself is returned if it has no sensitive key.
+func redactDebug(d Debug) Debug {
+	var redacted Debug
+	for k := range d {
+		if !isSensitive(k) {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(Debug, len(d))
+			for k, v := range d {
+				redacted[k] = v
+			}
+		}
+		redacted[k] = RedactedValue
+	}
+	if redacted == nil {
+		return d
+	}
+	return redacted
+}
+
+// UnredactedDebugsOf extracts list of information from the error like
+// DebugsOf, but values of sensitive keys are not redacted.
+// The result must not be written to outputs.
+func UnredactedDebugsOf(err error) []Debug {
+	if err == nil {
+		return nil
+	}
+
+	type unredactedDebugGetter interface {
+		getUnredactedDebug() Debug
+	}
+
+	var debugs []Debug
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(unredactedDebugGetter); ok {
+			debugs = append(debugs, g.getUnredactedDebug())
+		}
+	}
+
+	return debugs
+}
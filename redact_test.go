@@ -0,0 +1,54 @@
+package failure_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkSensitive(t *testing.T) {
+	failure.MarkSensitive("redact_test_password", "Redact_Test_Token")
+
+	err := failure.New(TestCodeA, failure.Debug{
+		"redact_test_password": "p@ss",
+		"REDACT_TEST_TOKEN":    "t0ken",
+		"user":                 "foo",
+	})
+	err = failure.Wrap(err, failure.Debug{"id": 1})
+
+	assert.Equal(t, []failure.Debug{
+		{"id": 1},
+		{"redact_test_password": failure.RedactedValue, "REDACT_TEST_TOKEN": failure.RedactedValue, "user": "foo"},
+	}, failure.DebugsOf(err))
+	assert.Equal(t, []failure.Debug{
+		{"id": 1},
+		{"redact_test_password": "p@ss", "REDACT_TEST_TOKEN": "t0ken", "user": "foo"},
+	}, failure.UnredactedDebugsOf(err))
+
+	v, _ := failure.ValueOf(err, "redact_test_password")
+	assert.Equal(t, "p@ss", v)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", "error", err)
+	b, e := failure.MarshalError(err)
+	assert.NoError(t, e)
+	outputs := map[string]string{
+		"%+v":     fmt.Sprintf("%+v", err),
+		"%#v":     fmt.Sprintf("%#v", err),
+		"slog":    buf.String(),
+		"json":    string(b),
+		"tree":    failure.Tree(err),
+		"message": failure.RenderMessage(err, "{{.redact_test_password}}"),
+	}
+	for name, out := range outputs {
+		assert.NotContains(t, out, "p@ss", name)
+		assert.NotContains(t, out, "t0ken", name)
+		assert.Contains(t, out, failure.RedactedValue, name)
+	}
+
+	assert.Nil(t, failure.UnredactedDebugsOf(nil))
+}
@@ -0,0 +1,90 @@
+package failure
+
+import (
+	"sort"
+	"sync"
+)
+
+// CodeInfo describes an error code registered by RegisterCode.
+type CodeInfo struct {
+	Code        Code
+	Description string
+	HTTPStatus  int
+	Retryable   bool
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = make(map[Code]CodeInfo)
+)
+
+// RegisterCode registers the code with its description in the central
+// registry, so that all the codes of an application can be listed by
+// Codes for documentation.
+// The HTTP status and the retryability are also registered by
+// RegisterHTTPStatus and RegisterRetryability. The HTTP status is not
+// registered if it is 0.
+//
+//	func init() {
+//		failure.RegisterCode(NotFound, "The resource is not found.", http.StatusNotFound, false)
+//	}
+func RegisterCode(code Code, description string, httpStatus int, retryable bool) {
+	codesMu.Lock()
+	codes[resolveCode(code)] = CodeInfo{code, description, httpStatus, retryable}
+	codesMu.Unlock()
+
+	if httpStatus != 0 {
+		RegisterHTTPStatus(code, httpStatus)
+	}
+	r := NotRetryable
+	if retryable {
+		r = Retryable
+	}
+	RegisterRetryability(code, r)
+}
+
+// Codes returns all the codes registered by RegisterCode ordered by
+// their string representations.
+func Codes() []CodeInfo {
+	codesMu.RLock()
+	infos := make([]CodeInfo, 0, len(codes))
+	for _, info := range codes {
+		infos = append(infos, info)
+	}
+	codesMu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Code.ErrorCode() < infos[j].Code.ErrorCode()
+	})
+	return infos
+}
+
+// LookupCode returns the information of the code registered by
+// RegisterCode.
+// Codes aliased by AliasCode are resolved.
+func LookupCode(code Code) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+
+	return lookupAliased(codes, code)
+}
+
+// UnregisteredCodeHook returns a Hook which calls report with errors
+// having a code not registered by RegisterCode.
+// It can be used to detect unregistered codes in tests or in
+// development.
+//
+//	failure.RegisterHook(failure.UnregisteredCodeHook(func(err error) {
+//		panic(fmt.Sprintf("unregistered code: %v", err))
+//	}))
+func UnregisteredCodeHook(report func(err error)) Hook {
+	return HookFunc(func(err error) {
+		c := CodeOf(err)
+		if c == nil {
+			return
+		}
+		if _, ok := LookupCode(c); !ok {
+			report(err)
+		}
+	})
+}
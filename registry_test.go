@@ -0,0 +1,73 @@
+package failure_test
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	RegistryCodeA failure.StringCode = "registry.a"
+	RegistryCodeB failure.StringCode = "registry.b"
+)
+
+func init() {
+	failure.RegisterCode(RegistryCodeB, "code b", 0, true)
+	failure.RegisterCode(RegistryCodeA, "code a", http.StatusNotFound, false)
+}
+
+func TestRegisterCode(t *testing.T) {
+	info, ok := failure.LookupCode(RegistryCodeA)
+	assert.True(t, ok)
+	assert.Equal(t, failure.CodeInfo{RegistryCodeA, "code a", http.StatusNotFound, false}, info)
+	_, ok = failure.LookupCode(TestCodeA)
+	assert.False(t, ok)
+
+	assert.Equal(t, http.StatusNotFound, failure.HTTPStatusOf(failure.New(RegistryCodeA)))
+	assert.Equal(t, http.StatusInternalServerError, failure.HTTPStatusOf(failure.New(RegistryCodeB)))
+	assert.False(t, failure.IsRetryable(failure.Translate(failure.New(RegistryCodeB), RegistryCodeA)))
+	assert.True(t, failure.IsRetryable(failure.New(RegistryCodeB)))
+}
+
+func TestCodes(t *testing.T) {
+	var got []failure.CodeInfo
+	for _, info := range failure.Codes() {
+		if info.Code == RegistryCodeA || info.Code == RegistryCodeB {
+			got = append(got, info)
+		}
+	}
+
+	assert.Equal(t, []failure.CodeInfo{
+		{RegistryCodeA, "code a", http.StatusNotFound, false},
+		{RegistryCodeB, "code b", 0, true},
+	}, got)
+}
+
+func TestUnregisteredCodeHook(t *testing.T) {
+	const code failure.StringCode = "registry.unregistered"
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	failure.RegisterHook(failure.UnregisteredCodeHook(func(err error) {
+		c := failure.CodeOf(err)
+		if c != code && c != RegistryCodeA {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+
+	e1 := failure.New(code)
+	failure.New(RegistryCodeA)
+	failure.Translate(e1, RegistryCodeA)
+	failure.Wrap(io.EOF)
+
+	assert.Equal(t, []error{e1}, errs)
+}
@@ -0,0 +1,102 @@
+package failure
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Renderer renders errors with a text/template.
+type Renderer struct {
+	template *template.Template
+}
+
+// RenderData is the data passed to the template of Renderer.
+type RenderData struct {
+	// Error is the result of err.Error().
+	Error string
+	// Code is the error code, or an empty string if err has no code.
+	Code string
+	// Message is the message extracted by MessageOf.
+	Message string
+	// Context is the debug information of all layers merged into one.
+	// If the same key appears more than once, the outermost one is used.
+	Context map[string]interface{}
+	// CallStack is the call stack extracted by CallStackOf, or nil.
+	CallStack CallStack
+	// Layers are the wrapped errors from the outermost.
+	Layers []RenderLayer
+}
+
+// RenderLayer is a wrapped error in RenderData.
+// The fields are for the layer itself without unwrapping.
+type RenderLayer struct {
+	Error     string
+	Code      string
+	Message   string
+	Debug     Debug
+	CallStack CallStack
+}
+
+// NewRenderer creates a Renderer from tmpl written in text/template
+// syntax. The template is executed with RenderData.
+//
+//	r, err := failure.NewRenderer(`[{{.Code}}] {{.Message}}
+//	{{range .CallStack.Frames}}  at {{.Func}} ({{.Path}}:{{.Line}})
+//	{{end}}`)
+func NewRenderer(tmpl string) (*Renderer, error) {
+	t, err := template.New("failure").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{t}, nil
+}
+
+// Render writes err rendered by the template to w.
+func (r *Renderer) Render(w io.Writer, err error) error {
+	return r.template.Execute(w, newRenderData(err))
+}
+
+// RenderString returns err rendered by the template.
+func (r *Renderer) RenderString(err error) (string, error) {
+	var sb strings.Builder
+	if e := r.Render(&sb, err); e != nil {
+		return "", e
+	}
+	return sb.String(), nil
+}
+
+func newRenderData(err error) RenderData {
+	if err == nil {
+		return RenderData{}
+	}
+
+	d := RenderData{
+		Error:     err.Error(),
+		Message:   MessageOf(err),
+		Context:   ContextOf(err),
+		CallStack: CallStackOf(err),
+	}
+	if c := CodeOf(err); c != nil {
+		d.Code = c.ErrorCode()
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		switch i.Error().(type) {
+		case formatter, withCollector:
+			continue
+		}
+		l := RenderLayer{
+			Error:     i.Error().Error(),
+			Message:   i.Message(),
+			Debug:     i.Debug(),
+			CallStack: i.CallStack(),
+		}
+		if c := i.Code(); c != nil {
+			l.Code = c.ErrorCode()
+		}
+		d.Layers = append(d.Layers, l)
+	}
+	return d
+}
@@ -0,0 +1,50 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderer(t *testing.T) {
+	r, err := failure.NewRenderer(`{{.Code}}: {{.Message}} user={{.Context.user_id}}
+{{with .CallStack}}at {{.HeadFrame.Func}}{{end}}
+{{range .Layers}}- {{if .Code}}code {{.Code}}{{else if .Message}}message {{.Message}}{{else if .Debug}}debug{{else if .CallStack}}stack {{.CallStack.HeadFrame.Func}}{{else}}error {{.Error}}{{end}}
+{{end}}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e := failure.Translate(io.EOF, TestCodeA, failure.Debug{"user_id": 1}, failure.Message("not found"))
+	got, err := r.RenderString(e)
+	assert.NoError(t, err)
+	assert.Equal(t, `code_a: not found user=1
+at TestRenderer
+- stack TestRenderer
+- message not found
+- debug
+- code code_a
+- error EOF
+`, got)
+
+	got, err = r.RenderString(io.EOF)
+	assert.NoError(t, err)
+	assert.Equal(t, ":  user=<no value>\n\n- error EOF\n", got)
+}
+
+func TestNewRenderer_Invalid(t *testing.T) {
+	r, err := failure.NewRenderer("{{.Code")
+	assert.Nil(t, r)
+	assert.Error(t, err)
+}
+
+func TestRenderer_ExecError(t *testing.T) {
+	r, err := failure.NewRenderer("{{.Unknown}}")
+	assert.NoError(t, err)
+
+	got, err := r.RenderString(io.EOF)
+	assert.Equal(t, "", got)
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package failure
+
+import "net/http"
+
+// Keys of the debug information added by WithRequest.
+// Integrations like grpcutil use the same keys, so that errors of
+// different protocols can be queried in the same way.
+const (
+	MethodKey    = "method"
+	PathKey      = "path"
+	PeerKey      = "peer"
+	RequestIDKey = "request_id"
+	UserAgentKey = "user_agent"
+)
+
+// WithRequest appends the metadata of r to an error as debug
+// information.
+// The method, the path, the remote address as the peer, the
+// X-Request-Id header and the user agent are added, and empty ones
+// are omitted.
+// It appends nothing if r is nil.
+//
+//	failure.Wrap(err, failure.WithRequest(r))
+func WithRequest(r *http.Request) Wrapper {
+	if r == nil {
+		return WrapperFunc(func(err error) error {
+			return err
+		})
+	}
+
+	d := Debug{}
+	add := func(k, v string) {
+		if v != "" {
+			d[k] = v
+		}
+	}
+	add(MethodKey, r.Method)
+	if r.URL != nil {
+		add(PathKey, r.URL.Path)
+	}
+	add(PeerKey, r.RemoteAddr)
+	add(RequestIDKey, r.Header.Get("X-Request-Id"))
+	add(UserAgentKey, r.UserAgent())
+	return d
+}
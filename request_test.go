@@ -0,0 +1,37 @@
+package failure_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+	r.Header.Set("X-Request-Id", "req-1")
+	r.Header.Set("User-Agent", "test/1.0")
+
+	err := failure.Wrap(io.EOF, failure.WithRequest(r))
+	assert.Equal(t, []failure.Debug{{
+		"method":     "POST",
+		"path":       "/users",
+		"peer":       "192.0.2.1:1234",
+		"request_id": "req-1",
+		"user_agent": "test/1.0",
+	}}, failure.DebugsOf(err))
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	err = failure.Wrap(io.EOF, failure.WithRequest(r))
+	assert.Equal(t, []failure.Debug{{
+		"method": "GET",
+		"path":   "/",
+		"peer":   "192.0.2.1:1234",
+	}}, failure.DebugsOf(err))
+
+	err = failure.Wrap(io.EOF, failure.WithRequest(nil))
+	assert.Empty(t, failure.DebugsOf(err))
+}
@@ -0,0 +1,172 @@
+package failure
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Retryability represents whether an operation failed with an error
+// can be retried.
+type Retryability int
+
+// Retryability values.
+const (
+	// NotRetryable means the operation should not be retried.
+	NotRetryable Retryability = iota + 1
+	// Retryable means the operation can be retried.
+	Retryable
+)
+
+var (
+	retryabilitiesMu sync.RWMutex
+	retryabilities   = make(map[Code]Retryability)
+)
+
+// RegisterRetryability registers the retryability for the error code.
+func RegisterRetryability(code Code, r Retryability) {
+	retryabilitiesMu.Lock()
+	defer retryabilitiesMu.Unlock()
+
+	retryabilities[resolveCode(code)] = r
+}
+
+// MarkRetryable marks an error as retryable.
+func MarkRetryable() Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withRetryability{err, Retryable}
+	})
+}
+
+// MarkNotRetryable marks an error as not retryable.
+func MarkNotRetryable() Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withRetryability{err, NotRetryable}
+	})
+}
+
+type withRetryability struct {
+	error
+	retryability Retryability
+}
+
+func (w withRetryability) UnwrapError() error {
+	return w.error
+}
+
+func (w withRetryability) Unwrap() error {
+	return w.error
+}
+
+func (w withRetryability) GetRetryability() Retryability {
+	return w.retryability
+}
+
+func (w withRetryability) detail(p palette) string {
+	return fmt.Sprintf("retryable(%t)", w.retryability == Retryable)
+}
+
+// IsRetryable checks whether err can be retried.
+// It walks the error chain from the outermost, and the first error
+// marked by MarkRetryable/MarkNotRetryable or having a code
+// registered by RegisterRetryability decides the result.
+// It returns false if nothing decides it.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	type retryabilityGetter interface {
+		GetRetryability() Retryability
+	}
+	type codeGetter interface {
+		GetCode() Code
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		switch t := i.Error().(type) {
+		case retryabilityGetter:
+			return t.GetRetryability() == Retryable
+		case codeGetter:
+			retryabilitiesMu.RLock()
+			r, ok := lookupAliased(retryabilities, t.GetCode())
+			retryabilitiesMu.RUnlock()
+			if ok {
+				return r == Retryable
+			}
+		}
+	}
+
+	return false
+}
+
+// WithRetryAfter appends a hint that the operation can be retried
+// after d.
+// The hint is written to the Retry-After header by HTTPErrorWriter and
+// to RetryInfo by grpcutil.
+func WithRetryAfter(d time.Duration) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withRetryAfter{err, d, time.Time{}}
+	})
+}
+
+// WithRetryAt appends a hint that the operation can be retried at t.
+// It is the same as WithRetryAfter except that the duration is
+// counted from when it is read by RetryAfterOf.
+func WithRetryAt(t time.Time) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withRetryAfter{err, 0, t}
+	})
+}
+
+type withRetryAfter struct {
+	error
+	after time.Duration
+	at    time.Time
+}
+
+func (w withRetryAfter) UnwrapError() error {
+	return w.error
+}
+
+func (w withRetryAfter) Unwrap() error {
+	return w.error
+}
+
+func (w withRetryAfter) GetRetryAfter() time.Duration {
+	if w.at.IsZero() {
+		return w.after
+	}
+	if d := time.Until(w.at); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (w withRetryAfter) detail(p palette) string {
+	return fmt.Sprintf("retry_after(%s)", w.GetRetryAfter())
+}
+
+// RetryAfterOf returns the duration to wait before retrying the
+// operation failed with err, which is appended by WithRetryAfter or
+// WithRetryAt.
+// The outermost one is used if there are multiple hints.
+func RetryAfterOf(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	type retryAfterGetter interface {
+		GetRetryAfter() time.Duration
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(retryAfterGetter); ok {
+			return g.GetRetryAfter(), true
+		}
+	}
+
+	return 0, false
+}
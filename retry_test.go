@@ -0,0 +1,76 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	Unavailable  failure.StringCode = "unavailable"
+	InvalidInput failure.StringCode = "invalid_input"
+)
+
+func init() {
+	failure.RegisterRetryability(Unavailable, failure.Retryable)
+	failure.RegisterRetryability(InvalidInput, failure.NotRetryable)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		want bool
+	}{
+		"registered retryable":     {failure.New(Unavailable), true},
+		"registered not retryable": {failure.New(InvalidInput), false},
+		"unregistered":             {failure.New(TestCodeA), false},
+		"inner registered":         {failure.Translate(failure.New(Unavailable), TestCodeA), true},
+		"outer registered":         {failure.Translate(failure.New(Unavailable), InvalidInput), false},
+		"marked":                   {failure.Wrap(io.EOF, failure.MarkRetryable()), true},
+		"marked not retryable":     {failure.New(Unavailable, failure.MarkNotRetryable()), false},
+		"outer mark wins":          {failure.Wrap(failure.Wrap(io.EOF, failure.MarkNotRetryable()), failure.MarkRetryable()), true},
+		"no info":                  {io.EOF, false},
+		"nil":                      {nil, false},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, failure.IsRetryable(test.err))
+		})
+	}
+}
+
+func TestIsRetryable_Format(t *testing.T) {
+	err := failure.New(Unavailable, failure.MarkRetryable())
+
+	assert.Regexp(t, `    retryable\(true\)
+    code\(unavailable\)
+`, fmt.Sprintf("%+v", err))
+}
+
+func TestRetryAfterOf(t *testing.T) {
+	err := failure.New(Unavailable, failure.WithRetryAfter(30*time.Second))
+	d, ok := failure.RetryAfterOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	d, ok = failure.RetryAfterOf(failure.Wrap(err, failure.WithRetryAt(time.Now().Add(time.Minute))))
+	assert.True(t, ok)
+	assert.True(t, d > 50*time.Second && d <= time.Minute, d)
+
+	d, ok = failure.RetryAfterOf(failure.Wrap(err, failure.WithRetryAt(time.Now().Add(-time.Minute))))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+
+	_, ok = failure.RetryAfterOf(failure.New(Unavailable))
+	assert.False(t, ok)
+	_, ok = failure.RetryAfterOf(nil)
+	assert.False(t, ok)
+
+	assert.Contains(t, fmt.Sprintf("%+v", err), "    retry_after(30s)\n")
+}
@@ -0,0 +1,63 @@
+// Package sentry converts errors of the failure package into
+// events of github.com/getsentry/sentry-go.
+package sentry
+
+import (
+	"fmt"
+
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/morikuni/failure"
+)
+
+// NewEvent converts err into a sentry event.
+// The code of err is set to the "failure.code" tag, the message is
+// set to the event message, and debug information is set to extra.
+func NewEvent(err error) *sentrygo.Event {
+	event := sentrygo.NewEvent()
+	event.Level = sentrygo.LevelError
+	event.Message = failure.MessageOf(err)
+	event.Exception = []sentrygo.Exception{Exception(err)}
+
+	if c := failure.CodeOf(err); c != nil {
+		event.Tags["failure.code"] = c.ErrorCode()
+	}
+
+	for k, v := range failure.ContextOf(err) {
+		event.Extra[k] = v
+	}
+
+	return event
+}
+
+// Exception converts err into a sentry exception.
+// The stack trace of the exception is the deepest call stack of err
+// so that sentry can group events by the place the error occurred.
+func Exception(err error) sentrygo.Exception {
+	e := sentrygo.Exception{
+		Type:  fmt.Sprintf("%T", failure.CauseOf(err)),
+		Value: err.Error(),
+	}
+	if c := failure.CodeOf(err); c != nil {
+		e.Type = c.ErrorCode()
+	}
+	if cs := failure.CallStackOf(err); cs != nil {
+		e.Stacktrace = Stacktrace(cs)
+	}
+	return e
+}
+
+// Stacktrace converts cs into a sentry stack trace.
+// Sentry expects frames ordered from the oldest call, so the
+// frames are reversed.
+func Stacktrace(cs failure.CallStack) *sentrygo.Stacktrace {
+	fs := cs.Frames()
+	if len(fs) == 0 {
+		return nil
+	}
+
+	frames := make([]sentrygo.Frame, len(fs))
+	for i, f := range fs {
+		frames[len(fs)-1-i] = sentrygo.NewFrame(failure.RuntimeFrameOf(f))
+	}
+	return &sentrygo.Stacktrace{Frames: frames}
+}
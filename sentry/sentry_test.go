@@ -0,0 +1,49 @@
+package sentry_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/sentry"
+	"github.com/stretchr/testify/assert"
+)
+
+const NotFound failure.StringCode = "not_found"
+
+func newError() error {
+	return failure.Translate(io.EOF, NotFound,
+		failure.Message("xxx"),
+		failure.Debug{"id": 1},
+	)
+}
+
+func TestNewEvent(t *testing.T) {
+	err := failure.Wrap(newError(), failure.Debug{"id": 2, "name": "a"})
+
+	event := sentry.NewEvent(err)
+
+	assert.Equal(t, "xxx", event.Message)
+	assert.Equal(t, "not_found", event.Tags["failure.code"])
+	assert.Equal(t, map[string]interface{}{"id": 2, "name": "a"}, event.Extra)
+	if assert.Len(t, event.Exception, 1) {
+		e := event.Exception[0]
+		assert.Equal(t, "not_found", e.Type)
+		assert.Equal(t, err.Error(), e.Value)
+
+		fs := e.Stacktrace.Frames
+		if assert.True(t, len(fs) >= 2) {
+			assert.Equal(t, "newError", fs[len(fs)-1].Function)
+			assert.Equal(t, 15, fs[len(fs)-1].Lineno)
+			assert.Equal(t, "TestNewEvent", fs[len(fs)-2].Function)
+		}
+	}
+}
+
+func TestException(t *testing.T) {
+	e := sentry.Exception(io.EOF)
+
+	assert.Equal(t, "*errors.errorString", e.Type)
+	assert.Equal(t, "EOF", e.Value)
+	assert.Nil(t, e.Stacktrace)
+}
@@ -0,0 +1,103 @@
+package failure
+
+import "fmt"
+
+// Severity represents how serious an error is.
+type Severity int
+
+// Severity values from the least serious.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+// String implements the fmt.Stringer interface.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// WithSeverity appends severity to an error.
+func WithSeverity(s Severity) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withSeverity{err, s}
+	})
+}
+
+type withSeverity struct {
+	error
+	severity Severity
+}
+
+func (w withSeverity) UnwrapError() error {
+	return w.error
+}
+
+func (w withSeverity) Unwrap() error {
+	return w.error
+}
+
+func (w withSeverity) GetSeverity() Severity {
+	return w.severity
+}
+
+func (w withSeverity) detail(p palette) string {
+	return fmt.Sprintf("severity(%s)", w.severity)
+}
+
+// SeverityOf extracts the severity from err.
+// The outermost severity is used, and SeverityError is returned if
+// err has no severity.
+// It returns 0 if err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return 0
+	}
+
+	type severityGetter interface {
+		GetSeverity() Severity
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(severityGetter); ok {
+			return g.GetSeverity()
+		}
+	}
+
+	return SeverityError
+}
+
+// Warn creates an error with code and message whose severity is
+// SeverityWarn.
+// Warnings satisfy error, but IsWarning distinguishes them from errors,
+// so that APIs can return them with partial results, and loggers like
+// slogutil.Log log them at the warn level.
+//
+//	return users, failure.Warn(PartialResult, "some users are not loaded")
+func Warn(code Code, msg string, wrappers ...Wrapper) error {
+	ws := append([]Wrapper{Message(msg), WithSeverity(SeverityWarn)}, wrappers...)
+	return newFailure(nil, code, ws, 2)
+}
+
+// IsWarning reports whether err is a warning, which is an error whose
+// severity is SeverityWarn or less serious.
+// It returns false if err is nil.
+func IsWarning(err error) bool {
+	s := SeverityOf(err)
+	return s != 0 && s <= SeverityWarn
+}
@@ -0,0 +1,62 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityOf(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		want failure.Severity
+	}{
+		"with severity": {failure.New(TestCodeA, failure.WithSeverity(failure.SeverityWarn)), failure.SeverityWarn},
+		"outer wins":    {failure.Wrap(failure.New(TestCodeA, failure.WithSeverity(failure.SeverityWarn)), failure.WithSeverity(failure.SeverityCritical)), failure.SeverityCritical},
+		"inner":         {failure.Wrap(failure.New(TestCodeA, failure.WithSeverity(failure.SeverityInfo))), failure.SeverityInfo},
+		"default":       {io.EOF, failure.SeverityError},
+		"nil":           {nil, 0},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			assert.Equal(t, test.want, failure.SeverityOf(test.err))
+		})
+	}
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, "debug", failure.SeverityDebug.String())
+	assert.Equal(t, "info", failure.SeverityInfo.String())
+	assert.Equal(t, "warn", failure.SeverityWarn.String())
+	assert.Equal(t, "error", failure.SeverityError.String())
+	assert.Equal(t, "critical", failure.SeverityCritical.String())
+	assert.Equal(t, "unknown", failure.Severity(0).String())
+}
+
+func TestSeverity_Format(t *testing.T) {
+	err := failure.New(TestCodeA, failure.WithSeverity(failure.SeverityWarn))
+
+	assert.Regexp(t, `    severity\(warn\)
+    code\(code_a\)
+`, fmt.Sprintf("%+v", err))
+}
+
+func TestWarn(t *testing.T) {
+	err := failure.Warn(TestCodeA, "partially loaded", failure.Debug{"missing": 2})
+
+	assert.True(t, failure.IsWarning(err))
+	assert.Equal(t, failure.SeverityWarn, failure.SeverityOf(err))
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "partially loaded", failure.MessageOf(err))
+	assert.Equal(t, "TestWarn", failure.CallStackOf(err).HeadFrame().Func())
+
+	assert.True(t, failure.IsWarning(failure.New(TestCodeA, failure.WithSeverity(failure.SeverityInfo))))
+	assert.False(t, failure.IsWarning(failure.New(TestCodeA)))
+	assert.False(t, failure.IsWarning(failure.Wrap(err, failure.WithSeverity(failure.SeverityError))))
+	assert.False(t, failure.IsWarning(nil))
+}
@@ -0,0 +1,80 @@
+// Package slogutil provides helpers to log errors of the failure
+// package with log/slog.
+package slogutil
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/morikuni/failure"
+)
+
+// Error returns an attribute for err keyed by "error".
+// Errors created by the failure package are expanded into their
+// code, message, debug information and call stack.
+func Error(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+// Level returns the log level for err decided by failure.SeverityOf.
+// failure.SeverityCritical is mapped to a level above slog.LevelError.
+func Level(err error) slog.Level {
+	switch failure.SeverityOf(err) {
+	case failure.SeverityDebug:
+		return slog.LevelDebug
+	case failure.SeverityInfo:
+		return slog.LevelInfo
+	case failure.SeverityWarn:
+		return slog.LevelWarn
+	case failure.SeverityCritical:
+		return LevelCritical
+	}
+	return slog.LevelError
+}
+
+// LevelCritical is the log level for failure.SeverityCritical.
+const LevelCritical = slog.LevelError + 4
+
+// Log logs err with logger at the level decided by Level.
+// err is added to args as an attribute keyed by "error".
+func Log(ctx context.Context, logger *slog.Logger, err error, msg string, args ...any) {
+	logger.Log(ctx, Level(err), msg, append([]any{Error(err)}, args...)...)
+}
+
+// CallStack returns an attribute for cs.
+func CallStack(key string, cs failure.CallStack) slog.Attr {
+	return slog.Attr{Key: key, Value: CallStackValue(cs)}
+}
+
+// CallStackValue converts cs to a slog.Value.
+// The value is a group of frames keyed by their index, and each
+// frame is a group of func, file and line.
+func CallStackValue(cs failure.CallStack) slog.Value {
+	if cs == nil {
+		return slog.GroupValue()
+	}
+	if v, ok := cs.(slog.LogValuer); ok {
+		return v.LogValue()
+	}
+
+	fs := cs.Frames()
+	attrs := make([]slog.Attr, len(fs))
+	for i, f := range fs {
+		attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: FrameValue(f)}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// FrameValue converts f to a slog.Value.
+func FrameValue(f failure.Frame) slog.Value {
+	if v, ok := f.(slog.LogValuer); ok {
+		return v.LogValue()
+	}
+
+	return slog.GroupValue(
+		slog.String("func", f.Func()),
+		slog.String("file", f.Path()),
+		slog.Int("line", f.Line()),
+	)
+}
@@ -0,0 +1,89 @@
+package slogutil_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/slogutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type frames []failure.Frame
+
+func (fs frames) HeadFrame() failure.Frame                                { return fs[0] }
+func (fs frames) Frames() []failure.Frame                                 { return fs }
+
+func logJSON(t *testing.T, attr slog.Attr) map[string]interface{} {
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", attr)
+
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	return m
+}
+
+func TestCallStack(t *testing.T) {
+	cs := failure.Callers(0)
+
+	m := logJSON(t, slogutil.CallStack("stack", cs))
+	stack := m["stack"].(map[string]interface{})
+	head := stack["0"].(map[string]interface{})
+	assert.Equal(t, "TestCallStack", head["func"])
+	assert.Equal(t, float64(30), head["line"])
+	assert.Contains(t, head["file"], "slogutil_test.go")
+}
+
+func TestCallStackValue(t *testing.T) {
+	cs := frames(failure.CallStackOf(failure.New(failure.StringCode("a"))).Frames())
+
+	v := slogutil.CallStackValue(cs)
+	if assert.Equal(t, slog.KindGroup, v.Kind()) {
+		attrs := v.Group()
+		assert.Len(t, attrs, len(cs))
+		assert.Equal(t, "0", attrs[0].Key)
+	}
+
+	assert.Empty(t, slogutil.CallStackValue(nil).Group())
+}
+
+func TestError(t *testing.T) {
+	err := failure.New(failure.StringCode("not_found"), failure.Message("xxx"))
+
+	m := logJSON(t, slogutil.Error(err))
+	e := m["error"].(map[string]interface{})
+	assert.Equal(t, "not_found", e["code"])
+	assert.Equal(t, "xxx", e["message"])
+}
+
+func TestLevel(t *testing.T) {
+	newErr := func(s failure.Severity) error {
+		return failure.New(failure.StringCode("a"), failure.WithSeverity(s))
+	}
+
+	assert.Equal(t, slog.LevelDebug, slogutil.Level(newErr(failure.SeverityDebug)))
+	assert.Equal(t, slog.LevelInfo, slogutil.Level(newErr(failure.SeverityInfo)))
+	assert.Equal(t, slog.LevelWarn, slogutil.Level(newErr(failure.SeverityWarn)))
+	assert.Equal(t, slog.LevelError, slogutil.Level(newErr(failure.SeverityError)))
+	assert.Equal(t, slogutil.LevelCritical, slogutil.Level(newErr(failure.SeverityCritical)))
+	assert.Equal(t, slog.LevelError, slogutil.Level(failure.New(failure.StringCode("a"))))
+	assert.Equal(t, slog.LevelWarn, slogutil.Level(failure.Warn(failure.StringCode("a"), "warning")))
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := failure.New(failure.StringCode("a"), failure.WithSeverity(failure.SeverityWarn))
+	slogutil.Log(context.Background(), logger, err, "failed", "k", "v")
+
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "WARN", m["level"])
+	assert.Equal(t, "failed", m["msg"])
+	assert.Equal(t, "v", m["k"])
+	assert.Equal(t, "warn", m["error"].(map[string]interface{})["severity"])
+}
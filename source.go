@@ -0,0 +1,76 @@
+package failure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+const sourceContextLines = 2
+
+// SourceOf returns lines of the source code around f.
+// The returned lines start from max(1, f.Line()-contextLines) and end
+// at f.Line()+contextLines unless the file ends before it.
+func SourceOf(f Frame, contextLines int) ([]string, error) {
+	rf := RuntimeFrameOf(f)
+	return readSource(rf.File, rf.Line, contextLines)
+}
+
+func readSource(file string, line, contextLines int) ([]string, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("failure: invalid line number %d", line)
+	}
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	fp, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	first := line - contextLines
+	if first < 1 {
+		first = 1
+	}
+	last := line + contextLines
+
+	var lines []string
+	sc := bufio.NewScanner(fp)
+	for n := 1; n <= last && sc.Scan(); n++ {
+		if n >= first {
+			lines = append(lines, sc.Text())
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) <= line-first {
+		return nil, fmt.Errorf("failure: line %d is out of %s", line, file)
+	}
+	return lines, nil
+}
+
+// writeSource writes the source code around f with line numbers.
+// Nothing is written if the source is not available.
+func writeSource(w io.Writer, f Frame, contextLines int) {
+	lines, err := SourceOf(f, contextLines)
+	if err != nil {
+		return
+	}
+
+	first := f.Line() - contextLines
+	if first < 1 {
+		first = 1
+	}
+	for i, l := range lines {
+		n := first + i
+		mark := " "
+		if n == f.Line() {
+			mark = ">"
+		}
+		fmt.Fprintf(w, "  %s %5d | %s\n", mark, n, l)
+	}
+}
@@ -0,0 +1,60 @@
+package failure_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func sourceTarget() failure.CallStack {
+	return failure.Callers(0)
+}
+
+func TestSourceOf(t *testing.T) {
+	f := sourceTarget().HeadFrame()
+
+	lines, err := failure.SourceOf(f, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"func sourceTarget() failure.CallStack {",
+		"\treturn failure.Callers(0)",
+		"}",
+	}, lines)
+
+	lines, err = failure.SourceOf(f, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"\treturn failure.Callers(0)"}, lines)
+
+	lines, err = failure.SourceOf(f, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "package failure_test", lines[0])
+}
+
+func TestSourceOf_Error(t *testing.T) {
+	err := stackError{[]byte("f()\n\t/not/exist.go:10 +0x1\n")}
+
+	_, e := failure.SourceOf(failure.CallStackOf(err).HeadFrame(), 1)
+	assert.Error(t, e)
+
+	err = stackError{[]byte("f()\n\t" + sourceTarget().HeadFrame().Path() + ":10000 +0x1\n")}
+	_, e = failure.SourceOf(failure.CallStackOf(err).HeadFrame(), 1)
+	assert.Error(t, e)
+}
+
+func TestCallStack_Format_Source(t *testing.T) {
+	cs := sourceTarget()
+
+	assert.Regexp(t,
+		`^\[sourceTarget\] /.+/source_test.go:12
+       10 \| 
+       11 \| func sourceTarget\(\) failure.CallStack {
+  >    12 \| 	return failure.Callers\(0\)
+       13 \| }
+       14 \| 
+\[TestCallStack_Format_Source\] /.+/source_test.go:47
+`,
+		fmt.Sprintf("%#+v", cs),
+	)
+}
@@ -0,0 +1,85 @@
+package failure
+
+import "fmt"
+
+// WithParentStack appends the call stack of the goroutine which
+// spawned the current goroutine to an error.
+// %+v prints it beneath the call stack of the error, which restores
+// the causality lost at the goroutine boundary.
+// Go does it automatically.
+func WithParentStack(parent CallStack) Wrapper {
+	return WrapperFunc(func(err error) error {
+		return withParentStack{err, parent}
+	})
+}
+
+type withParentStack struct {
+	error
+	parent CallStack
+}
+
+func (w withParentStack) UnwrapError() error {
+	return w.error
+}
+
+func (w withParentStack) Unwrap() error {
+	return w.error
+}
+
+func (w withParentStack) GetParentCallStack() CallStack {
+	return w.parent
+}
+
+func (w withParentStack) detail(p palette) string {
+	if w.parent == nil {
+		return ""
+	}
+	return fmt.Sprintf("spawned_at(%s)", p.frame(w.parent.HeadFrame()))
+}
+
+// ParentCallStacksOf extracts the call stacks appended by
+// WithParentStack.
+// Returned call stacks are ordered from the outermost, so the last one
+// is the call stack of the goroutine spawning the goroutine where the
+// error occurred.
+func ParentCallStacksOf(err error) []CallStack {
+	if err == nil {
+		return nil
+	}
+
+	type parentCallStackGetter interface {
+		GetParentCallStack() CallStack
+	}
+
+	var css []CallStack
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(parentCallStackGetter); ok && g.GetParentCallStack() != nil {
+			css = append(css, g.GetParentCallStack())
+		}
+	}
+
+	return css
+}
+
+// Go calls f in a new goroutine, and sends the returned error to the
+// returned channel, which is closed after that.
+// The error is appended the call stack of the caller of Go by
+// WithParentStack.
+//
+//	errc := failure.Go(func() error {
+//		return fetch(ctx)
+//	})
+//	...
+//	if err := <-errc; err != nil {
+//		return err
+//	}
+func Go(f func() error) <-chan error {
+	parent := Callers(1)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		errc <- Custom(f(), WithParentStack(parent), WithFormatter())
+	}()
+	return errc
+}
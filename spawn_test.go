@@ -0,0 +1,46 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGo(t *testing.T) {
+	errc := failure.Go(func() error {
+		return failure.New(TestCodeA)
+	})
+	err := <-errc
+	_, ok := <-errc
+	assert.False(t, ok)
+
+	assert.Equal(t, TestCodeA, failure.CodeOf(err))
+	assert.Equal(t, "TestGo.func1", failure.CallStackOf(err).HeadFrame().Func())
+	pcss := failure.ParentCallStacksOf(err)
+	if assert.Len(t, pcss, 1) {
+		assert.Equal(t, "TestGo", pcss[0].HeadFrame().Func())
+		assert.Equal(t, 14, pcss[0].HeadFrame().Line())
+	}
+
+	s := fmt.Sprintf("%+v", err)
+	assert.Contains(t, s, "    spawned_at([TestGo] ")
+	callStack := strings.Index(s, "[CallStack]\n    [TestGo.func1] ")
+	parent := strings.Index(s, "[ParentCallStack]\n    [TestGo] ")
+	assert.True(t, callStack >= 0 && parent > callStack, s)
+
+	assert.Nil(t, <-failure.Go(func() error { return nil }))
+}
+
+func TestParentCallStacksOf(t *testing.T) {
+	outer := failure.Callers(0)
+	inner := failure.Callers(0)
+	err := failure.Wrap(failure.Wrap(io.EOF, failure.WithParentStack(inner)), failure.WithParentStack(outer))
+
+	assert.Equal(t, []failure.CallStack{outer, inner}, failure.ParentCallStacksOf(err))
+	assert.Nil(t, failure.ParentCallStacksOf(io.EOF))
+	assert.Nil(t, failure.ParentCallStacksOf(nil))
+}
@@ -0,0 +1,17 @@
+module github.com/morikuni/failure/sqlutil
+
+go 1.21
+
+replace github.com/morikuni/failure => ../
+
+require (
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/morikuni/failure v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.2.2
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
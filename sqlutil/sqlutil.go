@@ -0,0 +1,138 @@
+// Package sqlutil classifies errors of database/sql and its drivers
+// into errors of the failure package.
+//
+// Errors of MySQL are classified by github.com/go-sql-driver/mysql,
+// and errors of PostgreSQL drivers like github.com/jackc/pgx and
+// github.com/lib/pq are classified by their SQLSTATE.
+package sqlutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/morikuni/failure"
+)
+
+// Codes of errors of databases.
+// They share the "sqlutil" namespace so that
+// failure.CodeMatches(err, "sqlutil.*") matches all of them.
+const (
+	// NotFound means no row was found.
+	NotFound failure.StringCode = "sqlutil.not_found"
+	// Duplicate means a unique constraint was violated.
+	Duplicate failure.StringCode = "sqlutil.duplicate"
+	// Deadlock means the transaction was aborted by a deadlock.
+	Deadlock failure.StringCode = "sqlutil.deadlock"
+	// ConnLost means the connection to the database was lost.
+	ConnLost failure.StringCode = "sqlutil.conn_lost"
+	// Canceled means the context of the query was canceled.
+	Canceled failure.StringCode = "sqlutil.canceled"
+	// Timeout means the query timed out.
+	Timeout failure.StringCode = "sqlutil.timeout"
+	// Unknown means any other error.
+	Unknown failure.StringCode = "sqlutil.unknown"
+)
+
+// Keys of the debug information added by Translate.
+const (
+	// SQLStateKey is the key of the SQLSTATE of the error.
+	SQLStateKey = "sql_state"
+	// VendorCodeKey is the key of the vendor specific error number
+	// like 1062 of MySQL.
+	VendorCodeKey = "vendor_code"
+)
+
+// MySQL error numbers.
+const (
+	mysqlDuplicate = 1062
+	mysqlDeadlock  = 1213
+)
+
+// sqlStater is implemented by errors of PostgreSQL drivers.
+type sqlStater interface {
+	SQLState() string
+}
+
+// Classify returns the code for err returned by database/sql.
+func Classify(err error) failure.Code {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case mysqlDuplicate:
+			return Duplicate
+		case mysqlDeadlock:
+			return Deadlock
+		}
+		return Unknown
+	}
+
+	var pgErr sqlStater
+	if errors.As(err, &pgErr) {
+		state := pgErr.SQLState()
+		switch {
+		case state == "23505":
+			return Duplicate
+		case state == "40P01":
+			return Deadlock
+		case strings.HasPrefix(state, "08"):
+			// class 08 is connection exception.
+			return ConnLost
+		}
+		return Unknown
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFound
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, mysql.ErrInvalidConn):
+		return ConnLost
+	}
+	return Unknown
+}
+
+// Translate translates err into a failure error with the code decided
+// by Classify.
+// The SQLSTATE and the vendor specific error number are appended as
+// debug information keyed by SQLStateKey and VendorCodeKey if
+// available.
+// It returns nil if err is nil.
+//
+//	if err := row.Scan(&u.Name); err != nil {
+//		return sqlutil.Translate(err)
+//	}
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	debug := failure.Debug{}
+	var (
+		myErr *mysql.MySQLError
+		pgErr sqlStater
+	)
+	switch {
+	case errors.As(err, &myErr):
+		debug[VendorCodeKey] = int(myErr.Number)
+		if myErr.SQLState != [5]byte{} {
+			debug[SQLStateKey] = string(myErr.SQLState[:])
+		}
+	case errors.As(err, &pgErr):
+		debug[SQLStateKey] = pgErr.SQLState()
+	}
+
+	wrappers := []failure.Wrapper{failure.WithCallStackSkip(1)}
+	if len(debug) != 0 {
+		wrappers = append(wrappers, debug)
+	}
+	return failure.Translate(err, Classify(err), wrappers...)
+}
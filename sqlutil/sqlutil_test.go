@@ -0,0 +1,75 @@
+package sqlutil_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/sqlutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// pgError is an error like pgconn.PgError.
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string {
+	return "pg: " + e.code
+}
+
+func (e *pgError) SQLState() string {
+	return e.code
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want failure.Code
+	}{
+		"no rows":            {sql.ErrNoRows, sqlutil.NotFound},
+		"wrapped no rows":    {fmt.Errorf("query: %w", sql.ErrNoRows), sqlutil.NotFound},
+		"mysql duplicate":    {&mysql.MySQLError{Number: 1062}, sqlutil.Duplicate},
+		"mysql deadlock":     {&mysql.MySQLError{Number: 1213}, sqlutil.Deadlock},
+		"mysql other":        {&mysql.MySQLError{Number: 1146}, sqlutil.Unknown},
+		"mysql conn":         {mysql.ErrInvalidConn, sqlutil.ConnLost},
+		"pg duplicate":       {&pgError{"23505"}, sqlutil.Duplicate},
+		"pg deadlock":        {&pgError{"40P01"}, sqlutil.Deadlock},
+		"pg conn":            {&pgError{"08006"}, sqlutil.ConnLost},
+		"pg other":           {&pgError{"42P01"}, sqlutil.Unknown},
+		"bad conn":           {driver.ErrBadConn, sqlutil.ConnLost},
+		"conn done":          {sql.ErrConnDone, sqlutil.ConnLost},
+		"canceled":           {context.Canceled, sqlutil.Canceled},
+		"deadline":           {context.DeadlineExceeded, sqlutil.Timeout},
+		"unknown":            {io.EOF, sqlutil.Unknown},
+		"wrapped by failure": {failure.Wrap(&pgError{"23505"}), sqlutil.Duplicate},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, sqlutil.Classify(test.err))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	err := sqlutil.Translate(&mysql.MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}})
+	assert.Equal(t, sqlutil.Duplicate, failure.CodeOf(err))
+	assert.Equal(t, []failure.Debug{{"vendor_code": 1062, "sql_state": "23000"}}, failure.DebugsOf(err))
+	assert.Equal(t, "TestTranslate", failure.CallStackOf(err).HeadFrame().Func())
+
+	err = sqlutil.Translate(&pgError{"40P01"})
+	assert.Equal(t, sqlutil.Deadlock, failure.CodeOf(err))
+	assert.Equal(t, []failure.Debug{{"sql_state": "40P01"}}, failure.DebugsOf(err))
+
+	err = sqlutil.Translate(sql.ErrNoRows)
+	assert.Equal(t, sqlutil.NotFound, failure.CodeOf(err))
+	assert.Empty(t, failure.DebugsOf(err))
+
+	assert.Nil(t, sqlutil.Translate(nil))
+}
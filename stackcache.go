@@ -0,0 +1,80 @@
+package failure
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	stackCacheTTL int64 // time.Duration
+
+	stackCacheMu sync.Mutex
+	stackCache   map[stackCacheKey]stackCacheEntry
+)
+
+type stackCacheKey struct {
+	code Code
+	pc   uintptr
+}
+
+type stackCacheEntry struct {
+	callStack CallStack
+	expires   time.Time
+}
+
+// EnableStackCache makes New, Translate and Wrap reuse a call stack
+// captured within ttl for errors with the same code created at the
+// same place.
+// It cuts CPU time when the same error occurs many times a second,
+// but the cached call stack may have different callers of the place.
+// Passing 0 or less disables the cache, which is the default.
+// It is safe to call EnableStackCache concurrently.
+func EnableStackCache(ttl time.Duration) {
+	stackCacheMu.Lock()
+	defer stackCacheMu.Unlock()
+
+	if ttl <= 0 {
+		ttl = 0
+		stackCache = nil
+	} else {
+		stackCache = make(map[stackCacheKey]stackCacheEntry)
+	}
+	atomic.StoreInt64(&stackCacheTTL, int64(ttl))
+}
+
+// defaultCallStack returns the call stack of the caller skipping top N
+// of frames, which may be taken from the stack cache.
+// CodeOf(err) is used if code is nil.
+func defaultCallStack(err error, code Code, skip int) CallStack {
+	ttl := time.Duration(atomic.LoadInt64(&stackCacheTTL))
+	if ttl <= 0 {
+		return Callers(skip + 1)
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return Callers(skip + 1)
+	}
+	if code == nil {
+		code = CodeOf(err)
+	}
+	key := stackCacheKey{code, pcs[0]}
+	now := time.Now()
+
+	stackCacheMu.Lock()
+	e, ok := stackCache[key]
+	stackCacheMu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.callStack
+	}
+
+	cs := Callers(skip + 1)
+	stackCacheMu.Lock()
+	if stackCache != nil {
+		stackCache[key] = stackCacheEntry{cs, now.Add(ttl)}
+	}
+	stackCacheMu.Unlock()
+	return cs
+}
@@ -0,0 +1,50 @@
+package failure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedErrors(code failure.Code) (error, error) {
+	var errs []error
+	for i := 0; i < 2; i++ {
+		errs = append(errs, failure.New(code))
+	}
+	return errs[0], errs[1]
+}
+
+func TestEnableStackCache(t *testing.T) {
+	failure.EnableStackCache(time.Minute)
+	defer failure.EnableStackCache(0)
+
+	e1, e2 := newCachedErrors(TestCodeA)
+	assert.True(t, failure.CallStackOf(e1) == failure.CallStackOf(e2))
+	assert.Equal(t, "newCachedErrors", failure.CallStackOf(e1).HeadFrame().Func())
+
+	e3, _ := newCachedErrors(TestCodeB)
+	assert.False(t, failure.CallStackOf(e1) == failure.CallStackOf(e3))
+
+	w1, w2 := failure.Wrap(e1), failure.Wrap(e1)
+	assert.False(t, failure.CallStacksOf(w1)[0] == failure.CallStacksOf(w2)[0], "different places")
+
+	failure.EnableStackCache(time.Nanosecond)
+	e1, e2 = newCachedErrors(TestCodeA)
+	assert.False(t, failure.CallStackOf(e1) == failure.CallStackOf(e2), "expired")
+
+	failure.EnableStackCache(0)
+	e1, e2 = newCachedErrors(TestCodeA)
+	assert.False(t, failure.CallStackOf(e1) == failure.CallStackOf(e2))
+}
+
+func BenchmarkNew_StackCache(b *testing.B) {
+	failure.EnableStackCache(time.Minute)
+	defer failure.EnableStackCache(0)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		failure.New(TestCodeA)
+	}
+}
@@ -0,0 +1,171 @@
+package failure
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StatsEntry is the number of occurrences of errors and the time the
+// last one occurred.
+type StatsEntry struct {
+	Count    uint64    `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+	// Code is the error code, or an empty string if errors have no
+	// code.
+	Code string `json:"code,omitempty"`
+	// Error is err.Error() of the last error.
+	// It is set only for entries per fingerprint.
+	Error string `json:"error,omitempty"`
+}
+
+// StatsSnapshot is a snapshot of ErrorStats.
+type StatsSnapshot struct {
+	// Codes are the entries per error code.
+	Codes map[string]StatsEntry `json:"codes"`
+	// Fingerprints are the entries per Fingerprint.
+	Fingerprints map[string]StatsEntry `json:"fingerprints"`
+}
+
+// ErrorStats counts errors per code and per Fingerprint in process.
+// It is a Hook, so it counts errors created by New, Translate, Wrap and
+// WrapMultiple once registered by RegisterHook.
+// Errors wrapping errors already counted are not counted again, but
+// errors translated by Translate are counted with the new code.
+//
+// It implements expvar.Var and http.Handler to inspect the counts
+// without any metrics stack.
+//
+//	expvar.Publish("errors", failure.Stats())
+//	http.Handle("/debug/errors", failure.Stats())
+type ErrorStats struct {
+	mu           sync.Mutex
+	codes        map[string]*StatsEntry
+	fingerprints map[string]*StatsEntry
+}
+
+// NewErrorStats creates an ErrorStats.
+func NewErrorStats() *ErrorStats {
+	return &ErrorStats{
+		codes:        make(map[string]*StatsEntry),
+		fingerprints: make(map[string]*StatsEntry),
+	}
+}
+
+var (
+	statsOnce sync.Once
+	stats     *ErrorStats
+)
+
+// Stats returns the ErrorStats of the process.
+// The first call registers it by RegisterHook, so errors are counted
+// only after that.
+func Stats() *ErrorStats {
+	statsOnce.Do(func() {
+		stats = NewErrorStats()
+		RegisterHook(stats)
+	})
+	return stats
+}
+
+// HandleError implements the Hook interface.
+func (s *ErrorStats) HandleError(err error) {
+	if err == nil || counted(err) {
+		return
+	}
+
+	var code string
+	if c := CodeOf(err); c != nil {
+		code = c.ErrorCode()
+	}
+	fp := Fingerprint(err)
+	msg := err.Error()
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code != "" {
+		e := s.codes[code]
+		if e == nil {
+			e = &StatsEntry{Code: code}
+			s.codes[code] = e
+		}
+		e.Count++
+		e.LastSeen = now
+	}
+
+	e := s.fingerprints[fp]
+	if e == nil {
+		e = &StatsEntry{Code: code}
+		s.fingerprints[fp] = e
+	}
+	e.Count++
+	e.LastSeen = now
+	e.Error = msg
+}
+
+// counted reports whether err wraps an error created by this package
+// without a new code, which has been counted when it was created.
+func counted(err error) bool {
+	outermost := true
+	i := NewIterator(err)
+	for i.Next() {
+		switch i.Error().(type) {
+		case formatter:
+			if !outermost {
+				return true
+			}
+			outermost = false
+		case Failure:
+			return false
+		}
+	}
+	return false
+}
+
+// Snapshot returns a copy of the current counts.
+func (s *ErrorStats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := StatsSnapshot{
+		Codes:        make(map[string]StatsEntry, len(s.codes)),
+		Fingerprints: make(map[string]StatsEntry, len(s.fingerprints)),
+	}
+	for k, e := range s.codes {
+		ss.Codes[k] = *e
+	}
+	for k, e := range s.fingerprints {
+		ss.Fingerprints[k] = *e
+	}
+	return ss
+}
+
+// Reset clears the counts.
+func (s *ErrorStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes = make(map[string]*StatsEntry)
+	s.fingerprints = make(map[string]*StatsEntry)
+}
+
+// String returns the snapshot in JSON.
+// It implements expvar.Var.
+func (s *ErrorStats) String() string {
+	b, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// ServeHTTP writes the snapshot in JSON.
+// It implements http.Handler.
+func (s *ErrorStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, s.String())
+}
@@ -0,0 +1,75 @@
+package failure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStats(t *testing.T) {
+	s := failure.NewErrorStats()
+
+	newErr := func() error {
+		return failure.New(failure.StringCode("stats_not_found"))
+	}
+	err := newErr()
+	s.HandleError(err)
+	s.HandleError(newErr())
+	s.HandleError(failure.Wrap(err))
+	s.HandleError(failure.Wrap(errors.New("error")))
+	s.HandleError(nil)
+
+	ss := s.Snapshot()
+	if assert.Len(t, ss.Codes, 1) {
+		e := ss.Codes["stats_not_found"]
+		assert.Equal(t, uint64(2), e.Count)
+		assert.False(t, e.LastSeen.IsZero())
+	}
+	if assert.Len(t, ss.Fingerprints, 2) {
+		e := ss.Fingerprints[failure.Fingerprint(err)]
+		assert.Equal(t, uint64(2), e.Count)
+		assert.Equal(t, "stats_not_found", e.Code)
+		assert.Equal(t, err.Error(), e.Error)
+	}
+
+	var v expvar.Var = s
+	var got failure.StatsSnapshot
+	assert.NoError(t, json.Unmarshal([]byte(v.String()), &got))
+	assert.Equal(t, uint64(2), got.Codes["stats_not_found"].Count)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, v.String(), rec.Body.String())
+
+	s.Reset()
+	assert.Empty(t, s.Snapshot().Codes)
+}
+
+func TestErrorStats_Translate(t *testing.T) {
+	s := failure.NewErrorStats()
+
+	err := failure.New(failure.StringCode("stats_inner"))
+	s.HandleError(err)
+	translated := failure.Translate(err, failure.StringCode("stats_outer"))
+	s.HandleError(translated)
+	s.HandleError(failure.Wrap(translated))
+
+	ss := s.Snapshot()
+	assert.Equal(t, uint64(1), ss.Codes["stats_inner"].Count)
+	assert.Equal(t, uint64(1), ss.Codes["stats_outer"].Count)
+}
+
+func TestStats(t *testing.T) {
+	s := failure.Stats()
+	assert.True(t, s == failure.Stats())
+
+	failure.New(failure.StringCode("stats_global"))
+	assert.Equal(t, uint64(1), s.Snapshot().Codes["stats_global"].Count)
+}
@@ -0,0 +1,38 @@
+package failure
+
+import "time"
+
+// TimestampsOf extracts the times when err was created and wrapped by
+// New, Translate, Wrap and other constructors.
+// Returned timestamps are ordered from the outermost.
+func TimestampsOf(err error) []time.Time {
+	if err == nil {
+		return nil
+	}
+
+	type timestampGetter interface {
+		GetTimestamp() time.Time
+	}
+
+	var ts []time.Time
+	i := NewIterator(err)
+	for i.Next() {
+		if g, ok := i.Error().(timestampGetter); ok && !g.GetTimestamp().IsZero() {
+			ts = append(ts, g.GetTimestamp())
+		}
+	}
+
+	return ts
+}
+
+// PropagationTimeOf returns the duration from when err was created to
+// when it was wrapped last, which shows how long the error took to
+// propagate through layers like middlewares.
+// It returns 0 if err has less than two timestamps.
+func PropagationTimeOf(err error) time.Duration {
+	ts := TimestampsOf(err)
+	if len(ts) < 2 {
+		return 0
+	}
+	return ts[0].Sub(ts[len(ts)-1])
+}
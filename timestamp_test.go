@@ -0,0 +1,33 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampsOf(t *testing.T) {
+	before := time.Now()
+	err := failure.New(TestCodeA)
+	time.Sleep(10 * time.Millisecond)
+	err = failure.Wrap(err)
+	err = failure.Translate(err, TestCodeB)
+	after := time.Now()
+
+	ts := failure.TimestampsOf(err)
+	if assert.Len(t, ts, 3) {
+		assert.False(t, ts[2].Before(before))
+		assert.False(t, ts[0].After(after))
+		assert.False(t, ts[0].Before(ts[1]))
+		assert.True(t, ts[1].Sub(ts[2]) >= 10*time.Millisecond)
+	}
+	assert.Equal(t, ts[0].Sub(ts[2]), failure.PropagationTimeOf(err))
+
+	assert.Len(t, failure.TimestampsOf(failure.Wrap(io.EOF)), 1)
+	assert.Equal(t, time.Duration(0), failure.PropagationTimeOf(failure.Wrap(io.EOF)))
+	assert.Nil(t, failure.TimestampsOf(io.EOF))
+	assert.Nil(t, failure.TimestampsOf(nil))
+}
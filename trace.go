@@ -0,0 +1,37 @@
+package failure
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+	"sync/atomic"
+)
+
+// TraceEventKey is the key of the debug information added by
+// WithTraceTask.
+const TraceEventKey = "trace_event"
+
+var traceEventSeq uint64
+
+// WithTraceTask records an error as a log event of runtime/trace in
+// the task of ctx, and appends the ID of the event to the error as
+// debug information with TraceEventKey, so that error dumps can be
+// looked up in the trace by "go tool trace".
+// The event has the category "failure" and the message like
+// "trace_event=1 code(not_found): user not found".
+// It does nothing unless tracing is enabled.
+//
+//	ctx, task := trace.NewTask(ctx, "GetUser")
+//	defer task.End()
+//	...
+//	return failure.Wrap(err, failure.WithTraceTask(ctx))
+func WithTraceTask(ctx context.Context) Wrapper {
+	return WrapperFunc(func(err error) error {
+		if !trace.IsEnabled() {
+			return err
+		}
+		id := atomic.AddUint64(&traceEventSeq, 1)
+		trace.Log(ctx, "failure", fmt.Sprintf("%s=%d %s", TraceEventKey, id, err.Error()))
+		return &withDebug{err, Debug{TraceEventKey: id}}
+	})
+}
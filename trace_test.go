@@ -0,0 +1,36 @@
+package failure_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime/trace"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTraceTask(t *testing.T) {
+	ctx, task := trace.NewTask(context.Background(), "test")
+	defer task.End()
+
+	if !trace.IsEnabled() {
+		err := failure.Wrap(io.EOF, failure.WithTraceTask(ctx))
+		assert.Nil(t, failure.ContextOf(err))
+
+		var buf bytes.Buffer
+		if err := trace.Start(&buf); err != nil {
+			t.Fatal(err)
+		}
+		defer trace.Stop()
+	}
+
+	err1 := failure.Wrap(io.EOF, failure.WithTraceTask(ctx))
+	err2 := failure.Wrap(io.EOF, failure.WithTraceTask(ctx))
+	id1 := failure.ContextOf(err1)[failure.TraceEventKey]
+	id2 := failure.ContextOf(err2)[failure.TraceEventKey]
+	assert.NotNil(t, id1)
+	assert.NotNil(t, id2)
+	assert.NotEqual(t, id1, id2)
+}
@@ -0,0 +1,80 @@
+package failure
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Trail returns err in one line per call of New, Translate, Wrap and
+// so on from the outermost, which has the frame of the call, the code,
+// the message and the annotation of WrapHere.
+// It is much shorter than %+v, which prints entire call stacks.
+//
+//	[GetUser] /src/service/user.go:20 at(service.GetUser)
+//	[FindUser] /src/repo/user.go:42 code(not_found) message("user not found")
+//	error("sql: no rows in result set")
+func Trail(err error) string {
+	var sb strings.Builder
+	writeTrail(&sb, err)
+	return sb.String()
+}
+
+// TrailFormat is a Formatter printing Trail for %+v, which is suitable
+// for production logs.
+// It is the same as DefaultFormat for the other verbs.
+//
+//	failure.SetFormatter(failure.FormatterFunc(failure.TrailFormat))
+func TrailFormat(s fmt.State, verb rune, err error) {
+	if verb == 'v' && s.Flag('+') && !s.Flag('#') {
+		writeTrail(s, err)
+		return
+	}
+	DefaultFormat(s, verb, err)
+}
+
+func writeTrail(w io.Writer, err error) {
+	type annotationGetter interface {
+		GetAnnotation() string
+	}
+	type messageGetter interface {
+		GetMessage() string
+	}
+
+	var (
+		line []string
+		last error
+	)
+	flush := func() {
+		if len(line) != 0 {
+			fmt.Fprintf(w, "%s\n", strings.Join(line, " "))
+		}
+		line = nil
+	}
+
+	i := NewIterator(err)
+	for i.Next() {
+		e := i.Error()
+		last = e
+		switch t := e.(type) {
+		case formatter:
+			flush()
+		case annotationGetter:
+			line = append(line, fmt.Sprintf("at(%s)", t.GetAnnotation()))
+		case messageGetter:
+			line = append(line, fmt.Sprintf("message(%q)", t.GetMessage()))
+		default:
+			if cs := i.CallStack(); cs != nil {
+				line = append([]string{fmt.Sprintf("%+v", cs.HeadFrame())}, line...)
+			} else if c := i.Code(); c != nil {
+				line = append(line, fmt.Sprintf("code(%s)", c.ErrorCode()))
+			}
+		}
+	}
+	flush()
+
+	// The innermost error not created by New.
+	if last != nil && (&Iterator{err: last}).Code() == nil {
+		fmt.Fprintf(w, "error(%q)\n", last.Error())
+	}
+}
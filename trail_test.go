@@ -0,0 +1,41 @@
+package failure_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrail(t *testing.T) {
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("user not found"),
+		failure.WithStaticCallStack(failure.MustParseFrames("repo.FindUser /src/repo/user.go:42")...),
+	)
+	err = failure.Wrap(err,
+		failure.Debug{"id": "1"},
+		failure.WithStaticCallStack(failure.MustParseFrames("service.GetUser /src/service/user.go:20")...),
+	)
+
+	want := `[GetUser] /src/service/user.go:20
+[FindUser] /src/repo/user.go:42 message("user not found") code(not_found)
+error("EOF")
+`
+	assert.Equal(t, want, failure.Trail(err))
+
+	assert.Equal(t, "[FindUser] /src/repo/user.go:42 code(not_found)\n", failure.Trail(failure.New(failure.StringCode("not_found"),
+		failure.WithStaticCallStack(failure.MustParseFrames("repo.FindUser /src/repo/user.go:42")...),
+	)))
+	assert.Equal(t, "", failure.Trail(nil))
+}
+
+func TestTrailFormat(t *testing.T) {
+	defer failure.SetFormatter(nil)
+	failure.SetFormatter(failure.FormatterFunc(failure.TrailFormat))
+
+	err := failure.Wrap(io.EOF, failure.WithStaticCallStack(failure.MustParseFrames("main.f /src/main.go:1")...))
+	assert.Equal(t, "[f] /src/main.go:1\nerror(\"EOF\")\n", fmt.Sprintf("%+v", err))
+	assert.Equal(t, "f: EOF", fmt.Sprintf("%v", err))
+}
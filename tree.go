@@ -0,0 +1,89 @@
+package failure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tree returns the wrapped layers of err as an ASCII tree.
+// Each line is a layer from the outermost, and errors joined by
+// errors.Join or WrapMultiple are printed as branches.
+//
+//	[Handler] /app/handler.go:20
+//	code(internal)
+//	errors(2)
+//	├─ [Save] /app/db.go:12
+//	│  code(conflict)
+//	│  error("duplicate key")
+//	└─ error("rollback failed")
+func Tree(err error) string {
+	if err == nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeTree(&sb, &chainGuard{}, err, "", "")
+	return sb.String()
+}
+
+func writeTree(sb *strings.Builder, g *chainGuard, err error, first, rest string) {
+	type multiUnwrapper interface {
+		Unwrap() []error
+	}
+
+	prefix := first
+	for err != nil {
+		if !g.visit(err) {
+			fmt.Fprintf(sb, "%s... (truncated)\n", prefix)
+			return
+		}
+
+		switch err.(type) {
+		case formatter, withCollector:
+			err = (&Iterator{err: err}).unwrapError()
+			continue
+		}
+
+		if m, ok := err.(multiUnwrapper); ok {
+			var errs []error
+			for _, e := range m.Unwrap() {
+				if e != nil {
+					errs = append(errs, e)
+				}
+			}
+			fmt.Fprintf(sb, "%serrors(%d)\n", prefix, len(errs))
+			for i, e := range errs {
+				if i == len(errs)-1 {
+					writeTree(sb, g, e, rest+"└─ ", rest+"   ")
+				} else {
+					writeTree(sb, g, e, rest+"├─ ", rest+"│  ")
+				}
+			}
+			return
+		}
+
+		fmt.Fprintf(sb, "%s%s\n", prefix, treeLabel(err))
+		prefix = rest
+		err = (&Iterator{err: err}).unwrapError()
+	}
+}
+
+func treeLabel(err error) string {
+	if d, ok := err.(detailer); ok {
+		if s := d.detail(palette{}); s != "" {
+			return s
+		}
+	}
+
+	// Errors outside of this package may implement the getters.
+	i := &Iterator{err: err}
+	if cs := i.CallStack(); cs != nil {
+		return fmt.Sprintf("%+v", cs.HeadFrame())
+	}
+	if c := i.Code(); c != nil {
+		return fmt.Sprintf("code(%s)", c.ErrorCode())
+	}
+	if m := i.Message(); m != "" {
+		return fmt.Sprintf("message(%q)", m)
+	}
+	return fmt.Sprintf("error(%q)", err.Error())
+}
@@ -0,0 +1,66 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	failure.SetPathTrimmer(failure.TrimGoPaths())
+	defer failure.SetPathTrimmer(nil)
+
+	conflict := failure.Translate(io.EOF, TestCodeB, failure.Message("conflict"), failure.Debug{"b": 2, "a": 1})
+	joined := errors.Join(conflict, fmt.Errorf("rollback: %w", io.ErrClosedPipe))
+	err := failure.Translate(joined, TestCodeA, failure.MarkRetryable(), failure.WithSeverity(failure.SeverityWarn))
+
+	want := `[TestTree] github.com/morikuni/failure/tree_test.go:21
+severity(warn)
+retryable(true)
+code(code_a)
+errors(2)
+├─ [TestTree] github.com/morikuni/failure/tree_test.go:19
+│  debug(a=1, b=2)
+│  message("conflict")
+│  code(1)
+│  error("EOF")
+└─ error("rollback: io: read/write on closed pipe")
+   error("io: read/write on closed pipe")
+`
+	assert.Equal(t, want, failure.Tree(err))
+}
+
+func TestTree_Nested(t *testing.T) {
+	err := errors.Join(errors.Join(io.EOF, io.ErrUnexpectedEOF), nil, io.ErrClosedPipe)
+
+	want := `errors(2)
+├─ errors(2)
+│  ├─ error("EOF")
+│  └─ error("unexpected EOF")
+└─ error("io: read/write on closed pipe")
+`
+	assert.Equal(t, want, failure.Tree(err))
+	assert.Equal(t, "", failure.Tree(nil))
+}
+
+func TestTree_Wrappers(t *testing.T) {
+	err := failure.Wrap(io.EOF,
+		failure.WithRetryAfter(time.Second),
+		failure.WithPayload(1),
+		failure.WithPublicMessage("oops"),
+	)
+
+	want := `public_message("oops")
+payload(int)
+retry_after(1s)
+error("EOF")
+`
+	got := failure.Tree(err)
+	assert.True(t, strings.HasSuffix(got, want), got)
+}
@@ -0,0 +1,42 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate reports structural problems of err, which are usually
+// caused by errors created by hand or restored from broken data.
+// It returns nil if err has no problem.
+// The problems are the following.
+//
+//   - A layer has a nil code like New(nil).
+//   - A call stack has no frame.
+//   - Debug information has an empty key.
+//   - The chain of err is cyclic or too deep to iterate.
+func Validate(err error) error {
+	type codeGetter interface {
+		GetCode() Code
+	}
+
+	var problems []error
+	i := NewIterator(err)
+	n := 0
+	for i.Next() {
+		e := i.Error()
+		if g, ok := e.(codeGetter); ok && g.GetCode() == nil {
+			problems = append(problems, fmt.Errorf("failure: layer %d has nil code", n))
+		}
+		if cs := i.CallStack(); cs != nil && len(cs.Frames()) == 0 {
+			problems = append(problems, fmt.Errorf("failure: layer %d has empty call stack", n))
+		}
+		if _, ok := i.Debug()[""]; ok {
+			problems = append(problems, fmt.Errorf("failure: layer %d has empty debug key", n))
+		}
+		n++
+	}
+	if i.Truncated() {
+		problems = append(problems, fmt.Errorf("failure: chain is truncated after %d layers", n))
+	}
+	return errors.Join(problems...)
+}
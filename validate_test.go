@@ -0,0 +1,23 @@
+package failure_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, failure.Validate(nil))
+	assert.NoError(t, failure.Validate(io.EOF))
+	assert.NoError(t, failure.Validate(failure.New(failure.StringCode("code"), failure.Debug{"k": 1})))
+
+	err := failure.New(nil,
+		failure.WithStaticCallStack(),
+		failure.Debug{"": 1},
+	)
+	assert.EqualError(t, failure.Validate(err), "failure: layer 1 has empty debug key\n"+
+		"failure: layer 2 has empty call stack\n"+
+		"failure: layer 3 has nil code")
+}
@@ -2,10 +2,12 @@ package failure
 
 import (
 	"fmt"
-
-	"io"
-
-	"github.com/pkg/errors"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Unwrapper interface is used by iterator.
@@ -46,10 +48,18 @@ func (w withMessage) UnwrapError() error {
 	return w.error
 }
 
+func (w withMessage) Unwrap() error {
+	return w.error
+}
+
 func (w withMessage) GetMessage() string {
 	return w.message
 }
 
+func (w withMessage) detail(p palette) string {
+	return p.paint(p.message, fmt.Sprintf("message(%q)", w.message))
+}
+
 // MessageOf extracts the message from err.
 func MessageOf(err error) string {
 	if err == nil {
@@ -77,9 +87,11 @@ type Debug map[string]interface{}
 
 // WrapError implements the Wrapper interface.
 func (d Debug) WrapError(err error) error {
-	return withDebug{err, d}
+	return &withDebug{err, d}
 }
 
+// withDebug is used as a pointer because errors.Is compares errors
+// with ==, which panics for structs having a map.
 type withDebug struct {
 	error
 	debug Debug
@@ -89,11 +101,34 @@ func (w withDebug) UnwrapError() error {
 	return w.error
 }
 
+func (w withDebug) Unwrap() error {
+	return w.error
+}
+
 func (w withDebug) GetDebug() Debug {
+	return redactDebug(w.debug)
+}
+
+func (w withDebug) getUnredactedDebug() Debug {
 	return w.debug
 }
 
+func (w withDebug) detail(p palette) string {
+	d := w.GetDebug()
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]string, len(keys))
+	for i, k := range keys {
+		kvs[i] = fmt.Sprintf("%s=%v", k, d[k])
+	}
+	return fmt.Sprintf("debug(%s)", strings.Join(kvs, ", "))
+}
+
 // DebugsOf extracts list of information from the error.
+// Values of keys marked by MarkSensitive are redacted.
 func DebugsOf(err error) []Debug {
 	if err == nil {
 		return nil
@@ -115,16 +150,100 @@ func DebugsOf(err error) []Debug {
 	return debugs
 }
 
+// ContextOf flattens the debug information of all layers into one map.
+// If the same key is appended more than once, the outermost one is
+// used. Values of keys marked by MarkSensitive are redacted.
+// It returns nil if err has no debug information.
+//
+// Each layer keeps only its own debug information, so stacking layers
+// never copies the ones of wrapped layers, and the map is built only
+// when ContextOf is called.
+func ContextOf(err error) map[string]interface{} {
+	debugs := DebugsOf(err)
+	if len(debugs) == 0 {
+		return nil
+	}
+	return mergeDebugs(debugs)
+}
+
+// ValueOf returns the debug value for key from err.
+// If the key is appended more than once, the outermost one is returned.
+// The value is not redacted even if the key is marked by MarkSensitive.
+func ValueOf(err error, key string) (interface{}, bool) {
+	for _, d := range UnredactedDebugsOf(err) {
+		if v, ok := d[key]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// ValueAs returns the debug value for key from err as T.
+// It returns false if the key does not exist or the value is not T.
+//
+//	err := failure.New(NotFound, failure.Debug{"user_id": 42})
+//	id, ok := failure.ValueAs[int](err, "user_id") // 42, true
+func ValueAs[T any](err error, key string) (T, bool) {
+	v, ok := ValueOf(err, key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
 // WithCallStackSkip appends call stack to an error
 // skipping top N of frames.
+// When it is passed to New, Translate or Wrap, the constructor uses
+// it instead of appending its own call stack, so that helper
+// functions can exclude themselves from the call stack.
+//
+//	func NotFound(id string) error {
+//		return failure.New(NotFound, failure.WithCallStackSkip(1))
+//	}
 func WithCallStackSkip(skip int) Wrapper {
-	cs := Callers(skip + 1)
-	return WrapperFunc(func(err error) error {
-		return withCallStack{
-			err,
-			cs,
+	return WithCallStack(Callers(skip + 1))
+}
+
+// WithCallStack appends given call stack to an error.
+// Like WithCallStackSkip, constructors use it instead of their own
+// call stack.
+func WithCallStack(cs CallStack) Wrapper {
+	return callStackWrapper{cs}
+}
+
+// WithStaticCallStack appends a call stack made of given frames to an
+// error instead of the one captured by constructors.
+// It is meant to be used in tests to make errors reproducible.
+func WithStaticCallStack(frames ...Frame) Wrapper {
+	return WithCallStack(NewCallStackFromFrames(frames...))
+}
+
+type callStackWrapper struct {
+	callStack CallStack
+}
+
+func (w callStackWrapper) WrapError(err error) error {
+	if w.callStack == nil {
+		return err
+	}
+	return withCallStack{
+		err,
+		w.callStack,
+	}
+}
+
+// withDefaultCallStack appends call stack of the caller skipping top
+// N of frames to wrappers unless wrappers already have one.
+// err and code are used to look up the stack cache.
+func withDefaultCallStack(wrappers []Wrapper, err error, code Code, skip int) []Wrapper {
+	for _, w := range wrappers {
+		if _, ok := w.(callStackWrapper); ok {
+			return wrappers
 		}
-	})
+	}
+	return append(wrappers[:len(wrappers):len(wrappers)], WithCallStack(defaultCallStack(err, code, skip+1)))
 }
 
 type withCallStack struct {
@@ -140,117 +259,229 @@ func (w withCallStack) UnwrapError() error {
 	return w.err
 }
 
+func (w withCallStack) Unwrap() error {
+	return w.err
+}
+
 func (w withCallStack) GetCallStack() CallStack {
 	return w.callStack
 }
 
+func (w withCallStack) detail(p palette) string {
+	return p.frame(w.callStack.HeadFrame())
+}
+
 // CallStackOf extracts call stack from the error.
 // Returned call stack is for the most deepest place (appended first).
+//
+// In addition to the call stack appended by this package, it
+// recognizes the errors having one of the following methods, and
+// the errors recognized by CallStackExtractors registered by
+// RegisterCallStackExtractor.
+//
+//	StackTrace() errors.StackTrace // github.com/pkg/errors
+//	Callers() []uintptr            // program counters
+//	Stack() []byte                 // output of runtime/debug.Stack
+//
+// The StackTrace method is recognized by its shape, a slice of program
+// counters, so this package does not depend on github.com/pkg/errors.
 func CallStackOf(err error) CallStack {
+	css := CallStacksOf(err)
+	if len(css) == 0 {
+		return nil
+	}
+	return css[len(css)-1]
+}
+
+// CallStacksOf extracts all call stacks from the error.
+// Returned call stacks are ordered from the outermost.
+// See CallStackOf for the errors recognized as having call stack.
+func CallStacksOf(err error) []CallStack {
 	if err == nil {
 		return nil
 	}
 
+	var css []CallStack
+	i := NewIterator(err)
+	for i.Next() {
+		if cs := i.CallStack(); cs != nil {
+			css = append(css, cs)
+		}
+	}
+
+	return css
+}
+
+// callStackOfLayer returns the call stack of err itself without
+// unwrapping it.
+func callStackOfLayer(err error) CallStack {
 	type callStackGetter interface {
 		GetCallStack() CallStack
 	}
-	type stackTracer interface {
-		StackTrace() errors.StackTrace
+	type callerser interface {
+		Callers() []uintptr
+	}
+	type stacker interface {
+		Stack() []byte
 	}
 
-	var last CallStack
-	i := NewIterator(err)
-	for i.Next() {
-		err := i.Error()
-		switch t := err.(type) {
-		case callStackGetter:
-			last = t.GetCallStack()
-		case stackTracer:
-			last = callStackFromPkgErrors(t.StackTrace())
+	if g, ok := err.(callStackGetter); ok {
+		return g.GetCallStack()
+	}
+	if cs := stackTraceOf(err); cs != nil {
+		return cs
+	}
+	switch t := err.(type) {
+	case callerser:
+		return newCallStack(t.Callers())
+	case stacker:
+		if fs := parseDebugStack(t.Stack()); len(fs) != 0 {
+			return newCallStackFromFrames(fs)
+		}
+	}
+
+	callStackExtractorsMu.RLock()
+	es := callStackExtractors
+	callStackExtractorsMu.RUnlock()
+
+	for _, e := range es {
+		if cs := e(err); cs != nil {
+			return cs
 		}
 	}
+	return nil
+}
+
+// stackTraceOf returns the call stack of err having the StackTrace
+// method of github.com/pkg/errors, which returns a slice of program
+// counters.
+func stackTraceOf(err error) CallStack {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	mt := m.Type()
+	if mt.NumIn() != 0 || mt.NumOut() != 1 {
+		return nil
+	}
+	if out := mt.Out(0); out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
 
-	return last
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return newCallStack(pcs)
+}
+
+// CallStackExtractor extracts the call stack from an error created by
+// another package.
+// It should return nil if err has no call stack, and should not
+// unwrap err.
+type CallStackExtractor func(err error) CallStack
+
+var (
+	callStackExtractorsMu sync.RWMutex
+	callStackExtractors   []CallStackExtractor
+)
+
+// RegisterCallStackExtractor registers the CallStackExtractor used by
+// CallStackOf and other functions looking for call stacks.
+// Extractors are tried in the registered order.
+//
+//	failure.RegisterCallStackExtractor(pkgerrors.Extract)
+func RegisterCallStackExtractor(e CallStackExtractor) {
+	callStackExtractorsMu.Lock()
+	defer callStackExtractorsMu.Unlock()
+
+	callStackExtractors = append(callStackExtractors[:len(callStackExtractors):len(callStackExtractors)], e)
 }
 
 // WithFormatter appends error formatter to an error.
 //
-//     %v+: Print trace for each place, and deepest call stack.
-//     %#v: Print raw structure of the error.
-//     others (%s, %v): Same as err.Error().
+//	%v+: Print trace for each place, and call stacks depending on
+//	     the mode set by SetCallStackMode. Goroutines appended by
+//	     WithAllGoroutines are printed as well.
+//	%#v: Print the layers of the error in Go syntax.
+//	others (%s, %v): Same as err.Error().
 func WithFormatter() Wrapper {
 	return WrapperFunc(func(err error) error {
-		return formatter{err}
+		return formatter{err, time.Now()}
 	})
 }
 
+// formatter is added by all the constructors, so it also records the
+// time when the error is created or wrapped.
 type formatter struct {
 	error
+	timestamp time.Time
+}
+
+// Error joins the layers as set by SetErrorChain.
+func (f formatter) Error() string {
+	if c := chainConfigOf(); c != nil {
+		return c.chainString(f.error)
+	}
+	return f.error.Error()
 }
 
 func (f formatter) UnwrapError() error {
 	return f.error
 }
 
-func (f formatter) Format(s fmt.State, verb rune) {
-	if verb != 'v' { // %s
-		io.WriteString(s, f.Error())
-		return
-	}
+func (f formatter) Unwrap() error {
+	return f.error
+}
 
-	if s.Flag('#') { // %#v
-		type formatter struct {
-			error
-		}
-		fmt.Fprintf(s, "%#v", formatter{f.error})
-		return
-	}
+func (f formatter) GetTimestamp() time.Time {
+	return f.timestamp
+}
 
-	if !s.Flag('+') { // %v
-		io.WriteString(s, f.Error())
-		return
-	}
+func (f formatter) detail(p palette) string {
+	return ""
+}
 
-	// %+v
-	type callStacker interface {
-		GetCallStack() CallStack
+// LogValue implements the slog.LogValuer interface.
+// The error is logged as a group of its code, message, debug
+// information and call stack.
+func (f formatter) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("error", f.Error())}
+
+	if c := CodeOf(f); c != nil {
+		attrs = append(attrs, slog.String("code", c.ErrorCode()))
 	}
-	type debugger interface {
-		GetDebug() Debug
+	if msg := MessageOf(f); msg != "" {
+		attrs = append(attrs, slog.String("message", msg))
 	}
-	type messenger interface {
-		GetMessage() string
+	attrs = append(attrs, slog.String("severity", SeverityOf(f).String()))
+	if ctx := ContextOf(f); ctx != nil {
+		attrs = append(attrs, slog.Attr{Key: "debug", Value: contextLogValue(ctx)})
 	}
-	type coder interface {
-		GetCode() Code
+	if cs := CallStackOf(f); cs != nil {
+		attrs = append(attrs, slog.Any("stack", cs))
 	}
 
-	i := NewIterator(f.error)
-	for i.Next() {
-		err := i.Error()
-		switch t := err.(type) {
-		case callStacker:
-			fmt.Fprintf(s, "%+v\n", t.GetCallStack().HeadFrame())
-		case debugger:
-			debug := t.GetDebug()
-			for k, v := range debug {
-				fmt.Fprintf(s, "    %s = %v\n", k, v)
-			}
-		case messenger:
-			fmt.Fprintf(s, "    message(%q)\n", t.GetMessage())
-		case coder:
-			fmt.Fprintf(s, "    code(%s)\n", t.GetCode().ErrorCode())
-		case formatter:
-			// do nothing
-		default:
-			fmt.Fprintf(s, "    error(%q)\n", err.Error())
-		}
+	return slog.GroupValue(attrs...)
+}
+
+// contextLogValue converts the context from ContextOf into a group
+// ordered by the keys.
+func contextLogValue(ctx map[string]interface{}) slog.Value {
+	keys := make([]string, 0, len(ctx))
+	for k := range ctx {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	fmt.Fprint(s, "[CallStack]\n")
-	if cs := CallStackOf(f); cs != nil {
-		for _, f := range cs.Frames() {
-			fmt.Fprintf(s, "    %+v\n", f)
-		}
+	attrs := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		attrs[i] = slog.Any(k, ctx[k])
 	}
+	return slog.GroupValue(attrs...)
+}
+
+func (f formatter) Format(s fmt.State, verb rune) {
+	formatterOf().FormatError(s, verb, f.error)
 }
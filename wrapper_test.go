@@ -0,0 +1,262 @@
+package failure_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/stretchr/testify/assert"
+)
+
+type callersError struct {
+	pcs []uintptr
+}
+
+func (e callersError) Error() string {
+	return "callers"
+}
+
+func (e callersError) Callers() []uintptr {
+	return e.pcs
+}
+
+func newCallersError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return callersError{pcs[:n]}
+}
+
+type stackError struct {
+	stack []byte
+}
+
+func (e stackError) Error() string {
+	return "stack"
+}
+
+func (e stackError) Stack() []byte {
+	return e.stack
+}
+
+func newStackError() error {
+	return stackError{debug.Stack()}
+}
+
+func TestCallStackOf(t *testing.T) {
+	tests := map[string]struct {
+		err error
+
+		wantFuncs []string
+	}{
+		"callers": {
+			err:       newCallersError(),
+			wantFuncs: []string{"newCallersError", "TestCallStackOf"},
+		},
+		"debug stack": {
+			err:       newStackError(),
+			wantFuncs: []string{"Stack", "newStackError", "TestCallStackOf"},
+		},
+		"std wrap": {
+			err:       fmt.Errorf("wrap: %w", newCallersError()),
+			wantFuncs: []string{"newCallersError", "TestCallStackOf"},
+		},
+		"deepest": {
+			err:       failure.Wrap(newCallersError()),
+			wantFuncs: []string{"newCallersError", "TestCallStackOf"},
+		},
+		"invalid debug stack": {
+			err:       stackError{[]byte("invalid")},
+			wantFuncs: nil,
+		},
+		"no stack": {
+			err:       io.EOF,
+			wantFuncs: nil,
+		},
+	}
+
+	for title, test := range tests {
+		t.Run(title, func(t *testing.T) {
+			cs := failure.CallStackOf(test.err)
+			if test.wantFuncs == nil {
+				assert.Nil(t, cs)
+				return
+			}
+
+			fs := cs.Frames()
+			if assert.True(t, len(fs) >= len(test.wantFuncs)) {
+				for i, f := range test.wantFuncs {
+					assert.Equal(t, f, fs[i].Func())
+				}
+			}
+		})
+	}
+}
+
+func TestCallStackOf_DebugStack(t *testing.T) {
+	err := stackError{[]byte(`goroutine 1 [running]:
+main.(*T).f(0x1, 0x2)
+	/path/to/main.go:10 +0x1d
+main.main()
+	/path/to/main.go:15 +0x25
+created by main.init.0 in goroutine 1
+	/path/to/main.go:20 +0x2f
+`)}
+
+	fs := failure.CallStackOf(err).Frames()
+	if assert.Len(t, fs, 3) {
+		assert.Equal(t, "(*T).f", fs[0].Func())
+		assert.Equal(t, "main", fs[0].Pkg())
+		assert.Equal(t, "/path/to/main.go", fs[0].Path())
+		assert.Equal(t, 10, fs[0].Line())
+		assert.Equal(t, "main", fs[1].Func())
+		assert.Equal(t, 15, fs[1].Line())
+		assert.Equal(t, "init.0", fs[2].Func())
+		assert.Equal(t, 20, fs[2].Line())
+	}
+}
+
+func TestFormatter_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := failure.Translate(io.EOF, TestCodeA,
+		failure.Message("xxx"),
+		failure.Debug{"zzz": true, "aaa": 1},
+	)
+	logger.Error("failed", "err", err)
+
+	var got struct {
+		Err struct {
+			Error   string                 `json:"error"`
+			Code    string                 `json:"code"`
+			Message string                 `json:"message"`
+			Debug   map[string]interface{} `json:"debug"`
+			Stack   map[string]struct {
+				Func string `json:"func"`
+				File string `json:"file"`
+				Line int    `json:"line"`
+			} `json:"stack"`
+		} `json:"err"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "TestFormatter_LogValue: code(code_a): EOF", got.Err.Error)
+	assert.Equal(t, "code_a", got.Err.Code)
+	assert.Equal(t, "xxx", got.Err.Message)
+	assert.Equal(t, map[string]interface{}{"zzz": true, "aaa": float64(1)}, got.Err.Debug)
+	assert.Equal(t, "TestFormatter_LogValue", got.Err.Stack["0"].Func)
+	assert.Contains(t, got.Err.Stack["0"].File, "wrapper_test.go")
+}
+
+type customError struct {
+	msg string
+}
+
+func (e *customError) Error() string {
+	return e.msg
+}
+
+func TestStdErrors(t *testing.T) {
+	cause := &customError{"xxx"}
+	err := failure.Wrap(
+		failure.Translate(cause, TestCodeA, failure.Message("yyy"), failure.Debug{"zzz": true}),
+	)
+
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, io.EOF))
+
+	var ce *customError
+	if assert.True(t, errors.As(err, &ce)) {
+		assert.Equal(t, cause, ce)
+	}
+
+	var f failure.Failure
+	if assert.True(t, errors.As(err, &f)) {
+		assert.Equal(t, TestCodeA, f.GetCode())
+	}
+
+	last := err
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		last = e
+	}
+	assert.Equal(t, cause, last)
+}
+
+func TestStdErrors_IsCode(t *testing.T) {
+	errA := failure.New(TestCodeA)
+	errB := failure.New(TestCodeB)
+
+	err := failure.Wrap(failure.Translate(io.EOF, TestCodeA))
+
+	assert.True(t, errors.Is(err, errA))
+	assert.False(t, errors.Is(err, errB))
+	assert.True(t, errors.Is(failure.Translate(err, TestCodeB), errA))
+	assert.True(t, errors.Is(failure.Translate(err, TestCodeB), errB))
+	assert.False(t, errors.Is(io.EOF, errA))
+}
+
+func TestValueAs(t *testing.T) {
+	base := failure.New(TestCodeA, failure.Debug{"user_id": 42, "retry": true})
+	err := failure.Wrap(base, failure.Debug{"user_id": 43, "name": "foo"})
+
+	id, ok := failure.ValueAs[int](err, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, 43, id)
+
+	retry, ok := failure.ValueAs[bool](err, "retry")
+	assert.True(t, ok)
+	assert.True(t, retry)
+
+	_, ok = failure.ValueAs[string](err, "user_id")
+	assert.False(t, ok)
+
+	_, ok = failure.ValueAs[int](err, "not_exist")
+	assert.False(t, ok)
+
+	v, ok := failure.ValueOf(err, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "foo", v)
+
+	_, ok = failure.ValueOf(nil, "name")
+	assert.False(t, ok)
+}
+
+func TestWithStaticCallStack(t *testing.T) {
+	err := failure.New(failure.StringCode("code"), failure.WithStaticCallStack(failure.MustParseFrames(
+		"main.f /src/main.go:12",
+		"main.main /src/main.go:5",
+	)...))
+
+	cs := failure.CallStackOf(err)
+	assert.Equal(t, "f: main", fmt.Sprintf("%v", cs))
+	assert.Len(t, failure.CallStacksOf(err), 1)
+	assert.Contains(t, fmt.Sprintf("%+v", err), "[f] /src/main.go:12\n")
+}
+
+func TestContextOf(t *testing.T) {
+	err := failure.New(failure.StringCode("code"), failure.Debug{"a": 1, "b": 2})
+	err = failure.Wrap(err, failure.Debug{"b": 3, "c": 4})
+
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": 3, "c": 4}, failure.ContextOf(err))
+	assert.Nil(t, failure.ContextOf(failure.New(failure.StringCode("code"))))
+	assert.Nil(t, failure.ContextOf(nil))
+}
+
+func BenchmarkContextOf(b *testing.B) {
+	err := failure.New(failure.StringCode("code"))
+	for i := 0; i < 10; i++ {
+		err = failure.Wrap(err, failure.Debug{fmt.Sprint(i): i})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		failure.ContextOf(err)
+	}
+}
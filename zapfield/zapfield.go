@@ -0,0 +1,111 @@
+// Package zapfield provides zap fields for errors of the failure
+// package.
+package zapfield
+
+import (
+	"sort"
+
+	"github.com/morikuni/failure"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Error returns a field for err keyed by "error".
+// The error is expanded into its code, message, severity, debug
+// information and call stack.
+func Error(err error) zap.Field {
+	return NamedError("error", err)
+}
+
+// NamedError is the same as Error except that it uses key.
+func NamedError(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, ErrorMarshaler{err})
+}
+
+// CallStack returns a field for cs.
+func CallStack(key string, cs failure.CallStack) zap.Field {
+	if cs == nil {
+		return zap.Skip()
+	}
+	return zap.Array(key, CallStackMarshaler{cs})
+}
+
+// ErrorMarshaler is a zapcore.ObjectMarshaler for an error.
+type ErrorMarshaler struct {
+	Err error
+}
+
+// MarshalLogObject implements the zapcore.ObjectMarshaler interface.
+func (m ErrorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	err := m.Err
+	enc.AddString("error", err.Error())
+	if c := failure.CodeOf(err); c != nil {
+		enc.AddString("code", c.ErrorCode())
+	}
+	if msg := failure.MessageOf(err); msg != "" {
+		enc.AddString("message", msg)
+	}
+	enc.AddString("severity", failure.SeverityOf(err).String())
+	if ctx := failure.ContextOf(err); ctx != nil {
+		if e := enc.AddObject("debug", contextMarshaler(ctx)); e != nil {
+			return e
+		}
+	}
+	if cs := failure.CallStackOf(err); cs != nil {
+		if e := enc.AddArray("stack", CallStackMarshaler{cs}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// contextMarshaler encodes the context from failure.ContextOf.
+type contextMarshaler map[string]interface{}
+
+// MarshalLogObject encodes the context ordered by the keys.
+func (ctx contextMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(ctx))
+	for k := range ctx {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := enc.AddReflected(k, ctx[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CallStackMarshaler is a zapcore.ArrayMarshaler for a call stack.
+// Each frame is encoded by FrameMarshaler.
+type CallStackMarshaler struct {
+	CallStack failure.CallStack
+}
+
+// MarshalLogArray implements the zapcore.ArrayMarshaler interface.
+func (m CallStackMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range m.CallStack.Frames() {
+		if err := enc.AppendObject(FrameMarshaler{f}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FrameMarshaler is a zapcore.ObjectMarshaler for a frame.
+type FrameMarshaler struct {
+	Frame failure.Frame
+}
+
+// MarshalLogObject implements the zapcore.ObjectMarshaler interface.
+func (m FrameMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("func", m.Frame.Func())
+	enc.AddString("file", m.Frame.Path())
+	enc.AddInt("line", m.Frame.Line())
+	return nil
+}
@@ -0,0 +1,51 @@
+package zapfield_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/zapfield"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestError(t *testing.T) {
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("xxx"),
+		failure.Debug{"id": 1},
+	)
+	err = failure.Wrap(err, failure.Debug{"id": 2, "name": "a"})
+
+	enc := zapcore.NewMapObjectEncoder()
+	zapfield.Error(err).AddTo(enc)
+
+	got := enc.Fields["error"].(map[string]interface{})
+	assert.Equal(t, err.Error(), got["error"])
+	assert.Equal(t, "not_found", got["code"])
+	assert.Equal(t, "xxx", got["message"])
+	assert.Equal(t, "error", got["severity"])
+	assert.Equal(t, map[string]interface{}{"id": 2, "name": "a"}, got["debug"])
+
+	stack := got["stack"].([]interface{})
+	head := stack[0].(map[string]interface{})
+	assert.Equal(t, "TestError", head["func"])
+	assert.Equal(t, 14, head["line"])
+}
+
+func TestError_Nil(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	zapfield.Error(nil).AddTo(enc)
+
+	assert.Empty(t, enc.Fields)
+}
+
+func TestCallStack(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	zapfield.CallStack("stack", failure.Callers(0)).AddTo(enc)
+
+	stack := enc.Fields["stack"].([]interface{})
+	head := stack[0].(map[string]interface{})
+	assert.Equal(t, "TestCallStack", head["func"])
+	assert.Contains(t, head["file"], "zapfield_test.go")
+}
@@ -0,0 +1,81 @@
+// Package zerologutil provides marshalers to log errors of the
+// failure package with github.com/rs/zerolog.
+//
+//	zerolog.ErrorMarshalFunc = zerologutil.MarshalError
+//	zerolog.ErrorStackMarshaler = zerologutil.MarshalStack
+package zerologutil
+
+import (
+	"github.com/morikuni/failure"
+	"github.com/rs/zerolog"
+)
+
+// MarshalError converts err into ErrorObject.
+// It can be set to zerolog.ErrorMarshalFunc.
+func MarshalError(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return ErrorObject{err}
+}
+
+// MarshalStack returns the call stack of err.
+// It can be set to zerolog.ErrorStackMarshaler, and the call stack
+// is encoded as JSON array of frames.
+func MarshalStack(err error) interface{} {
+	cs := failure.CallStackOf(err)
+	if cs == nil {
+		return nil
+	}
+	return cs
+}
+
+// ErrorObject is a zerolog.LogObjectMarshaler for an error.
+// The error is expanded into its code, message, severity, debug
+// information and call stack.
+type ErrorObject struct {
+	Err error
+}
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (o ErrorObject) MarshalZerologObject(e *zerolog.Event) {
+	err := o.Err
+	e.Str("error", err.Error())
+	if c := failure.CodeOf(err); c != nil {
+		e.Str("code", c.ErrorCode())
+	}
+	if msg := failure.MessageOf(err); msg != "" {
+		e.Str("message", msg)
+	}
+	e.Str("severity", failure.SeverityOf(err).String())
+	if fields := failure.ContextOf(err); fields != nil {
+		e.Dict("debug", zerolog.Dict().Fields(fields))
+	}
+	if cs := failure.CallStackOf(err); cs != nil {
+		e.Array("stack", CallStackArray{cs})
+	}
+}
+
+// CallStackArray is a zerolog.LogArrayMarshaler for a call stack.
+type CallStackArray struct {
+	CallStack failure.CallStack
+}
+
+// MarshalZerologArray implements the zerolog.LogArrayMarshaler interface.
+func (a CallStackArray) MarshalZerologArray(arr *zerolog.Array) {
+	for _, f := range a.CallStack.Frames() {
+		arr.Object(FrameObject{f})
+	}
+}
+
+// FrameObject is a zerolog.LogObjectMarshaler for a frame.
+type FrameObject struct {
+	Frame failure.Frame
+}
+
+// MarshalZerologObject implements the zerolog.LogObjectMarshaler interface.
+func (o FrameObject) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("func", o.Frame.Func())
+	e.Str("file", o.Frame.Path())
+	e.Int("line", o.Frame.Line())
+}
@@ -0,0 +1,80 @@
+package zerologutil_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/morikuni/failure"
+	"github.com/morikuni/failure/zerologutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalError(t *testing.T) {
+	defer func(f func(error) interface{}) {
+		zerolog.ErrorMarshalFunc = f
+	}(zerolog.ErrorMarshalFunc)
+	zerolog.ErrorMarshalFunc = zerologutil.MarshalError
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	err := failure.Translate(io.EOF, failure.StringCode("not_found"),
+		failure.Message("xxx"),
+		failure.Debug{"id": 1},
+	)
+	err = failure.Wrap(err, failure.Debug{"id": 2, "name": "a"})
+	logger.Error().Err(err).Msg("failed")
+
+	var got struct {
+		Error struct {
+			Error    string                 `json:"error"`
+			Code     string                 `json:"code"`
+			Message  string                 `json:"message"`
+			Severity string                 `json:"severity"`
+			Debug    map[string]interface{} `json:"debug"`
+			Stack    []struct {
+				Func string `json:"func"`
+				Line int    `json:"line"`
+			} `json:"stack"`
+		} `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, err.Error(), got.Error.Error)
+	assert.Equal(t, "not_found", got.Error.Code)
+	assert.Equal(t, "xxx", got.Error.Message)
+	assert.Equal(t, "error", got.Error.Severity)
+	assert.Equal(t, map[string]interface{}{"id": float64(2), "name": "a"}, got.Error.Debug)
+	if assert.NotEmpty(t, got.Error.Stack) {
+		assert.Equal(t, "TestMarshalError", got.Error.Stack[0].Func)
+		assert.Equal(t, 24, got.Error.Stack[0].Line)
+	}
+
+	assert.Nil(t, zerologutil.MarshalError(nil))
+}
+
+func TestMarshalStack(t *testing.T) {
+	defer func(f func(error) interface{}) {
+		zerolog.ErrorStackMarshaler = f
+	}(zerolog.ErrorStackMarshaler)
+	zerolog.ErrorStackMarshaler = zerologutil.MarshalStack
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	logger.Error().Stack().Err(failure.New(failure.StringCode("a"))).Msg("failed")
+
+	var got struct {
+		Stack []struct {
+			Func string `json:"func"`
+		} `json:"stack"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	if assert.NotEmpty(t, got.Stack) {
+		assert.Equal(t, "TestMarshalStack", got.Stack[0].Func)
+	}
+
+	assert.Nil(t, zerologutil.MarshalStack(io.EOF))
+}